@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// アラートのイベント名
+const (
+	AlertEventTriggered = "alert.triggered"
+	AlertEventResolved  = "alert.resolved"
+	WebhookEventPing    = "ping"
+)
+
+const defaultAlertHysteresis = 10.0 // 解除までに下がる必要がある混雑率（ポイント）
+
+// 混雑アラートのルール
+// 観光地・カテゴリのどちらも指定しない場合は全観光地が対象
+type AlertRule struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Name           string    `gorm:"not null" json:"name"`
+	TouristSpotID  *uint     `gorm:"index" json:"tourist_spot_id"`
+	CategoryID     *uint     `gorm:"index" json:"category_id"`
+	ThresholdRatio float64   `gorm:"not null" json:"threshold_ratio"`  // 発報する混雑率（%）例: 80=非常に混雑, 100=満員
+	Hysteresis     float64   `gorm:"not null" json:"hysteresis"`       // 閾値からこのポイント下回ったら解除
+	QuietStart     string    `json:"quiet_start"`                      // 通知しない時間帯の開始（"22:00"）
+	QuietEnd       string    `json:"quiet_end"`                        // 通知しない時間帯の終了（"07:00"）
+	WebhookID      uint      `gorm:"not null;index" json:"webhook_id"` // 送信先
+	Enabled        bool      `json:"enabled"`                          // 作成時に明示的に設定する（default:true だと false が無視される）
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ルール×観光地ごとの発報状態
+type AlertState struct {
+	AlertRuleID   uint      `gorm:"primaryKey" json:"alert_rule_id"`
+	TouristSpotID uint      `gorm:"primaryKey" json:"tourist_spot_id"`
+	Active        bool      `gorm:"default:false" json:"active"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// 混雑状況の表示名から閾値（%）を求める（例: "非常に混雑" → 80）
+func alertThresholdForLabel(label string) (float64, bool) {
+	level, ok := CongestionLevelFromLabel(label)
+	if !ok || level == CongestionEmpty {
+		return 0, false
+	}
+	return level.MinRatio(), true
+}
+
+// "HH:MM" を0時からの分に変換
+func parseClockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, newLocalizedError("common.clock_format", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ルールの検証
+func (r *AlertRule) Validate() error {
+	if r.ThresholdRatio <= 0 {
+		return newLocalizedError("alert.threshold_invalid")
+	}
+	if r.Hysteresis < 0 || r.Hysteresis >= r.ThresholdRatio {
+		return newLocalizedError("alert.hysteresis_invalid")
+	}
+	if (r.QuietStart == "") != (r.QuietEnd == "") {
+		return newLocalizedError("alert.quiet_hours_incomplete")
+	}
+	if r.QuietStart != "" {
+		if _, err := parseClockMinutes(r.QuietStart); err != nil {
+			return err
+		}
+		if _, err := parseClockMinutes(r.QuietEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 通知しない時間帯かどうか（日をまたぐ指定にも対応）
+func (r *AlertRule) IsQuietAt(t time.Time) bool {
+	if r.QuietStart == "" || r.QuietEnd == "" {
+		return false
+	}
+	start, err1 := parseClockMinutes(r.QuietStart)
+	end, err2 := parseClockMinutes(r.QuietEnd)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// アラートとして送る内容
+type AlertPayload struct {
+	Event      string       `json:"event"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Rule       AlertRule    `json:"rule"`
+	Spot       AlertSpotRef `json:"tourist_spot"`
+}
+
+type AlertSpotRef struct {
+	ID         uint             `json:"id"`
+	Name       string           `json:"name"`
+	Congestion CongestionStatus `json:"congestion"`
+}
+
+// 発報状態を切り替える（既に同じ状態の場合はfalse）
+// 同時に複数の更新があっても、条件付きUPDATEにより通知は1回だけになる
+func transitionAlertState(db *gorm.DB, ruleID, spotID uint, active bool) (bool, error) {
+	state := AlertState{AlertRuleID: ruleID, TouristSpotID: spotID, ChangedAt: time.Now()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&state).Error; err != nil {
+		return false, err
+	}
+	result := db.Model(&AlertState{}).
+		Where("alert_rule_id = ? AND tourist_spot_id = ? AND active = ?", ruleID, spotID, !active).
+		Updates(map[string]interface{}{"active": active, "changed_at": time.Now()})
+	return result.RowsAffected == 1, result.Error
+}
+
+// 観光地に適用されるアラートルール
+func alertRulesForSpot(db *gorm.DB, spot *TouristSpot) ([]AlertRule, error) {
+	query := db.Where("enabled = ?", true)
+	if spot.CategoryID != nil {
+		query = query.Where("tourist_spot_id = ? OR category_id = ? OR (tourist_spot_id IS NULL AND category_id IS NULL)", spot.ID, *spot.CategoryID)
+	} else {
+		query = query.Where("tourist_spot_id = ? OR (tourist_spot_id IS NULL AND category_id IS NULL)", spot.ID)
+	}
+	var rules []AlertRule
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// 来場者数の更新後にアラートを判定し、閾値をまたいだ場合はWebhookへ送る
+// 非同期に呼ばれても古い値で判定しないよう、最新の状態を読み直す
+func EvaluateCongestionAlerts(db *gorm.DB, spotID uint) {
+	var spot TouristSpot
+	if err := db.First(&spot, spotID).Error; err != nil || spot.MaxCapacity <= 0 {
+		return
+	}
+	rules, err := alertRulesForSpot(db, &spot)
+	if err != nil {
+		fmt.Printf("⚠️ アラートルールの取得に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+		return
+	}
+
+	status := NewCongestionService(db).SpotStatus(&spot)
+	ratio := *status.Ratio
+	// 通知しない時間帯は観光地のタイムゾーンで判定する
+	loc, err := loadSpotLocation(spot.TimeZone)
+	if err != nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+
+	for _, rule := range rules {
+		var event string
+		switch {
+		case ratio >= rule.ThresholdRatio:
+			event = AlertEventTriggered
+		case ratio < rule.ThresholdRatio-rule.Hysteresis:
+			event = AlertEventResolved
+		default:
+			continue // 閾値付近では状態を変えない
+		}
+
+		changed, err := transitionAlertState(db, rule.ID, spot.ID, event == AlertEventTriggered)
+		if err != nil {
+			fmt.Printf("⚠️ アラート状態の更新に失敗 - Rule: %d, SpotID: %d, Error: %v\n", rule.ID, spot.ID, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if rule.IsQuietAt(now) {
+			fmt.Printf("アラート通知を抑制（通知しない時間帯） - Rule: %d, SpotID: %d, Event: %s\n", rule.ID, spot.ID, event)
+			continue
+		}
+
+		payload := AlertPayload{
+			Event:      event,
+			OccurredAt: now,
+			Rule:       rule,
+			Spot:       AlertSpotRef{ID: spot.ID, Name: spot.Name, Congestion: status},
+		}
+		ruleID, spotID := rule.ID, spot.ID
+		if _, err := EnqueueWebhookDelivery(db, rule.WebhookID, event, payload, &ruleID, &spotID); err != nil {
+			fmt.Printf("⚠️ アラート配信の登録に失敗 - Rule: %d, SpotID: %d, Error: %v\n", rule.ID, spot.ID, err)
+		}
+	}
+}
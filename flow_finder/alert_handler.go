@@ -0,0 +1,452 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 混雑アラート・Webhook関連のルートを登録（認可は route_policy.go の alerts:manage）
+func RegisterAlertRoutes(r *gin.Engine, db *gorm.DB) {
+	webhooks := r.Group("/api/webhooks")
+	{
+		// Webhook一覧（シークレットは含まない）
+		webhooks.GET("", listWebhooksHandler(db))
+
+		// Webhook作成（署名用シークレットはこのレスポンスでのみ返す）
+		webhooks.POST("", createWebhookHandler(db))
+
+		// Webhook更新
+		webhooks.PUT("/:id", updateWebhookHandler(db))
+
+		// Webhook削除（アラートルールから参照されている場合は不可）
+		webhooks.DELETE("/:id", deleteWebhookHandler(db))
+
+		// 疎通確認用のpingを送信
+		webhooks.POST("/:id/test", testWebhookHandler(db))
+
+		// 配信記録（?status=pending|sending|success|failed）
+		webhooks.GET("/:id/deliveries", listWebhookDeliveriesHandler(db))
+	}
+
+	// 配信の再送
+	r.POST("/api/webhook-deliveries/:id/retry", retryWebhookDeliveryHandler(db))
+
+	rules := r.Group("/api/alert-rules")
+	{
+		// アラートルール一覧
+		rules.GET("", listAlertRulesHandler(db))
+
+		// アラートルール作成
+		rules.POST("", createAlertRuleHandler(db))
+
+		// アラートルール更新
+		rules.PUT("/:id", updateAlertRuleHandler(db))
+
+		// アラートルール削除
+		rules.DELETE("/:id", deleteAlertRuleHandler(db))
+
+		// ルールごとの発報状態
+		rules.GET("/:id/states", listAlertStatesHandler(db))
+	}
+}
+
+// 変更履歴に記録する操作ユーザーID（APIキーなどユーザー以外の場合はnil）
+func currentUserIDPtr(c *gin.Context) *uint {
+	if uid, ok := GetUserIDFromContext(c); ok {
+		return &uid
+	}
+	return nil
+}
+
+// Webhook一覧取得ハンドラ
+func listWebhooksHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hooks []Webhook
+		if err := db.Order("id ASC").Find(&hooks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "webhook.fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, hooks)
+	}
+}
+
+// Webhook作成ハンドラ
+func createWebhookHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name    string `json:"name" binding:"required"`
+			URL     string `json:"url" binding:"required"`
+			Enabled *bool  `json:"enabled"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if err := validateWebhookURL(req.URL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+
+		secret, err := GenerateWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "webhook.secret_failed")})
+			return
+		}
+		hook := Webhook{Name: req.Name, URL: req.URL, Secret: secret, Enabled: true}
+		if req.Enabled != nil {
+			hook.Enabled = *req.Enabled
+		}
+		if err := db.Create(&hook).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "webhook.create_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "webhooks", strconv.Itoa(int(hook.ID)), currentUserIDPtr(c), "create", nil, hook)
+
+		c.JSON(http.StatusCreated, gin.H{
+			"webhook": hook,
+			"secret":  secret,
+			"message": "このシークレットは再表示できません。受信側で署名の検証に使用してください",
+		})
+	}
+}
+
+// Webhook更新ハンドラ
+func updateWebhookHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hook Webhook
+		if err := db.First(&hook, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "webhook.not_found")})
+			return
+		}
+		before := hook
+
+		var req struct {
+			Name    *string `json:"name"`
+			URL     *string `json:"url"`
+			Enabled *bool   `json:"enabled"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if req.Name != nil {
+			hook.Name = *req.Name
+		}
+		if req.URL != nil {
+			if err := validateWebhookURL(*req.URL); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+				return
+			}
+			hook.URL = *req.URL
+		}
+		if req.Enabled != nil {
+			hook.Enabled = *req.Enabled
+		}
+		if err := db.Save(&hook).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "webhook.update_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "webhooks", strconv.Itoa(int(hook.ID)), currentUserIDPtr(c), "update", before, hook)
+		c.JSON(http.StatusOK, hook)
+	}
+}
+
+// Webhook削除ハンドラ
+func deleteWebhookHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hook Webhook
+		if err := db.First(&hook, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "webhook.not_found")})
+			return
+		}
+
+		var ruleCount, policyCount int64
+		db.Model(&AlertRule{}).Where("webhook_id = ?", hook.ID).Count(&ruleCount)
+		db.Model(&SpotCapacityPolicy{}).Where("webhook_id = ?", hook.ID).Count(&policyCount)
+		if ruleCount > 0 || policyCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": T(c, "webhook.in_use"), "rules": ruleCount, "capacity_policies": policyCount})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("webhook_id = ?", hook.ID).Delete(&WebhookDelivery{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&hook).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "webhook.delete_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "webhooks", strconv.Itoa(int(hook.ID)), currentUserIDPtr(c), "delete", hook, nil)
+		c.JSON(http.StatusOK, gin.H{"result": "deleted"})
+	}
+}
+
+// 疎通確認ハンドラ（pingイベントを配信キューに登録）
+func testWebhookHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hook Webhook
+		if err := db.First(&hook, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "webhook.not_found")})
+			return
+		}
+		if !hook.Enabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "webhook.disabled")})
+			return
+		}
+
+		payload := gin.H{"event": WebhookEventPing, "occurred_at": time.Now(), "webhook_id": hook.ID}
+		delivery, err := EnqueueWebhookDelivery(db, hook.ID, WebhookEventPing, payload, nil, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "delivery.create_failed")})
+			return
+		}
+		c.JSON(http.StatusAccepted, delivery)
+	}
+}
+
+// 配信記録一覧ハンドラ
+func listWebhookDeliveriesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Where("webhook_id = ?", c.Param("id")).Order("id DESC")
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > 500 {
+			limit = 50
+		}
+
+		var deliveries []WebhookDelivery
+		if err := query.Limit(limit).Find(&deliveries).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "delivery.fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+// 配信の再送ハンドラ（失敗した配信を送信待ちに戻す）
+func retryWebhookDeliveryHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var delivery WebhookDelivery
+		if err := db.First(&delivery, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "delivery.not_found")})
+			return
+		}
+		if delivery.Status == DeliverySending {
+			c.JSON(http.StatusConflict, gin.H{"error": T(c, "delivery.in_progress")})
+			return
+		}
+		if err := db.Model(&delivery).Updates(map[string]interface{}{
+			"status":          DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "delivery.retry_failed")})
+			return
+		}
+		kickWebhookWorker()
+		c.JSON(http.StatusAccepted, delivery)
+	}
+}
+
+// アラートルールの作成・更新リクエスト
+type alertRuleRequest struct {
+	Name           *string  `json:"name"`
+	TouristSpotID  *uint    `json:"tourist_spot_id"`
+	CategoryID     *uint    `json:"category_id"`
+	ThresholdRatio *float64 `json:"threshold_ratio"` // 混雑率（%）
+	ThresholdLevel string   `json:"threshold_level"` // 混雑状況の表示名（"非常に混雑"・"満員" など）でも指定可
+	Hysteresis     *float64 `json:"hysteresis"`
+	QuietStart     *string  `json:"quiet_start"`
+	QuietEnd       *string  `json:"quiet_end"`
+	WebhookID      *uint    `json:"webhook_id"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+// リクエストの内容をルールに反映して検証（エラー時はHTTPステータスとエラーを返す）
+func (req *alertRuleRequest) apply(db *gorm.DB, rule *AlertRule) (int, error) {
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.TouristSpotID != nil {
+		if *req.TouristSpotID == 0 {
+			rule.TouristSpotID = nil
+		} else {
+			if err := db.Select("id").First(&TouristSpot{}, *req.TouristSpotID).Error; err != nil {
+				return http.StatusBadRequest, newLocalizedError("spot.not_found")
+			}
+			rule.TouristSpotID = req.TouristSpotID
+		}
+	}
+	if req.CategoryID != nil {
+		if *req.CategoryID == 0 {
+			rule.CategoryID = nil
+		} else {
+			if err := db.Select("id").First(&TouristSpotCategory{}, *req.CategoryID).Error; err != nil {
+				return http.StatusBadRequest, newLocalizedError("category.not_found")
+			}
+			rule.CategoryID = req.CategoryID
+		}
+	}
+	if rule.TouristSpotID != nil && rule.CategoryID != nil {
+		return http.StatusBadRequest, newLocalizedError("alert.target_conflict")
+	}
+	if req.ThresholdLevel != "" {
+		ratio, ok := alertThresholdForLabel(req.ThresholdLevel)
+		if !ok {
+			return http.StatusBadRequest, newLocalizedError("alert.threshold_level_invalid")
+		}
+		rule.ThresholdRatio = ratio
+	} else if req.ThresholdRatio != nil {
+		rule.ThresholdRatio = *req.ThresholdRatio
+	}
+	if req.Hysteresis != nil {
+		rule.Hysteresis = *req.Hysteresis
+	}
+	if req.QuietStart != nil {
+		rule.QuietStart = *req.QuietStart
+	}
+	if req.QuietEnd != nil {
+		rule.QuietEnd = *req.QuietEnd
+	}
+	if req.WebhookID != nil {
+		if err := db.Select("id").First(&Webhook{}, *req.WebhookID).Error; err != nil {
+			return http.StatusBadRequest, newLocalizedError("webhook.not_found")
+		}
+		rule.WebhookID = *req.WebhookID
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if rule.Name == "" {
+		return http.StatusBadRequest, newLocalizedError("common.name_required")
+	}
+	if rule.WebhookID == 0 {
+		return http.StatusBadRequest, newLocalizedError("alert.webhook_required")
+	}
+	if err := rule.Validate(); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return 0, nil
+}
+
+// アラートルール一覧取得ハンドラ
+func listAlertRulesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Order("id ASC")
+		if spotID := c.Query("tourist_spot_id"); spotID != "" {
+			query = query.Where("tourist_spot_id = ?", spotID)
+		}
+		if categoryID := c.Query("category_id"); categoryID != "" {
+			query = query.Where("category_id = ?", categoryID)
+		}
+
+		var rules []AlertRule
+		if err := query.Find(&rules).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "alert.fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, rules)
+	}
+}
+
+// アラートルール作成ハンドラ
+func createAlertRuleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req alertRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		rule := AlertRule{Hysteresis: defaultAlertHysteresis, Enabled: true}
+		if status, err := req.apply(db, &rule); err != nil {
+			c.JSON(status, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if err := db.Create(&rule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "alert.create_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "alert_rules", strconv.Itoa(int(rule.ID)), currentUserIDPtr(c), "create", nil, rule)
+		c.JSON(http.StatusCreated, rule)
+	}
+}
+
+// アラートルール更新ハンドラ
+func updateAlertRuleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var rule AlertRule
+		if err := db.First(&rule, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "alert.not_found")})
+			return
+		}
+		before := rule
+
+		var req alertRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if status, err := req.apply(db, &rule); err != nil {
+			c.JSON(status, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if err := db.Save(&rule).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "alert.update_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "alert_rules", strconv.Itoa(int(rule.ID)), currentUserIDPtr(c), "update", before, rule)
+		c.JSON(http.StatusOK, rule)
+	}
+}
+
+// アラートルール削除ハンドラ
+func deleteAlertRuleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var rule AlertRule
+		if err := db.First(&rule, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "alert.not_found")})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("alert_rule_id = ?", rule.ID).Delete(&AlertState{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&rule).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "alert.delete_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "alert_rules", strconv.Itoa(int(rule.ID)), currentUserIDPtr(c), "delete", rule, nil)
+		c.JSON(http.StatusOK, gin.H{"result": "deleted"})
+	}
+}
+
+// 発報状態一覧ハンドラ
+func listAlertStatesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var states []AlertState
+		if err := db.Where("alert_rule_id = ?", c.Param("id")).Order("tourist_spot_id ASC").Find(&states).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "alert.state_fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, states)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIキーは "ffk_<プレフィックス>_<シークレット>" の形式
+// プレフィックスで検索し、シークレットはSHA-256のハッシュのみを保存する
+const (
+	apiKeyHeader        = "X-API-Key"
+	apiKeyScheme        = "ffk"
+	apiKeyPrefixBytes   = 6
+	apiKeySecretBytes   = 32
+	apiKeyTouchInterval = time.Minute // 最終利用日時を更新する最小間隔
+)
+
+var errInvalidAPIKey = errors.New("invalid api key")
+
+// 機械クライアント（センサー・キオスク・提携システム）用のAPIキー
+type APIKey struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"not null" json:"name"`               // 用途（例: 北門カウンター）
+	Prefix      string     `gorm:"not null;uniqueIndex" json:"prefix"` // キーの公開部分
+	SecretHash  string     `gorm:"not null" json:"-"`                  // シークレットのSHA-256
+	Scopes      string     `gorm:"type:text;not null" json:"-"`        // 許可する権限（カンマ区切り）
+	SpotIDs     string     `gorm:"type:text" json:"-"`                 // 利用可能な観光地ID（カンマ区切り、空の場合は制限なし）
+	ExpiresAt   *time.Time `json:"expires_at"`                         // 有効期限（nilの場合は無期限）
+	LastUsedAt  *time.Time `json:"last_used_at"`                       // 最終利用日時
+	LastUsedIP  string     `json:"last_used_ip"`                       // 最終利用元IP
+	RevokedAt   *time.Time `gorm:"index" json:"revoked_at"`            // 失効日時
+	CreatedByID *uint      `json:"created_by_id"`                      // 発行したユーザー
+	ScopeList   []string   `gorm:"-" json:"scopes"`                    // レスポンス用
+	SpotIDList  []uint     `gorm:"-" json:"tourist_spot_ids"`          // レスポンス用
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// テーブル名を指定
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// カンマ区切りの列をレスポンス用のスライスに展開
+func (k *APIKey) FillLists() {
+	k.ScopeList = splitList(k.Scopes)
+	k.SpotIDList = []uint{}
+	for _, s := range splitList(k.SpotIDs) {
+		if id, err := strconv.ParseUint(s, 10, 32); err == nil {
+			k.SpotIDList = append(k.SpotIDList, uint(id))
+		}
+	}
+}
+
+// スコープと観光地制限を設定
+func (k *APIKey) SetScopes(scopes []string, spotIDs []uint) {
+	k.Scopes = strings.Join(scopes, ",")
+	ids := make([]string, 0, len(spotIDs))
+	for _, id := range spotIDs {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	k.SpotIDs = strings.Join(ids, ",")
+	k.FillLists()
+}
+
+// 現在有効なキーかどうか
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// 新しいシークレットを発行し、平文のキーを返す（平文は保存しない）
+func (k *APIKey) GenerateSecret() (string, error) {
+	if k.Prefix == "" {
+		prefix := make([]byte, apiKeyPrefixBytes)
+		if _, err := rand.Read(prefix); err != nil {
+			return "", err
+		}
+		k.Prefix = hex.EncodeToString(prefix)
+	}
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	secretStr := hex.EncodeToString(secret)
+	k.SecretHash = hashAPIKeySecret(secretStr)
+	return fmt.Sprintf("%s_%s_%s", apiKeyScheme, k.Prefix, secretStr), nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// 平文のキーをプレフィックスとシークレットに分解
+func parseAPIKey(key string) (prefix, secret string, ok bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// 平文のキーを検証してキー情報を返す
+func VerifyAPIKey(db *gorm.DB, key, clientIP string) (*APIKey, error) {
+	prefix, secret, ok := parseAPIKey(key)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+
+	var apiKey APIKey
+	err := db.Where("prefix = ?", prefix).First(&apiKey).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashAPIKeySecret(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(apiKey.SecretHash)) != 1 {
+		return nil, errInvalidAPIKey
+	}
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return nil, errInvalidAPIKey
+	}
+
+	// 最終利用日時の更新は一定間隔ごとに限定し、書き込みを抑える
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval || apiKey.LastUsedIP != clientIP {
+		db.Model(&APIKey{}).Where("id = ?", apiKey.ID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": clientIP,
+		})
+		apiKey.LastUsedAt = &now
+		apiKey.LastUsedIP = clientIP
+	}
+
+	apiKey.FillLists()
+	return &apiKey, nil
+}
+
+// カンマ区切りの文字列を分割（空要素は除く）
+func splitList(s string) []string {
+	result := []string{}
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// APIキー関連のルートを登録（認可は route_policy.go の apikeys:manage）
+func RegisterAPIKeyRoutes(r *gin.Engine, db *gorm.DB) {
+	keys := r.Group("/api/api-keys")
+	{
+		// APIキー一覧取得（シークレットは含まない）
+		keys.GET("", listAPIKeysHandler(db))
+
+		// APIキー発行
+		keys.POST("", createAPIKeyHandler(db))
+
+		// シークレットの再発行（旧シークレットは即時無効）
+		keys.POST("/:id/rotate", rotateAPIKeyHandler(db))
+
+		// APIキー失効
+		keys.DELETE("/:id", revokeAPIKeyHandler(db))
+	}
+}
+
+// APIキー一覧取得ハンドラ
+func listAPIKeysHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Order("id ASC")
+		if c.Query("include_revoked") != "true" {
+			query = query.Where("revoked_at IS NULL")
+		}
+
+		var keys []APIKey
+		if err := query.Find(&keys).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "apikey.fetch_failed")})
+			return
+		}
+		for i := range keys {
+			keys[i].FillLists()
+		}
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+// APIキー発行ハンドラ（平文のキーはこのレスポンスでのみ返す）
+func createAPIKeyHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name           string     `json:"name" binding:"required"`
+			Scopes         []string   `json:"scopes" binding:"required"`
+			TouristSpotIDs []uint     `json:"tourist_spot_ids"`
+			ExpiresAt      *time.Time `json:"expires_at"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		if len(req.Scopes) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "apikey.scope_required"), "permissions": AllPermissions})
+			return
+		}
+		for _, scope := range req.Scopes {
+			if !IsKnownPermission(scope) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "apikey.unknown_scope"), "permissions": AllPermissions})
+				return
+			}
+		}
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "apikey.expiry_past")})
+			return
+		}
+		spotIDs := uniqueIDs(req.TouristSpotIDs)
+		if status, err := checkAPIKeyGrant(c, db, req.Scopes, spotIDs); err != nil {
+			c.JSON(status, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if len(spotIDs) > 0 {
+			var count int64
+			db.Model(&TouristSpot{}).Where("id IN ?", spotIDs).Count(&count)
+			if int(count) != len(spotIDs) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "apikey.unknown_spot")})
+				return
+			}
+		}
+
+		apiKey := APIKey{Name: req.Name, ExpiresAt: req.ExpiresAt}
+		apiKey.SetScopes(req.Scopes, spotIDs)
+		if uid, ok := GetUserIDFromContext(c); ok {
+			apiKey.CreatedByID = &uid
+		}
+		plainKey, err := apiKey.GenerateSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "apikey.generate_failed")})
+			return
+		}
+		if err := db.Create(&apiKey).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "apikey.create_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "api_keys", strconv.Itoa(int(apiKey.ID)), apiKey.CreatedByID, "create", nil, apiKey)
+
+		c.JSON(http.StatusCreated, gin.H{
+			"api_key": apiKey,
+			"key":     plainKey,
+			"message": T(c, "apikey.store_secret"),
+		})
+	}
+}
+
+// シークレット再発行ハンドラ
+func rotateAPIKeyHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var apiKey APIKey
+		if err := db.First(&apiKey, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "apikey.not_found")})
+			return
+		}
+		if apiKey.RevokedAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "apikey.revoked_rotate")})
+			return
+		}
+		// 新しいシークレットを受け取るため、発行時と同じく呼び出し元の権限内のキーに限る
+		apiKey.FillLists()
+		if status, err := checkAPIKeyGrant(c, db, apiKey.ScopeList, apiKey.SpotIDList); err != nil {
+			c.JSON(status, gin.H{"error": errorText(c, err)})
+			return
+		}
+
+		plainKey, err := apiKey.GenerateSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "apikey.generate_failed")})
+			return
+		}
+		if err := db.Model(&apiKey).Update("secret_hash", apiKey.SecretHash).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "apikey.rotate_failed")})
+			return
+		}
+		apiKey.FillLists()
+
+		var userID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			userID = &uid
+		}
+		RecordChangeHistory(db, "api_keys", id, userID, "rotate", nil, apiKey)
+
+		c.JSON(http.StatusOK, gin.H{
+			"api_key": apiKey,
+			"key":     plainKey,
+			"message": T(c, "apikey.store_secret"),
+		})
+	}
+}
+
+// APIキー失効ハンドラ
+func revokeAPIKeyHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var apiKey APIKey
+		if err := db.First(&apiKey, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "apikey.not_found")})
+			return
+		}
+		if apiKey.RevokedAt != nil {
+			c.JSON(http.StatusOK, gin.H{"result": "ok", "message": T(c, "apikey.already_revoked")})
+			return
+		}
+
+		now := time.Now()
+		if err := db.Model(&apiKey).Update("revoked_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "apikey.revoke_failed")})
+			return
+		}
+		apiKey.FillLists()
+
+		var userID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			userID = &uid
+		}
+		RecordChangeHistory(db, "api_keys", id, userID, "revoke", nil, apiKey)
+
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "message": T(c, "apikey.revoked")})
+	}
+}
+
+// 呼び出し元が持っていない権限・観光地をキーに与えないか確認（権限の昇格を防ぐ）
+// ユーザーはDBの最新のロール、APIキーは自身のスコープと観光地制限を上限とする
+func checkAPIKeyGrant(c *gin.Context, db *gorm.DB, scopes []string, spotIDs []uint) (int, error) {
+	principal := principalFromContext(c)
+	if principal == nil {
+		return http.StatusUnauthorized, newLocalizedError("auth.required")
+	}
+
+	perms := principal.permissions
+	if !principal.IsAPIKey() {
+		var err error
+		if perms, _, err = LoadUserPermissions(db, principal.UserID); err != nil {
+			return http.StatusUnauthorized, newLocalizedError("auth.user_invalid")
+		}
+	}
+	for _, scope := range scopes {
+		if !perms[permWildcard] && !perms[scope] {
+			return http.StatusForbidden, newLocalizedError("apikey.scope_not_held", scope)
+		}
+	}
+
+	// 観光地を制限されたAPIキーは、同じ範囲内に制限したキーしか扱えない
+	if len(principal.SpotIDs) > 0 {
+		if len(spotIDs) == 0 {
+			return http.StatusForbidden, newLocalizedError("apikey.spot_out_of_scope")
+		}
+		for _, id := range spotIDs {
+			if !principal.CanAccessSpot(id) {
+				return http.StatusForbidden, newLocalizedError("apikey.spot_out_of_scope")
+			}
+		}
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// トークンの発行方式
+const (
+	AuthModeOpaque = "opaque" // ランダムトークン + Redis（従来方式）
+	AuthModeJWT    = "jwt"    // 署名付きJWT + Redis失効リスト
+)
+
+// 認証設定
+type AuthConfig struct {
+	Mode                 string
+	JWTKeys              *JWTKeySet
+	JWTIssuer            string
+	PasswordLoginEnabled bool        // /api/login（ユーザー名とパスワード）を受け付けるか
+	OIDC                 *OIDCConfig // OIDCログイン（未設定の場合はnil）
+}
+
+// 起動時に読み込んだ認証設定
+var authConfig = &AuthConfig{Mode: AuthModeOpaque, PasswordLoginEnabled: true}
+
+// 環境変数から認証設定を読み込む
+//
+//	AUTH_TOKEN_MODE      opaque | jwt（デフォルト: opaque）
+//	AUTH_JWT_KEYS        "kid:alg:material" をカンマ区切りで列挙（古い鍵は検証専用として残す）
+//	AUTH_JWT_ACTIVE_KID  署名に使う鍵ID（省略時は先頭の鍵）
+//	AUTH_JWT_ISSUER      issクレーム（デフォルト: flow_finder）
+//	AUTH_PASSWORD_LOGIN  false の場合は /api/login を無効化（OIDCのみでログインさせる場合）
+//	OIDC_*               OIDCログインの設定（loadOIDCConfig を参照）
+func LoadAuthConfig() (*AuthConfig, error) {
+	cfg := &AuthConfig{
+		Mode:                 strings.ToLower(os.Getenv("AUTH_TOKEN_MODE")),
+		JWTIssuer:            os.Getenv("AUTH_JWT_ISSUER"),
+		PasswordLoginEnabled: !strings.EqualFold(os.Getenv("AUTH_PASSWORD_LOGIN"), "false"),
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = AuthModeOpaque
+	}
+	if cfg.JWTIssuer == "" {
+		cfg.JWTIssuer = "flow_finder"
+	}
+
+	var keys []*JWTKey
+	for _, spec := range strings.Split(os.Getenv("AUTH_JWT_KEYS"), ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		key, err := ParseJWTKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	switch cfg.Mode {
+	case AuthModeOpaque:
+		// 移行期間中はJWTの検証だけを行えるように鍵があれば読み込む
+		if len(keys) > 0 {
+			ks, err := NewJWTKeySet(keys, os.Getenv("AUTH_JWT_ACTIVE_KID"))
+			if err != nil {
+				return nil, err
+			}
+			cfg.JWTKeys = ks
+		}
+	case AuthModeJWT:
+		ks, err := NewJWTKeySet(keys, os.Getenv("AUTH_JWT_ACTIVE_KID"))
+		if err != nil {
+			return nil, err
+		}
+		cfg.JWTKeys = ks
+	default:
+		return nil, fmt.Errorf("未対応のAUTH_TOKEN_MODEです: %s", cfg.Mode)
+	}
+
+	oidcCfg, err := loadOIDCConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.OIDC = oidcCfg
+	if !cfg.PasswordLoginEnabled && cfg.OIDC == nil {
+		return nil, fmt.Errorf("AUTH_PASSWORD_LOGIN=false の場合はOIDC_ISSUERの設定が必要です")
+	}
+	return cfg, nil
+}
@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -12,68 +12,97 @@ import (
 
 // 認証関連のルートを登録
 func RegisterAuthRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
-	// ログインAPI（例: ユーザー名のみで認証）
+	// ログインAPI（ユーザー名とパスワードで認証）
 	r.POST("/api/login", func(c *gin.Context) {
+		// OIDCのみでログインさせる設定の場合は無効
+		if !authConfig.PasswordLoginEnabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": T(c, "auth.password_login_disabled"), "oidc_login_url": "/api/auth/oidc/login"})
+			return
+		}
+
 		var req struct {
-			Name string `json:"name"`
+			Name     string `json:"name" binding:"required"`
+			Password string `json:"password" binding:"required"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
+			return
+		}
+
+		sessionID := c.GetHeader("X-Session-Id")
+		if sessionID == "" {
+			sessionID = generateHandlerSessionID()
+		}
+
+		// ユーザー名単位の失敗回数制限（IP単位はレート制限ミドルウェアで制限）
+		ctx := context.Background()
+		allowed, retryAfter, err := CheckLoginAllowed(ctx, redisClient, req.Name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+		if !allowed {
+			logLoginAttempt(db, c, nil, sessionID, "rate limited")
+			c.Header("Retry-After", fmt.Sprintf("%d", int(retryAfter.Seconds())))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": T(c, "auth.too_many_attempts")})
 			return
 		}
+
 		var user User
-		if err := db.Where("name = ?", req.Name).First(&user).Error; err != nil {
+		found := db.Preload("Roles").Where("name = ?", req.Name).First(&user).Error == nil
+		// ユーザーが存在しない場合もパスワード照合を行い応答時間を揃える
+		if !CheckPassword(user.PasswordHash, req.Password) || !found {
+			RecordLoginFailure(ctx, redisClient, req.Name)
 			// ログイン失敗をログに記録
-			sessionID := c.GetHeader("X-Session-Id")
-			if sessionID == "" {
-				sessionID = generateHandlerSessionID()
+			var userID *uint
+			if found {
+				userID = &user.ID
 			}
-			LogUserActivity(db, UserLog{
-				UserID:    nil, // ゲストユーザー
-				SessionID: sessionID,
-				LogType:   LogTypeAction,
-				Category:  CategoryAuth,
-				Action:    ActionLogin,
-				Path:      "/login",
-				UserAgent: c.Request.UserAgent(),
-				IPAddress: c.ClientIP(),
-				Error:     "user not found",
-			})
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			logLoginAttempt(db, c, userID, sessionID, "invalid credentials")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.invalid_credentials")})
 			return
 		}
-		token, err := GenerateToken(32)
+		ResetLoginFailures(ctx, redisClient, req.Name)
+
+		// セッションを作成してトークンをRedisに保存（アクセストークンの有効期限1時間）
+		tokens, err := CreateSession(ctx, redisClient, &user, c.ClientIP(), c.Request.UserAgent())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
-			return
-		}
-		// トークンをRedisに保存（有効期限1時間）
-		if err := SaveTokenToRedis(context.Background(), redisClient, user.ID, token, time.Hour); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save token"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "auth.token_save_failed")})
 			return
 		}
-		
+
 		// ログイン成功をログに記録
-		sessionID := c.GetHeader("X-Session-Id")
-		if sessionID == "" {
-			sessionID = generateHandlerSessionID()
-		}
-		LogUserActivity(db, UserLog{
-			UserID:    &user.ID, // ポインター型に変更
-			SessionID: sessionID,
-			LogType:   LogTypeAction,
-			Category:  CategoryAuth,
-			Action:    ActionLogin,
-			Path:      "/login",
-			UserAgent: c.Request.UserAgent(),
-			IPAddress: c.ClientIP(),
-		})
-		
+		logLoginAttempt(db, c, &user.ID, sessionID, "")
+
+		// ゲストとして利用していた場合はお気に入り・ログを引き継ぐ
+		merged := mergeGuestOnLogin(c, db, redisClient, user.ID)
+
 		c.JSON(http.StatusOK, gin.H{
-			"token":      token,
-			"user_id":    user.ID,
-			"session_id": sessionID,
-			"is_admin":   user.IsAdmin,
+			"token":                tokens.Token,
+			"token_type":           tokens.TokenType,
+			"refresh_token":        tokens.RefreshToken,
+			"expires_in":           int(tokens.ExpiresIn.Seconds()),
+			"auth_session_id":      tokens.SessionID,
+			"user_id":              user.ID,
+			"session_id":           sessionID,
+			"is_admin":             user.IsAdmin,
+			"must_change_password": user.MustChangePassword,
+			"merged_guest":         merged,
 		})
 	})
+}
+
+// ログイン試行をユーザーログに記録
+func logLoginAttempt(db *gorm.DB, c *gin.Context, userID *uint, sessionID, errMsg string) {
+	LogUserActivity(db, UserLog{
+		UserID:    userID,
+		SessionID: sessionID,
+		LogType:   LogTypeAction,
+		Category:  CategoryAuth,
+		Action:    ActionLogin,
+		Path:      "/login",
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		Error:     errMsg,
+	})
 }
\ No newline at end of file
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 観光地の入場制限（キャパシティポリシー）
+// ソフト上限を超えると「混雑」として近くの代替観光地を案内し、ハード上限に達すると「満員」として入場を止める。
+// 満員になった後は、人数が再開の閾値まで下がるまで入場を再開しない（上限付近で開閉を繰り返さないため）
+const (
+	CapacityStateNormal = "normal" // 通常
+	CapacityStateBusy   = "busy"   // ソフト上限以上（入場は可能、代替を案内）
+	CapacityStateFull   = "full"   // 満員（入場不可）
+
+	CapacityEventChanged = "capacity.changed"
+)
+
+const (
+	defaultCapacitySoftRatio   = 80.0 // ソフト上限（許容人数に対する%）
+	defaultCapacityHardRatio   = 100.0
+	defaultCapacityReopenRatio = 90.0 // ハード上限に対する%
+	defaultAlternativeLimit    = 3
+	maxAlternativeLimit        = 10
+)
+
+// 観光地ごとのキャパシティポリシー
+type SpotCapacityPolicy struct {
+	TouristSpotID  uint       `gorm:"primaryKey" json:"tourist_spot_id"`
+	Enabled        bool       `json:"enabled"`
+	SoftLimitRatio float64    `gorm:"not null" json:"soft_limit_ratio"` // 混雑として代替を案内する混雑率（%）
+	HardLimitRatio float64    `gorm:"not null" json:"hard_limit_ratio"` // 入場を止める混雑率（%、100以下）
+	ReopenRatio    float64    `gorm:"not null" json:"reopen_ratio"`     // 満員から入場を再開する混雑率（%、ハード上限未満）
+	WebhookID      *uint      `gorm:"index" json:"webhook_id"`          // 状態が変わったときの通知先（任意）
+	State          string     `gorm:"not null;default:normal" json:"state"`
+	StateChangedAt *time.Time `json:"state_changed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// 状態の遷移記録
+type SpotCapacityTransition struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TouristSpotID uint      `gorm:"not null;index:idx_capacity_transition_spot,priority:1" json:"tourist_spot_id"`
+	FromState     string    `gorm:"not null" json:"from_state"`
+	ToState       string    `gorm:"not null" json:"to_state"`
+	CurrentCount  int       `json:"current_count"`
+	MaxCapacity   int       `json:"max_capacity"`
+	Source        string    `json:"source"` // visitors | mqtt | sensor | spot | import | policy
+	CreatedAt     time.Time `gorm:"index:idx_capacity_transition_spot,priority:2" json:"created_at"`
+}
+
+// 観光地の入場状況（レスポンス用）
+type CapacityStatus struct {
+	Enabled   bool   `json:"enabled"`
+	State     string `json:"state"`
+	SoftLimit int    `json:"soft_limit"` // 人数に換算した上限
+	HardLimit int    `json:"hard_limit"`
+	ReopenAt  int    `json:"reopen_at"`
+	Available int    `json:"available"` // ハード上限までの残り人数
+}
+
+// 状態が変わったときにWebhookへ送る内容
+type CapacityPayload struct {
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	FromState  string          `json:"from_state"`
+	ToState    string          `json:"to_state"`
+	Spot       CapacitySpotRef `json:"tourist_spot"`
+}
+
+type CapacitySpotRef struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	CurrentCount int    `json:"current_count"`
+	MaxCapacity  int    `json:"max_capacity"`
+}
+
+// 未設定の項目を既定値で補う
+func (p *SpotCapacityPolicy) applyDefaults() {
+	if p.SoftLimitRatio <= 0 {
+		p.SoftLimitRatio = defaultCapacitySoftRatio
+	}
+	if p.HardLimitRatio <= 0 {
+		p.HardLimitRatio = defaultCapacityHardRatio
+	}
+	if p.ReopenRatio <= 0 {
+		p.ReopenRatio = p.HardLimitRatio * defaultCapacityReopenRatio / 100
+	}
+	if p.State == "" {
+		p.State = CapacityStateNormal
+	}
+}
+
+// 設定の検証
+func (p *SpotCapacityPolicy) Validate() error {
+	if p.HardLimitRatio > 100 {
+		return newLocalizedError("capacity.hard_limit_invalid")
+	}
+	if p.SoftLimitRatio > p.HardLimitRatio {
+		return newLocalizedError("capacity.soft_limit_invalid")
+	}
+	if p.ReopenRatio >= p.HardLimitRatio {
+		return newLocalizedError("capacity.reopen_invalid")
+	}
+	return nil
+}
+
+// 各閾値を人数に換算（ソフト上限は切り上げ、ハード上限・再開は切り捨て）
+func (p *SpotCapacityPolicy) Limits(spot *TouristSpot) (soft, hard, reopen int) {
+	capacity := float64(spot.MaxCapacity)
+	soft = int(math.Ceil(capacity * p.SoftLimitRatio / 100))
+	hard = int(math.Floor(capacity * p.HardLimitRatio / 100))
+	reopen = int(math.Floor(capacity * p.ReopenRatio / 100))
+	return soft, hard, reopen
+}
+
+// 現在の人数から次の状態を求める（満員は再開の閾値まで下がるまで維持）
+func (p *SpotCapacityPolicy) NextState(spot *TouristSpot) string {
+	if spot.MaxCapacity <= 0 {
+		return CapacityStateNormal
+	}
+	soft, hard, reopen := p.Limits(spot)
+	count := spot.CurrentCount
+	switch {
+	case count >= hard:
+		return CapacityStateFull
+	case p.State == CapacityStateFull && count > reopen:
+		return CapacityStateFull
+	case count >= soft:
+		return CapacityStateBusy
+	default:
+		return CapacityStateNormal
+	}
+}
+
+// 入場状況（ポリシーが無効の場合は許容人数を上限とし、状態は人数から求める）
+func (p *SpotCapacityPolicy) Status(spot *TouristSpot) CapacityStatus {
+	soft, hard, reopen := p.Limits(spot)
+	state := p.State
+	if !p.Enabled {
+		state = p.NextState(spot)
+	}
+	return CapacityStatus{
+		Enabled:   p.Enabled,
+		State:     state,
+		SoftLimit: soft,
+		HardLimit: hard,
+		ReopenAt:  reopen,
+		Available: max(hard-spot.CurrentCount, 0),
+	}
+}
+
+// 観光地のキャパシティポリシー（未作成の場合は無効の既定値）
+func GetCapacityPolicy(db *gorm.DB, spotID uint) (*SpotCapacityPolicy, error) {
+	policy := SpotCapacityPolicy{TouristSpotID: spotID}
+	if err := db.Where("tourist_spot_id = ?", spotID).Limit(1).Find(&policy).Error; err != nil {
+		return nil, err
+	}
+	policy.applyDefaults()
+	return &policy, nil
+}
+
+// 観光地の入場状況
+func GetCapacityStatus(db *gorm.DB, spot *TouristSpot) (*CapacityStatus, error) {
+	policy, err := GetCapacityPolicy(db, spot.ID)
+	if err != nil {
+		return nil, err
+	}
+	status := policy.Status(spot)
+	return &status, nil
+}
+
+// 人数の変化に応じて状態を切り替え、遷移を記録してイベントを送る（遷移しなかった場合はnil）
+// 同時に複数の更新があっても、条件付きUPDATEにより遷移は1回だけ記録される
+func EvaluateCapacityState(db *gorm.DB, redisClient *redis.Client, spot *TouristSpot, source string) (*SpotCapacityTransition, error) {
+	policy, err := GetCapacityPolicy(db, spot.ID)
+	if err != nil || !policy.Enabled {
+		return nil, err
+	}
+	next := policy.NextState(spot)
+	if next == policy.State {
+		return nil, nil
+	}
+
+	now := time.Now()
+	result := db.Model(&SpotCapacityPolicy{}).
+		Where("tourist_spot_id = ? AND state = ?", spot.ID, policy.State).
+		Updates(map[string]interface{}{"state": next, "state_changed_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	transition := SpotCapacityTransition{
+		TouristSpotID: spot.ID,
+		FromState:     policy.State,
+		ToState:       next,
+		CurrentCount:  spot.CurrentCount,
+		MaxCapacity:   spot.MaxCapacity,
+		Source:        source,
+		CreatedAt:     now,
+	}
+	if err := db.Create(&transition).Error; err != nil {
+		fmt.Printf("⚠️ 入場状態の遷移の記録に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+	}
+
+	// 購読中のクライアントへ配信
+	if redisClient != nil {
+		ev := NewCongestionEvent(db, spot, "capacity")
+		ev.PreviousCapacityState = transition.FromState
+		if err := PublishCongestionEvent(context.Background(), redisClient, ev); err != nil {
+			fmt.Printf("⚠️ 入場状態の配信に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+		}
+	}
+
+	// 通知先のWebhookへ送る
+	if policy.WebhookID != nil {
+		payload := CapacityPayload{
+			Event:      CapacityEventChanged,
+			OccurredAt: now,
+			FromState:  transition.FromState,
+			ToState:    transition.ToState,
+			Spot:       CapacitySpotRef{ID: spot.ID, Name: spot.Name, CurrentCount: spot.CurrentCount, MaxCapacity: spot.MaxCapacity},
+		}
+		spotID := spot.ID
+		if _, err := EnqueueWebhookDelivery(db, *policy.WebhookID, CapacityEventChanged, payload, nil, &spotID); err != nil {
+			fmt.Printf("⚠️ 入場状態の通知の登録に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+		}
+	}
+	return &transition, nil
+}
+
+// 遷移の判定を行い、失敗しても更新自体は成功させる
+func evaluateCapacityState(db *gorm.DB, redisClient *redis.Client, spot *TouristSpot, source string) {
+	if _, err := EvaluateCapacityState(db, redisClient, spot, source); err != nil {
+		fmt.Printf("⚠️ 入場状態の判定に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+	}
+}
+
+// 近くにある同じカテゴリで空きのある観光地（距離の近い順）
+// 空きがあるのは営業中かつソフト上限未満の観光地（ポリシーがない観光地は既定の閾値で判定）
+func FindCapacityAlternatives(db *gorm.DB, spot *TouristSpot, limit int, exclude ...uint) ([]TouristSpot, error) {
+	query := preloadSpotSchedule(db).Where("id <> ? AND max_capacity > 0 AND current_count < max_capacity", spot.ID)
+	switch {
+	case spot.CategoryID != nil:
+		query = query.Where("category_id = ?", *spot.CategoryID)
+	case spot.Category != "":
+		query = query.Where("category_id IS NULL AND category = ?", spot.Category)
+	default:
+		return []TouristSpot{}, nil
+	}
+	if len(exclude) > 0 {
+		query = query.Where("id NOT IN ?", exclude)
+	}
+	var candidates []TouristSpot
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	policies := map[uint]SpotCapacityPolicy{}
+	if len(candidates) > 0 {
+		ids := make([]uint, len(candidates))
+		for i := range candidates {
+			ids[i] = candidates[i].ID
+		}
+		var rows []SpotCapacityPolicy
+		if err := db.Where("tourist_spot_id IN ?", ids).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			policies[row.TouristSpotID] = row
+		}
+	}
+
+	now := time.Now()
+	alternatives := []TouristSpot{}
+	for _, candidate := range candidates {
+		policy := policies[candidate.ID]
+		policy.applyDefaults()
+		if policy.Enabled && policy.State != CapacityStateNormal {
+			continue
+		}
+		if soft, _, _ := policy.Limits(&candidate); candidate.CurrentCount >= soft {
+			continue
+		}
+		if !candidate.OpenStatusAt(now).IsOpen {
+			continue
+		}
+		distance := calculateDistance(spot.X, spot.Y, candidate.X, candidate.Y)
+		candidate.Distance = &distance
+		alternatives = append(alternatives, candidate)
+	}
+	sort.SliceStable(alternatives, func(i, j int) bool { return *alternatives[i].Distance < *alternatives[j].Distance })
+	if limit > 0 && len(alternatives) > limit {
+		alternatives = alternatives[:limit]
+	}
+
+	service := NewCongestionService(db)
+	for i := range alternatives {
+		status := service.SpotStatus(&alternatives[i])
+		alternatives[i].Congestion = &status
+	}
+	return alternatives, nil
+}
+
+// 入場状況を設定し、混雑・満員の場合は代替観光地も設定する
+func FillSpotCapacity(db *gorm.DB, spot *TouristSpot, exclude ...uint) error {
+	status, err := GetCapacityStatus(db, spot)
+	if err != nil {
+		return err
+	}
+	spot.Capacity = status
+	if status.State == CapacityStateNormal {
+		return nil
+	}
+	alternatives, err := FindCapacityAlternatives(db, spot, defaultAlternativeLimit, exclude...)
+	if err != nil {
+		return err
+	}
+	spot.Alternatives = alternatives
+	return nil
+}
+
+// 混雑・満員の場合の警告文のメッセージID（通常の場合は空）
+func capacityWarningID(status *CapacityStatus) string {
+	switch {
+	case status == nil:
+		return ""
+	case status.State == CapacityStateFull:
+		return "capacity.full_warning"
+	case status.State == CapacityStateBusy:
+		return "capacity.busy_warning"
+	}
+	return ""
+}
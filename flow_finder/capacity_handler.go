@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 入場制限（キャパシティポリシー）関連のルートを登録
+func RegisterCapacityRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	spots := r.Group("/api/tourist-spots/:id")
+	{
+		// 入場状況（混雑・満員の場合は代替観光地も返す）
+		spots.GET("/capacity", getSpotCapacityHandler(db))
+
+		// キャパシティポリシーの取得・更新（spot:edit）
+		spots.GET("/capacity/policy", getCapacityPolicyHandler(db))
+		spots.PUT("/capacity/policy", updateCapacityPolicyHandler(db, redisClient))
+
+		// 状態の遷移記録（新しい順、?limit=50）
+		spots.GET("/capacity/transitions", listCapacityTransitionsHandler(db))
+
+		// 近くにある同じカテゴリで空きのある観光地（?limit=3）
+		spots.GET("/alternatives", getSpotAlternativesHandler(db))
+	}
+}
+
+// 入場状況取得ハンドラ
+func getSpotCapacityHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		if err := FillSpotCapacity(db, &spot); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "capacity.fetch_failed")})
+			return
+		}
+		TranslateSpots(db, RequestLocale(c), spot.Alternatives)
+		c.JSON(http.StatusOK, gin.H{
+			"tourist_spot_id": spot.ID,
+			"current_count":   spot.CurrentCount,
+			"max_capacity":    spot.MaxCapacity,
+			"capacity":        spot.Capacity,
+			"alternatives":    spot.Alternatives,
+		})
+	}
+}
+
+// キャパシティポリシー取得ハンドラ（未作成の場合は無効の既定値）
+func getCapacityPolicyHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		policy, err := GetCapacityPolicy(db, spot.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "capacity.fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"policy": policy, "capacity": policy.Status(&spot)})
+	}
+}
+
+type capacityPolicyRequest struct {
+	Enabled        *bool    `json:"enabled"`
+	SoftLimitRatio *float64 `json:"soft_limit_ratio"`
+	HardLimitRatio *float64 `json:"hard_limit_ratio"`
+	ReopenRatio    *float64 `json:"reopen_ratio"`
+	WebhookID      *uint    `json:"webhook_id"` // 0 で通知先を解除
+}
+
+// キャパシティポリシー更新ハンドラ（未作成の場合は作成）
+func updateCapacityPolicyHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		var req capacityPolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		var policy SpotCapacityPolicy
+		created := false
+		if err := db.Where("tourist_spot_id = ?", spot.ID).First(&policy).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "capacity.fetch_failed")})
+				return
+			}
+			policy = SpotCapacityPolicy{TouristSpotID: spot.ID}
+			created = true
+		}
+		before := policy
+
+		if req.Enabled != nil {
+			policy.Enabled = *req.Enabled
+		}
+		if req.SoftLimitRatio != nil {
+			policy.SoftLimitRatio = *req.SoftLimitRatio
+		}
+		if req.HardLimitRatio != nil {
+			policy.HardLimitRatio = *req.HardLimitRatio
+		}
+		if req.ReopenRatio != nil {
+			policy.ReopenRatio = *req.ReopenRatio
+		}
+		if req.WebhookID != nil {
+			if *req.WebhookID == 0 {
+				policy.WebhookID = nil
+			} else {
+				if err := db.Select("id").First(&Webhook{}, *req.WebhookID).Error; err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "webhook.not_found")})
+					return
+				}
+				policy.WebhookID = req.WebhookID
+			}
+		}
+		policy.applyDefaults()
+		if err := policy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if !policy.Enabled {
+			// 無効にした場合、状態は次に有効にしたときに判定し直す
+			policy.State = CapacityStateNormal
+		}
+
+		var err error
+		if created {
+			err = db.Create(&policy).Error
+		} else {
+			err = db.Model(&policy).Select("enabled", "soft_limit_ratio", "hard_limit_ratio", "reopen_ratio", "webhook_id", "state").Updates(&policy).Error
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "capacity.update_failed")})
+			return
+		}
+
+		action := "update"
+		var beforeValue interface{} = before
+		if created {
+			action, beforeValue = "create", nil
+		}
+		RecordChangeHistory(db, "spot_capacity_policies", strconv.Itoa(int(spot.ID)), currentUserIDPtr(c), action, beforeValue, policy)
+
+		// 閾値の変更で満員・再開をまたぐ場合がある
+		evaluateCapacityState(db, redisClient, &spot, "policy")
+		if latest, err := GetCapacityPolicy(db, spot.ID); err == nil {
+			policy = *latest
+		}
+		c.JSON(http.StatusOK, gin.H{"policy": policy, "capacity": policy.Status(&spot)})
+	}
+}
+
+// 状態の遷移記録一覧ハンドラ
+func listCapacityTransitionsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > 500 {
+			limit = 50
+		}
+		var transitions []SpotCapacityTransition
+		if err := db.Where("tourist_spot_id = ?", c.Param("id")).
+			Order("created_at DESC, id DESC").Limit(limit).Find(&transitions).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "capacity.transitions_fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, transitions)
+	}
+}
+
+// 代替観光地取得ハンドラ
+func getSpotAlternativesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAlternativeLimit)))
+		if err != nil || limit < 1 || limit > maxAlternativeLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot_query.limit_range", maxAlternativeLimit)})
+			return
+		}
+		alternatives, err := FindCapacityAlternatives(db, &spot, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "capacity.alternatives_failed")})
+			return
+		}
+		TranslateSpots(db, RequestLocale(c), alternatives)
+		c.JSON(http.StatusOK, alternatives)
+	}
+}
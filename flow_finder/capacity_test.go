@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSpotCapacityPolicyLimits(t *testing.T) {
+	tests := []struct {
+		name                         string
+		policy                       SpotCapacityPolicy
+		maxCapacity                  int
+		wantSoft, wantHard, wantOpen int
+	}{
+		{name: "既定値", maxCapacity: 10, wantSoft: 8, wantHard: 10, wantOpen: 9},
+		{name: "ソフト上限は切り上げ", policy: SpotCapacityPolicy{SoftLimitRatio: 80}, maxCapacity: 7, wantSoft: 6, wantHard: 7, wantOpen: 6},
+		{name: "ハード上限・再開は切り捨て", policy: SpotCapacityPolicy{SoftLimitRatio: 80, HardLimitRatio: 95, ReopenRatio: 85}, maxCapacity: 33, wantSoft: 27, wantHard: 31, wantOpen: 28},
+		{name: "割り切れる場合", policy: SpotCapacityPolicy{SoftLimitRatio: 50, HardLimitRatio: 75, ReopenRatio: 60}, maxCapacity: 40, wantSoft: 20, wantHard: 30, wantOpen: 24},
+		{name: "許容人数なし", maxCapacity: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			policy.applyDefaults()
+			soft, hard, reopen := policy.Limits(&TouristSpot{MaxCapacity: tt.maxCapacity})
+			if soft != tt.wantSoft || hard != tt.wantHard || reopen != tt.wantOpen {
+				t.Errorf("Limits = (%d, %d, %d), want (%d, %d, %d)", soft, hard, reopen, tt.wantSoft, tt.wantHard, tt.wantOpen)
+			}
+		})
+	}
+}
+
+// 許容人数100人・既定値（ソフト80人、ハード100人、再開90人）
+func TestSpotCapacityPolicyNextState(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		count       int
+		maxCapacity int
+		want        string
+	}{
+		{name: "ソフト上限未満", state: CapacityStateNormal, count: 79, want: CapacityStateNormal},
+		{name: "ソフト上限ちょうど", state: CapacityStateNormal, count: 80, want: CapacityStateBusy},
+		{name: "ハード上限の手前", state: CapacityStateNormal, count: 99, want: CapacityStateBusy},
+		{name: "ハード上限ちょうど", state: CapacityStateBusy, count: 100, want: CapacityStateFull},
+		{name: "ハード上限を超えている", state: CapacityStateNormal, count: 105, want: CapacityStateFull},
+		{name: "混雑から再開の閾値より上", state: CapacityStateBusy, count: 95, want: CapacityStateBusy},
+		{name: "満員は再開の閾値より上なら維持", state: CapacityStateFull, count: 91, want: CapacityStateFull},
+		{name: "満員から再開の閾値まで下がったら混雑", state: CapacityStateFull, count: 90, want: CapacityStateBusy},
+		{name: "満員からソフト上限未満まで下がった", state: CapacityStateFull, count: 50, want: CapacityStateNormal},
+		{name: "混雑からソフト上限未満", state: CapacityStateBusy, count: 79, want: CapacityStateNormal},
+		{name: "許容人数なし", state: CapacityStateFull, count: 10, maxCapacity: -1, want: CapacityStateNormal}, // 0は既定の100人になるため負の値で表す
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := SpotCapacityPolicy{Enabled: true, State: tt.state}
+			policy.applyDefaults()
+			maxCapacity := tt.maxCapacity
+			if maxCapacity == 0 {
+				maxCapacity = 100
+			}
+			if got := policy.NextState(&TouristSpot{CurrentCount: tt.count, MaxCapacity: maxCapacity}); got != tt.want {
+				t.Errorf("NextState = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpotCapacityPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SpotCapacityPolicy
+		wantID string // 空の場合はエラーなし
+	}{
+		{name: "既定値", policy: SpotCapacityPolicy{}},
+		{name: "ソフト上限とハード上限が同じ", policy: SpotCapacityPolicy{SoftLimitRatio: 90, HardLimitRatio: 90, ReopenRatio: 80}},
+		{name: "ハード上限が100%を超える", policy: SpotCapacityPolicy{HardLimitRatio: 110}, wantID: "capacity.hard_limit_invalid"},
+		{name: "ソフト上限がハード上限を超える", policy: SpotCapacityPolicy{SoftLimitRatio: 95, HardLimitRatio: 90}, wantID: "capacity.soft_limit_invalid"},
+		{name: "再開がハード上限と同じ", policy: SpotCapacityPolicy{HardLimitRatio: 90, ReopenRatio: 90}, wantID: "capacity.reopen_invalid"},
+		{name: "再開がハード上限を超える", policy: SpotCapacityPolicy{HardLimitRatio: 90, ReopenRatio: 95}, wantID: "capacity.reopen_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			policy.applyDefaults()
+			err := policy.Validate()
+			if tt.wantID == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			var lerr *LocalizedError
+			if !errors.As(err, &lerr) || lerr.ID != tt.wantID {
+				t.Errorf("err = %v, want %s", err, tt.wantID)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -13,21 +14,22 @@ func RegisterCategoryGroupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.
 	r.GET("/api/category-groups", func(c *gin.Context) {
 		var groups []CategoryGroup
 		if err := db.Where("is_active = ?", true).Order("display_order ASC, id ASC").Find(&groups).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "取得に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.fetch_failed")})
 			return
 		}
+		TranslateGroups(db, RequestLocale(c), groups)
 		c.JSON(http.StatusOK, groups)
 	})
 
 	// 管理者: グループ作成
-	r.POST("/api/category-groups", AdminRequired(db, redisClient), func(c *gin.Context) {
+	r.POST("/api/category-groups", func(c *gin.Context) {
 		var body struct {
 			Name         string `json:"name" binding:"required"`
 			DisplayOrder int    `json:"display_order"`
 			IsActive     *bool  `json:"is_active"`
 		}
 		if err := c.ShouldBindJSON(&body); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "nameは必須です"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.name_required")})
 			return
 		}
 		isActive := true
@@ -36,17 +38,18 @@ func RegisterCategoryGroupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.
 		}
 		group := CategoryGroup{Name: body.Name, DisplayOrder: body.DisplayOrder, IsActive: isActive}
 		if err := db.Create(&group).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "作成に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.create_failed")})
 			return
 		}
+		refreshSearchIndex(db, SearchTypeGroup, group.ID)
 		c.JSON(http.StatusCreated, group)
 	})
 
 	// 管理者: グループ更新
-	r.PUT("/api/category-groups/:id", AdminRequired(db, redisClient), func(c *gin.Context) {
+	r.PUT("/api/category-groups/:id", func(c *gin.Context) {
 		var group CategoryGroup
 		if err := db.First(&group, c.Param("id")).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "グループが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "group.not_found")})
 			return
 		}
 		var body struct {
@@ -55,7 +58,7 @@ func RegisterCategoryGroupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.
 			IsActive     *bool   `json:"is_active"`
 		}
 		if err := c.ShouldBindJSON(&body); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "リクエストが無効です"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 		if body.Name != nil {
@@ -68,14 +71,19 @@ func RegisterCategoryGroupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.
 			group.IsActive = *body.IsActive
 		}
 		db.Save(&group)
+		refreshSearchIndex(db, SearchTypeGroup, group.ID)
 		c.JSON(http.StatusOK, group)
 	})
 
 	// 管理者: グループ削除
-	r.DELETE("/api/category-groups/:id", AdminRequired(db, redisClient), func(c *gin.Context) {
+	r.DELETE("/api/category-groups/:id", func(c *gin.Context) {
 		// グループを使用しているカテゴリのgroup_idをNULLに
 		db.Model(&TouristSpotCategory{}).Where("group_id = ?", c.Param("id")).Update("group_id", nil)
 		db.Delete(&CategoryGroup{}, c.Param("id"))
+		if id, err := strconv.ParseUint(c.Param("id"), 10, 32); err == nil {
+			refreshSearchIndex(db, SearchTypeGroup, uint(id))
+			DeleteTranslations(db, TranslationTypeGroup, uint(id))
+		}
 		c.Status(http.StatusNoContent)
 	})
 }
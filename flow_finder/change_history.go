@@ -33,6 +33,24 @@ func MigrateChangeHistory(db *gorm.DB) error {
 
 // 変更履歴を記録するヘルパー関数
 func RecordChangeHistory(db *gorm.DB, tableName string, recordID string, userID *uint, operation string, before interface{}, after interface{}) {
+	history := newChangeHistory(tableName, recordID, userID, operation, before, after)
+
+	// バックグラウンドで非同期保存
+	go func() {
+		if err := db.Create(&history).Error; err != nil {
+			fmt.Printf("変更履歴保存エラー: %v\n", err)
+		}
+	}()
+}
+
+// トランザクション内で変更履歴を同期的に記録する
+// 非同期の RecordChangeHistory にトランザクションを渡すとコミット後の保存になり失われるため、こちらを使う
+func RecordChangeHistoryTx(tx *gorm.DB, tableName string, recordID string, userID *uint, operation string, before interface{}, after interface{}) error {
+	history := newChangeHistory(tableName, recordID, userID, operation, before, after)
+	return tx.Create(&history).Error
+}
+
+func newChangeHistory(tableName string, recordID string, userID *uint, operation string, before interface{}, after interface{}) ChangeHistory {
 	beforeJSON := ""
 	afterJSON := ""
 
@@ -48,7 +66,7 @@ func RecordChangeHistory(db *gorm.DB, tableName string, recordID string, userID
 		}
 	}
 
-	history := ChangeHistory{
+	return ChangeHistory{
 		TableName: tableName,
 		RecordID:  recordID,
 		UserID:    userID,
@@ -56,13 +74,6 @@ func RecordChangeHistory(db *gorm.DB, tableName string, recordID string, userID
 		Before:    beforeJSON,
 		After:     afterJSON,
 	}
-
-	// バックグラウンドで非同期保存
-	go func() {
-		if err := db.Create(&history).Error; err != nil {
-			fmt.Printf("変更履歴保存エラー: %v\n", err)
-		}
-	}()
 }
 
 // 変更履歴取得用のAPIエンドポイントを登録
@@ -101,7 +112,7 @@ func RegisterChangeHistoryRoutes(r *gin.Engine, db *gorm.DB) {
 		query.Session(&gorm.Session{}).Count(&total)
 
 		if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&histories).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch change history"})
+			c.JSON(500, gin.H{"error": T(c, "history.change_fetch_failed")})
 			return
 		}
 
@@ -132,7 +143,7 @@ func RegisterChangeHistoryRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 
 		if err := query.Order("created_at DESC").Find(&histories).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch change history"})
+			c.JSON(500, gin.H{"error": T(c, "history.change_fetch_failed")})
 			return
 		}
 
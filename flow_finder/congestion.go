@@ -1,32 +1,215 @@
 package main
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// 混雑記録モデル
+// 混雑度（全エンドポイント共通の尺度）
+// 混雑率（現在の人数 / 許容人数）の区間で6段階に分け、判定できない場合は不明とする
+type CongestionLevel int
+
+const (
+	CongestionUnknown  CongestionLevel = -1 // 不明（許容人数も最近の手動記録もない）
+	CongestionEmpty    CongestionLevel = 0  // 空いている    （20%未満）
+	CongestionQuiet    CongestionLevel = 1  // 少し空いている（20〜40%未満）
+	CongestionModerate CongestionLevel = 2  // 普通          （40〜60%未満）
+	CongestionBusy     CongestionLevel = 3  // 混雑          （60〜80%未満）
+	CongestionVeryBusy CongestionLevel = 4  // 非常に混雑    （80〜100%未満）
+	CongestionFull     CongestionLevel = 5  // 満員          （100%以上）
+	CongestionMaxLevel                 = CongestionFull
+)
+
+const (
+	congestionUnknownLabel   = "不明"
+	congestionRecordFreshFor = 30 * time.Minute // 手動記録を現在の混雑度として扱う期間
+)
+
+// 各段階の表示名と、その段階になる混雑率（%）の下限
+var congestionLevelDefs = []struct {
+	Label    string
+	MinRatio float64
+}{
+	{"空いている", 0},
+	{"少し空いている", 20},
+	{"普通", 40},
+	{"混雑", 60},
+	{"非常に混雑", 80},
+	{"満員", 100},
+}
+
+// 混雑度の表示名
+func (l CongestionLevel) Label() string {
+	if !l.Valid() {
+		return congestionUnknownLabel
+	}
+	return congestionLevelDefs[l].Label
+}
+
+// 0〜5の範囲かどうか
+func (l CongestionLevel) Valid() bool {
+	return l >= CongestionEmpty && l <= CongestionMaxLevel
+}
+
+// その段階になる混雑率（%）の下限
+func (l CongestionLevel) MinRatio() float64 {
+	if !l.Valid() {
+		return 0
+	}
+	return congestionLevelDefs[l].MinRatio
+}
+
+// 段階の代表値となる混雑率（%）。手動記録を混雑率として集計・予測に使う場合に用いる
+func (l CongestionLevel) RepresentativeRatio() float64 {
+	if !l.Valid() {
+		return 0
+	}
+	if l == CongestionFull {
+		return 100
+	}
+	return (congestionLevelDefs[l].MinRatio + congestionLevelDefs[l+1].MinRatio) / 2
+}
+
+// 混雑率（%）から混雑度を求める
+func CongestionLevelFromRatio(ratio float64) CongestionLevel {
+	for l := CongestionMaxLevel; l > CongestionEmpty; l-- {
+		if ratio >= congestionLevelDefs[l].MinRatio {
+			return l
+		}
+	}
+	return CongestionEmpty
+}
+
+// 表示名から混雑度を求める（例: "非常に混雑" → 4）
+func CongestionLevelFromLabel(label string) (CongestionLevel, bool) {
+	for i, def := range congestionLevelDefs {
+		if def.Label == label {
+			return CongestionLevel(i), true
+		}
+	}
+	return CongestionUnknown, false
+}
+
+// 混雑度の判定根拠
+const (
+	CongestionBasisCount     = "count"     // 来場者数（センサー・ゲートからの入力を含む）と許容人数
+	CongestionBasisRecord    = "record"    // スタッフによる手動記録
+	CongestionBasisAggregate = "aggregate" // ノード・カテゴリに属する観光地の集計
+	CongestionBasisNone      = "none"      // 判定材料なし
+)
+
+// 混雑状況（APIで返す共通の形）
+type CongestionStatus struct {
+	Level        CongestionLevel `json:"level"` // -1（不明）〜5
+	Label        string          `json:"label"` // 表示名
+	Ratio        *float64        `json:"ratio"` // 混雑率（%）。手動記録のみの場合は代表値
+	CurrentCount *int            `json:"current_count,omitempty"`
+	MaxCapacity  *int            `json:"max_capacity,omitempty"`
+	Basis        string          `json:"basis"` // 判定根拠
+	UpdatedAt    *time.Time      `json:"updated_at,omitempty"`
+}
+
+// 判定できない場合の混雑状況
+func UnknownCongestion() CongestionStatus {
+	return CongestionStatus{Level: CongestionUnknown, Label: congestionUnknownLabel, Basis: CongestionBasisNone}
+}
+
+// 来場者数と許容人数から混雑状況を求める
+func CongestionFromCounts(current, capacity int) CongestionStatus {
+	if capacity <= 0 {
+		return UnknownCongestion()
+	}
+	ratio := math.Round(float64(current)/float64(capacity)*1000) / 10
+	level := CongestionLevelFromRatio(ratio)
+	return CongestionStatus{
+		Level:        level,
+		Label:        level.Label(),
+		Ratio:        &ratio,
+		CurrentCount: &current,
+		MaxCapacity:  &capacity,
+		Basis:        CongestionBasisCount,
+	}
+}
+
+// 手動記録から混雑状況を求める
+func CongestionFromRecord(rec *CongestionRecord) CongestionStatus {
+	level := CongestionLevel(rec.Level)
+	if !level.Valid() {
+		return UnknownCongestion()
+	}
+	ratio := rec.Ratio
+	recordedAt := rec.RecordedAt
+	return CongestionStatus{
+		Level:     level,
+		Label:     level.Label(),
+		Ratio:     &ratio,
+		Basis:     CongestionBasisRecord,
+		UpdatedAt: &recordedAt,
+	}
+}
+
+// 混雑記録モデル（スタッフによる手動記録）
 type CongestionRecord struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
 	TouristSpotID uint      `gorm:"index;not null" json:"tourist_spot_id"`
-	Level         int       `gorm:"not null" json:"level"` // 0-3
+	Level         int       `gorm:"not null" json:"level"` // 混雑度（0-5、CongestionLevel）
+	Label         string    `gorm:"-" json:"label"`        // 表示名（レスポンス用）
+	Ratio         float64   `json:"ratio"`                 // 混雑度の代表値（%）。履歴・予測の集計に使う
 	RecordedAt    time.Time `gorm:"not null" json:"recorded_at"`
 	Note          string    `json:"note"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+func (r *CongestionRecord) AfterFind(tx *gorm.DB) error {
+	r.Label = CongestionLevel(r.Level).Label()
+	return nil
+}
+
 // マイグレーション用
 func MigrateCongestionRecord(db *gorm.DB) error {
 	return db.AutoMigrate(&CongestionRecord{})
 }
 
+// 旧形式（4段階: 0=混雑なし, 1=やや混雑, 2=混雑, 3=非常に混雑）の記録が残っているか
+// 旧形式の記録は ratio が未設定（NULL）のため、AutoMigrateの後に呼び出す
+// 変換が途中で止まった場合も、次回起動時に未変換の記録を検出できる
+func NeedsCongestionScaleMigration(db *gorm.DB) (bool, error) {
+	var count int64
+	if err := db.Model(&CongestionRecord{}).Where("ratio IS NULL").Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// 旧形式の記録を6段階の尺度に変換する（AutoMigrateの後に呼び出す）
+func MigrateCongestionScale(db *gorm.DB) error {
+	legacy := map[int]CongestionLevel{0: CongestionEmpty, 1: CongestionModerate, 2: CongestionBusy, 3: CongestionVeryBusy}
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 未変換（ratioが未設定）の記録だけを変換するため、変換済みの値を再度変換しない
+		for old := 3; old >= 0; old-- {
+			level := legacy[old]
+			if err := tx.Model(&CongestionRecord{}).Where("level = ? AND ratio IS NULL", old).
+				Updates(map[string]interface{}{"level": int(level), "ratio": level.RepresentativeRatio()}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // 追加
-func AddCongestionRecord(db *gorm.DB, spotID uint, level int, recordedAt time.Time, note string) (*CongestionRecord, error) {
+func AddCongestionRecord(db *gorm.DB, spotID uint, level CongestionLevel, recordedAt time.Time, note string) (*CongestionRecord, error) {
+	if !level.Valid() {
+		return nil, newLocalizedError("congestion.level_range", CongestionMaxLevel)
+	}
 	rec := &CongestionRecord{
 		TouristSpotID: spotID,
-		Level:         level,
+		Level:         int(level),
+		Label:         level.Label(),
+		Ratio:         level.RepresentativeRatio(),
 		RecordedAt:    recordedAt,
 		Note:          note,
 	}
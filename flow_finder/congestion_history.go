@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	_ "time/tzdata" // 実行用イメージにタイムゾーン情報がないため埋め込む
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 混雑状況のスナップショット（CurrentCount/MaxCapacity を定期的に記録）
+type CongestionSnapshot struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TouristSpotID uint      `gorm:"not null;index:idx_congestion_snapshot_spot_time,priority:1" json:"tourist_spot_id"`
+	CategoryID    *uint     `gorm:"index:idx_congestion_snapshot_category_time,priority:1" json:"category_id"` // 記録時点のカテゴリ
+	CurrentCount  int       `gorm:"not null" json:"current_count"`
+	MaxCapacity   int       `gorm:"not null" json:"max_capacity"`
+	Ratio         float64   `gorm:"not null" json:"ratio"` // 混雑率（%）
+	RecordedAt    time.Time `gorm:"not null;index:idx_congestion_snapshot_spot_time,priority:2;index:idx_congestion_snapshot_category_time,priority:2;index" json:"recorded_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+const (
+	defaultSnapshotInterval  = 5 * time.Minute
+	defaultSnapshotRetention = 90 * 24 * time.Hour
+	defaultHistoryTimeZone   = "Asia/Tokyo"
+	congestionHistoryMaxBins = 2000 // 1リクエストで返す最大バケット数
+)
+
+// 集計の粒度（PostgreSQLのdate_binに渡す間隔）
+var congestionHistoryIntervals = map[string]string{
+	"5m": "5 minutes",
+	"1h": "1 hour",
+	"1d": "1 day",
+}
+
+var congestionHistoryDurations = map[string]time.Duration{
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// 集計対象のデータ
+const (
+	CongestionSourceSnapshot = "snapshot" // 自動記録された混雑率（%）
+	CongestionSourceRecord   = "record"   // 手動記録された混雑度（0-5）
+	CongestionSourceSensor   = "sensor"   // センサーの入退場数（イベント発生時刻で集計するため遅延分も正しい区間に入る）
+)
+
+// 集計結果の1区間
+type CongestionHistoryBucket struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Count       int64     `json:"count"`
+	Min         float64   `json:"min"`
+	Max         float64   `json:"max"`
+	Avg         float64   `json:"avg"`
+	In          *int64    `gorm:"column:sensor_in" json:"in,omitempty"`   // 入場数の合計（sensorのみ）
+	Out         *int64    `gorm:"column:sensor_out" json:"out,omitempty"` // 退場数の合計（sensorのみ）
+}
+
+// 履歴の集計条件
+type CongestionHistoryQuery struct {
+	TouristSpotID uint   // 観光地で絞り込む場合
+	CategoryID    uint   // カテゴリで絞り込む場合
+	Interval      string // 5m | 1h | 1d
+	Source        string // snapshot | record
+	From          time.Time
+	To            time.Time
+	Location      *time.Location // 日単位の区切りに使うタイムゾーン
+}
+
+// 集計条件を検証
+func (q *CongestionHistoryQuery) Validate() error {
+	step, ok := congestionHistoryDurations[q.Interval]
+	if !ok {
+		return newLocalizedError("history.interval_invalid")
+	}
+	if q.Source != CongestionSourceSnapshot && q.Source != CongestionSourceRecord && q.Source != CongestionSourceSensor {
+		return newLocalizedError("history.source_invalid")
+	}
+	if !q.From.Before(q.To) {
+		return newLocalizedError("history.range_reversed")
+	}
+	if q.To.Sub(q.From)/step > congestionHistoryMaxBins {
+		return newLocalizedError("history.range_too_long", congestionHistoryMaxBins)
+	}
+	return nil
+}
+
+// 混雑履歴を区間ごとに集計（min/max/avg/件数）
+func GetCongestionHistory(db *gorm.DB, q CongestionHistoryQuery) ([]CongestionHistoryBucket, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	// 区間の起点（指定タイムゾーンでのfrom当日0時）
+	y, m, d := q.From.In(loc).Date()
+	origin := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	var query *gorm.DB
+	switch q.Source {
+	case CongestionSourceRecord:
+		query = db.Table("congestion_records").
+			Select("date_bin(?::interval, congestion_records.recorded_at, ?::timestamptz) AS bucket_start, COUNT(*) AS count, MIN(level) AS min, MAX(level) AS max, AVG(level) AS avg",
+				congestionHistoryIntervals[q.Interval], origin).
+			Where("congestion_records.recorded_at >= ? AND congestion_records.recorded_at < ?", q.From, q.To)
+		if q.TouristSpotID != 0 {
+			query = query.Where("congestion_records.tourist_spot_id = ?", q.TouristSpotID)
+		}
+		if q.CategoryID != 0 {
+			query = query.Joins("JOIN tourist_spots ON tourist_spots.id = congestion_records.tourist_spot_id").
+				Where("tourist_spots.category_id = ?", q.CategoryID)
+		}
+	case CongestionSourceSensor:
+		// min/max/avg はイベントごとの入場数 - 退場数
+		query = db.Table("sensor_events").
+			Select("date_bin(?::interval, sensor_events.occurred_at, ?::timestamptz) AS bucket_start, COUNT(*) AS count, MIN(in_count - out_count) AS min, MAX(in_count - out_count) AS max, AVG(in_count - out_count) AS avg, SUM(in_count) AS sensor_in, SUM(out_count) AS sensor_out",
+				congestionHistoryIntervals[q.Interval], origin).
+			Where("sensor_events.occurred_at >= ? AND sensor_events.occurred_at < ?", q.From, q.To)
+		if q.TouristSpotID != 0 {
+			query = query.Where("sensor_events.tourist_spot_id = ?", q.TouristSpotID)
+		}
+		if q.CategoryID != 0 {
+			query = query.Joins("JOIN tourist_spots ON tourist_spots.id = sensor_events.tourist_spot_id").
+				Where("tourist_spots.category_id = ?", q.CategoryID)
+		}
+	default:
+		query = db.Model(&CongestionSnapshot{}).
+			Select("date_bin(?::interval, recorded_at, ?::timestamptz) AS bucket_start, COUNT(*) AS count, MIN(ratio) AS min, MAX(ratio) AS max, AVG(ratio) AS avg",
+				congestionHistoryIntervals[q.Interval], origin).
+			Where("recorded_at >= ? AND recorded_at < ?", q.From, q.To)
+		if q.TouristSpotID != 0 {
+			query = query.Where("tourist_spot_id = ?", q.TouristSpotID)
+		}
+		if q.CategoryID != 0 {
+			query = query.Where("category_id = ?", q.CategoryID)
+		}
+	}
+
+	buckets := []CongestionHistoryBucket{}
+	if err := query.Group("bucket_start").Order("bucket_start").Scan(&buckets).Error; err != nil {
+		return nil, err
+	}
+	for i := range buckets {
+		buckets[i].BucketStart = buckets[i].BucketStart.In(loc)
+	}
+	return buckets, nil
+}
+
+// 全観光地（許容人数が設定されたもの）の現在の混雑率を記録
+func TakeCongestionSnapshot(db *gorm.DB, at time.Time) (int64, error) {
+	result := db.Exec(`
+		INSERT INTO congestion_snapshots (tourist_spot_id, category_id, current_count, max_capacity, ratio, recorded_at, created_at)
+		SELECT id, category_id, current_count, max_capacity, current_count * 100.0 / max_capacity, ?, ?
+		FROM tourist_spots WHERE max_capacity > 0`, at, time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// 保存期間を過ぎたスナップショットを削除
+func PruneCongestionSnapshots(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("recorded_at < ?", before).Delete(&CongestionSnapshot{})
+	return result.RowsAffected, result.Error
+}
+
+// スナップショット取得の設定
+type CongestionSnapshotConfig struct {
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+// 環境変数から設定を読み込む（CONGESTION_SNAPSHOT_INTERVAL=5m, CONGESTION_SNAPSHOT_RETENTION_DAYS=90）
+// CONGESTION_SNAPSHOT_INTERVAL=0 で無効
+func loadCongestionSnapshotConfig() CongestionSnapshotConfig {
+	cfg := CongestionSnapshotConfig{Interval: defaultSnapshotInterval, Retention: defaultSnapshotRetention}
+	if v := os.Getenv("CONGESTION_SNAPSHOT_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Interval = d
+		} else {
+			fmt.Printf("⚠️ CONGESTION_SNAPSHOT_INTERVALが不正です: %s\n", v)
+		}
+	}
+	if v := os.Getenv("CONGESTION_SNAPSHOT_RETENTION_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days > 0 {
+			cfg.Retention = time.Duration(days) * 24 * time.Hour
+		} else {
+			fmt.Printf("⚠️ CONGESTION_SNAPSHOT_RETENTION_DAYSが不正です: %s\n", v)
+		}
+	}
+	return cfg
+}
+
+// 混雑率を定期的に記録するバックグラウンドジョブを開始
+// 複数インスタンスで動かしても、Redisのロックにより各時刻の記録は1回だけ行われる
+func StartCongestionSnapshotJob(ctx context.Context, db *gorm.DB, redisClient *redis.Client, cfg CongestionSnapshotConfig) {
+	if cfg.Interval <= 0 {
+		fmt.Println("混雑率の自動記録は無効です")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				runCongestionSnapshot(ctx, db, redisClient, cfg, now)
+			}
+		}
+	}()
+	fmt.Printf("混雑率の自動記録を開始（間隔: %v）\n", cfg.Interval)
+}
+
+func runCongestionSnapshot(ctx context.Context, db *gorm.DB, redisClient *redis.Client, cfg CongestionSnapshotConfig, now time.Time) {
+	// 記録時刻を間隔の区切りに揃え、その時刻分のロックを取得できたインスタンスだけが記録する
+	at := now.Truncate(cfg.Interval)
+	if redisClient != nil {
+		lockKey := fmt.Sprintf("congestion:snapshot:lock:%d", at.Unix())
+		ok, err := redisClient.SetNX(ctx, lockKey, "1", cfg.Interval).Result()
+		if err != nil {
+			fmt.Printf("⚠️ 混雑率記録のロック取得に失敗: %v\n", err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+
+	n, err := TakeCongestionSnapshot(db, at)
+	if err != nil {
+		fmt.Printf("⚠️ 混雑率の記録に失敗: %v\n", err)
+		return
+	}
+	fmt.Printf("混雑率を記録 - %s (%d件)\n", at.Format(time.RFC3339), n)
+
+	if _, err := PruneCongestionSnapshots(db, now.Add(-cfg.Retention)); err != nil {
+		fmt.Printf("⚠️ 古い混雑率記録の削除に失敗: %v\n", err)
+	}
+}
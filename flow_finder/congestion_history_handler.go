@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 混雑履歴関連のルートを登録
+func RegisterCongestionHistoryRoutes(r *gin.Engine, db *gorm.DB) {
+	// 観光地ごとの混雑履歴（?interval=5m|1h|1d&from=&to=&source=snapshot|record|sensor&tz=Asia/Tokyo）
+	r.GET("/api/tourist-spots/:id/congestion/history", func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.Select("id").First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		respondCongestionHistory(c, db, CongestionHistoryQuery{TouristSpotID: spot.ID})
+	})
+
+	// カテゴリごとの混雑履歴（カテゴリ内の全観光地を集計）
+	r.GET("/api/tourist-spot-categories/:id/congestion/history", func(c *gin.Context) {
+		var category TouristSpotCategory
+		if err := db.Select("id").First(&category, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "category.not_found")})
+			return
+		}
+		respondCongestionHistory(c, db, CongestionHistoryQuery{CategoryID: category.ID})
+	})
+
+	// カテゴリの現在の混雑状況（カテゴリ全体の集計と観光地ごとの状況）
+	r.GET("/api/tourist-spot-categories/:id/congestion", func(c *gin.Context) {
+		var category TouristSpotCategory
+		if err := db.First(&category, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "category.not_found")})
+			return
+		}
+		status, spots, err := NewCongestionService(db).CategoryStatus(category.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "congestion.fetch_failed")})
+			return
+		}
+		items := make([]gin.H, len(spots))
+		for i, spot := range spots {
+			items[i] = gin.H{"id": spot.ID, "name": spot.Name, "congestion": spot.Congestion}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"category_id":   category.ID,
+			"category_name": category.Name,
+			"congestion":    status,
+			"tourist_spots": items,
+		})
+	})
+}
+
+// クエリパラメータを解釈して集計結果を返す
+func respondCongestionHistory(c *gin.Context, db *gorm.DB, q CongestionHistoryQuery) {
+	q.Interval = c.DefaultQuery("interval", "1h")
+	q.Source = c.DefaultQuery("source", CongestionSourceSnapshot)
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", defaultHistoryTimeZone))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.tz_invalid")})
+		return
+	}
+	q.Location = loc
+
+	// 期間（RFC3339、省略時は直近24時間）
+	q.To = time.Now()
+	if v := c.Query("to"); v != "" {
+		if q.To, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.rfc3339_to")})
+			return
+		}
+	}
+	q.From = q.To.Add(-24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		if q.From, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.rfc3339_from")})
+			return
+		}
+	}
+
+	if err := q.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+		return
+	}
+
+	buckets, err := GetCongestionHistory(db, q)
+	if err != nil {
+		fmt.Printf("⚠️ 混雑履歴の集計に失敗: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "history.fetch_failed")})
+		return
+	}
+
+	metric := "ratio"
+	switch q.Source {
+	case CongestionSourceRecord:
+		metric = "level"
+	case CongestionSourceSensor:
+		metric = "net_flow"
+	}
+	resp := gin.H{
+		"interval": q.Interval,
+		"source":   q.Source,
+		"metric":   metric, // ratio: 混雑率（%）, level: 混雑度（0-5）, net_flow: 入場数 - 退場数
+		"from":     q.From.In(loc),
+		"to":       q.To.In(loc),
+		"tz":       loc.String(),
+		"buckets":  buckets,
+	}
+	if q.TouristSpotID != 0 {
+		resp["tourist_spot_id"] = q.TouristSpotID
+	}
+	if q.CategoryID != 0 {
+		resp["category_id"] = q.CategoryID
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 観光地・ノード・カテゴリの混雑状況を求めるサービス
+// 許容人数が設定された観光地は来場者数（センサー入力を含む）から、
+// それ以外は最近の手動記録から判定し、ノード・カテゴリは属する観光地を集計する
+type CongestionService struct {
+	db *gorm.DB
+}
+
+func NewCongestionService(db *gorm.DB) *CongestionService {
+	return &CongestionService{db: db}
+}
+
+// 観光地の混雑状況
+func (s *CongestionService) SpotStatus(spot *TouristSpot) CongestionStatus {
+	return s.SpotStatuses([]TouristSpot{*spot})[spot.ID]
+}
+
+// 複数の観光地の混雑状況（手動記録はまとめて取得する）
+func (s *CongestionService) SpotStatuses(spots []TouristSpot) map[uint]CongestionStatus {
+	statuses := make(map[uint]CongestionStatus, len(spots))
+	var needRecords []uint
+	for i := range spots {
+		if spots[i].MaxCapacity > 0 {
+			status := CongestionFromCounts(spots[i].CurrentCount, spots[i].MaxCapacity)
+			if !spots[i].LastUpdated.IsZero() {
+				updatedAt := spots[i].LastUpdated
+				status.UpdatedAt = &updatedAt
+			}
+			statuses[spots[i].ID] = status
+		} else {
+			statuses[spots[i].ID] = UnknownCongestion()
+			needRecords = append(needRecords, spots[i].ID)
+		}
+	}
+
+	for spotID, rec := range s.latestRecords(needRecords) {
+		statuses[spotID] = CongestionFromRecord(rec)
+	}
+	return statuses
+}
+
+// 観光地ごとの最近の手動記録
+func (s *CongestionService) latestRecords(spotIDs []uint) map[uint]*CongestionRecord {
+	result := map[uint]*CongestionRecord{}
+	if len(spotIDs) == 0 {
+		return result
+	}
+	var recs []CongestionRecord
+	if err := s.db.Raw(`
+		SELECT DISTINCT ON (tourist_spot_id) * FROM congestion_records
+		WHERE tourist_spot_id IN ? AND recorded_at >= ?
+		ORDER BY tourist_spot_id, recorded_at DESC`,
+		spotIDs, time.Now().Add(-congestionRecordFreshFor)).Scan(&recs).Error; err != nil {
+		return result
+	}
+	for i := range recs {
+		result[recs[i].TouristSpotID] = &recs[i]
+	}
+	return result
+}
+
+// 複数の観光地をまとめた混雑状況
+// 許容人数のある観光地は人数の合計で、それ以外は手動記録の代表値の平均で判定する
+func aggregateCongestion(statuses map[uint]CongestionStatus) CongestionStatus {
+	total, capacity := 0, 0
+	var recordRatios []float64
+	var latest time.Time
+	for _, status := range statuses {
+		switch status.Basis {
+		case CongestionBasisCount:
+			total += *status.CurrentCount
+			capacity += *status.MaxCapacity
+		case CongestionBasisRecord:
+			recordRatios = append(recordRatios, *status.Ratio)
+		default:
+			continue
+		}
+		if status.UpdatedAt != nil && status.UpdatedAt.After(latest) {
+			latest = *status.UpdatedAt
+		}
+	}
+
+	var result CongestionStatus
+	switch {
+	case capacity > 0:
+		result = CongestionFromCounts(total, capacity)
+	case len(recordRatios) > 0:
+		sum := 0.0
+		for _, r := range recordRatios {
+			sum += r
+		}
+		ratio := math.Round(sum/float64(len(recordRatios))*10) / 10
+		level := CongestionLevelFromRatio(ratio)
+		result = CongestionStatus{Level: level, Label: level.Label(), Ratio: &ratio}
+	default:
+		return UnknownCongestion()
+	}
+	result.Basis = CongestionBasisAggregate
+	if !latest.IsZero() {
+		result.UpdatedAt = &latest
+	}
+	return result
+}
+
+// ノードの混雑状況（ノードを最寄りとする観光地の集計）
+func (s *CongestionService) NodeStatuses(nodeIDs []uint) (map[uint]CongestionStatus, error) {
+	statuses := make(map[uint]CongestionStatus, len(nodeIDs))
+	if len(nodeIDs) == 0 {
+		return statuses, nil
+	}
+	var spots []TouristSpot
+	if err := s.db.Where("node_id IN ?", nodeIDs).Find(&spots).Error; err != nil {
+		return nil, err
+	}
+	spotStatuses := s.SpotStatuses(spots)
+	byNode := map[uint]map[uint]CongestionStatus{}
+	for _, spot := range spots {
+		if byNode[*spot.NodeID] == nil {
+			byNode[*spot.NodeID] = map[uint]CongestionStatus{}
+		}
+		byNode[*spot.NodeID][spot.ID] = spotStatuses[spot.ID]
+	}
+	for _, id := range nodeIDs {
+		statuses[id] = aggregateCongestion(byNode[id])
+	}
+	return statuses, nil
+}
+
+// カテゴリの混雑状況と、カテゴリ内の観光地ごとの混雑状況
+func (s *CongestionService) CategoryStatus(categoryID uint) (CongestionStatus, []TouristSpot, error) {
+	var spots []TouristSpot
+	if err := s.db.Where("category_id = ?", categoryID).Order("id ASC").Find(&spots).Error; err != nil {
+		return CongestionStatus{}, nil, err
+	}
+	statuses := s.SpotStatuses(spots)
+	for i := range spots {
+		status := statuses[spots[i].ID]
+		spots[i].Congestion = &status
+	}
+	return aggregateCongestion(statuses), spots, nil
+}
+
+// 観光地のレスポンスに混雑状況を設定
+func (s *CongestionService) FillSpots(spots []TouristSpot) {
+	statuses := s.SpotStatuses(spots)
+	for i := range spots {
+		status := statuses[spots[i].ID]
+		spots[i].Congestion = &status
+	}
+}
+
+// ノードのレスポンスに混雑状況を設定
+func (s *CongestionService) FillNodes(nodes []Node) error {
+	ids := make([]uint, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].ID
+	}
+	statuses, err := s.NodeStatuses(ids)
+	if err != nil {
+		return err
+	}
+	for i := range nodes {
+		status := statuses[nodes[i].ID]
+		nodes[i].Congestion = status.Level
+		nodes[i].CongestionStatus = &status
+	}
+	return nil
+}
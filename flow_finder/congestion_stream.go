@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 混雑状況のリアルタイム配信
+// 更新はRedisストリームに追記（再接続時の再送用）したうえでpub/subで全インスタンスへ通知する
+const (
+	congestionStreamKey     = "congestion:events"  // 再送用のRedisストリーム
+	congestionChannel       = "congestion:updates" // インスタンス間のpub/subチャンネル
+	congestionStreamMaxLen  = 10000                // ストリームに保持するイベント数（概算）
+	congestionReplayMax     = 1000                 // 再接続時に再送する最大件数
+	congestionHeartbeat     = 15 * time.Second     // ハートビート間隔
+	congestionSubscriberBuf = 64                   // 購読者ごとのバッファ（溢れたら切断して再接続させる）
+)
+
+// 混雑状況の更新イベント
+type CongestionEvent struct {
+	ID                    string           `json:"id"` // RedisストリームのID（Last-Event-IDとして使う）
+	TouristSpotID         uint             `json:"tourist_spot_id"`
+	FieldID               *uint            `json:"field_id"` // 最寄りノードの所属フィールド
+	Name                  string           `json:"name"`
+	CurrentCount          int              `json:"current_count"`
+	MaxCapacity           int              `json:"max_capacity"`
+	Congestion            CongestionStatus `json:"congestion"`
+	RecordedLevel         *int             `json:"recorded_level,omitempty"`          // 手動記録された混雑度（0-5）
+	CapacityState         string           `json:"capacity_state,omitempty"`          // 入場状態（キャパシティポリシーが有効な場合のみ）
+	PreviousCapacityState string           `json:"previous_capacity_state,omitempty"` // 切り替え前の入場状態（source が capacity の場合のみ）
+	Source                string           `json:"source"`                            // visitors | congestion | sensor | mqtt | capacity
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+// 観光地の現在の状態からイベントを作成
+func NewCongestionEvent(db *gorm.DB, spot *TouristSpot, source string) *CongestionEvent {
+	ev := &CongestionEvent{
+		TouristSpotID: spot.ID,
+		Name:          spot.Name,
+		CurrentCount:  spot.CurrentCount,
+		MaxCapacity:   spot.MaxCapacity,
+		Congestion:    NewCongestionService(db).SpotStatus(spot),
+		Source:        source,
+		UpdatedAt:     time.Now(),
+	}
+	if spot.NodeID != nil {
+		var node Node
+		if err := db.Select("id", "field_id").First(&node, *spot.NodeID).Error; err == nil {
+			ev.FieldID = node.FieldID
+		}
+	}
+	if policy, err := GetCapacityPolicy(db, spot.ID); err == nil && policy.Enabled {
+		ev.CapacityState = policy.State
+	}
+	return ev
+}
+
+// イベントをストリームに追記し、pub/subで配信する
+func PublishCongestionEvent(ctx context.Context, client *redis.Client, ev *CongestionEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	id, err := client.XAdd(ctx, &redis.XAddArgs{
+		Stream: congestionStreamKey,
+		MaxLen: congestionStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	ev.ID = id
+
+	data, err = json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return client.Publish(ctx, congestionChannel, data).Err()
+}
+
+// 観光地の更新を配信する（失敗してもリクエスト自体は成功させる）
+func notifyCongestionChange(db *gorm.DB, client *redis.Client, spot *TouristSpot, source string, recordedLevel *int) {
+	if client == nil {
+		return
+	}
+	ev := NewCongestionEvent(db, spot, source)
+	ev.RecordedLevel = recordedLevel
+	if err := PublishCongestionEvent(context.Background(), client, ev); err != nil {
+		fmt.Printf("⚠️ 混雑状況の配信に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+	}
+}
+
+// 購読条件（両方0の場合は全観光地）
+type CongestionFilter struct {
+	TouristSpotID uint
+	FieldID       uint
+}
+
+func (f CongestionFilter) Match(ev *CongestionEvent) bool {
+	if f.TouristSpotID != 0 && ev.TouristSpotID != f.TouristSpotID {
+		return false
+	}
+	if f.FieldID != 0 && (ev.FieldID == nil || *ev.FieldID != f.FieldID) {
+		return false
+	}
+	return true
+}
+
+// 1接続分の購読
+type CongestionSubscription struct {
+	filter CongestionFilter
+	Events chan *CongestionEvent
+	Done   chan struct{} // 配信が追いつかず切断された場合に閉じる
+	once   sync.Once
+}
+
+func (s *CongestionSubscription) close() {
+	s.once.Do(func() { close(s.Done) })
+}
+
+// インスタンス内の購読者へイベントを配る
+type CongestionHub struct {
+	client *redis.Client
+	mu     sync.RWMutex
+	subs   map[*CongestionSubscription]struct{}
+}
+
+func NewCongestionHub(client *redis.Client) *CongestionHub {
+	return &CongestionHub{client: client, subs: make(map[*CongestionSubscription]struct{})}
+}
+
+// 購読を開始
+func (h *CongestionHub) Subscribe(filter CongestionFilter) *CongestionSubscription {
+	sub := &CongestionSubscription{
+		filter: filter,
+		Events: make(chan *CongestionEvent, congestionSubscriberBuf),
+		Done:   make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// 購読を終了
+func (h *CongestionHub) Unsubscribe(sub *CongestionSubscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+	sub.close()
+}
+
+// 接続中の購読者数
+func (h *CongestionHub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+func (h *CongestionHub) broadcast(ev *CongestionEvent) {
+	h.mu.RLock()
+	var slow []*CongestionSubscription
+	for sub := range h.subs {
+		if !sub.filter.Match(ev) {
+			continue
+		}
+		select {
+		case sub.Events <- ev:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	// 受信が追いつかない購読者は切断（クライアントはLast-Event-IDで再開する）
+	for _, sub := range slow {
+		h.Unsubscribe(sub)
+	}
+}
+
+// Redis pub/subを購読して配信を続ける（go-redisが再接続を行う）
+func (h *CongestionHub) Run(ctx context.Context) {
+	pubsub := h.client.Subscribe(ctx, congestionChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		var ev CongestionEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			fmt.Printf("⚠️ 混雑イベントの解析に失敗: %v\n", err)
+			continue
+		}
+		h.broadcast(&ev)
+	}
+}
+
+// 指定したIDより後のイベントをストリームから取得（reset=true の場合は取りこぼしがある）
+func ReplayCongestionEvents(ctx context.Context, client *redis.Client, lastID string, filter CongestionFilter) ([]*CongestionEvent, bool, error) {
+	if _, _, ok := parseStreamID(lastID); !ok {
+		return nil, false, nil
+	}
+
+	// 最古のイベントが指定IDより新しい場合は、トリムにより欠落している
+	reset := false
+	first, err := client.XRangeN(ctx, congestionStreamKey, "-", "+", 1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(first) > 0 && compareStreamIDs(first[0].ID, lastID) > 0 {
+		reset = true
+	}
+
+	msgs, err := client.XRangeN(ctx, congestionStreamKey, "("+lastID, "+", congestionReplayMax).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(msgs) == congestionReplayMax {
+		reset = true
+	}
+
+	var events []*CongestionEvent
+	for _, msg := range msgs {
+		data, _ := msg.Values["data"].(string)
+		var ev CongestionEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			continue
+		}
+		ev.ID = msg.ID
+		if filter.Match(&ev) {
+			events = append(events, &ev)
+		}
+	}
+	return events, reset, nil
+}
+
+// ストリームID（"ミリ秒-連番"）を分解
+func parseStreamID(id string) (uint64, uint64, bool) {
+	msStr, seqStr, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	ms, err1 := strconv.ParseUint(msStr, 10, 64)
+	seq, err2 := strconv.ParseUint(seqStr, 10, 64)
+	return ms, seq, err1 == nil && err2 == nil
+}
+
+// ストリームIDを比較（a<bなら-1、a==bなら0、a>bなら1）
+func compareStreamIDs(a, b string) int {
+	am, as, _ := parseStreamID(a)
+	bm, bs, _ := parseStreamID(b)
+	switch {
+	case am < bm || (am == bm && as < bs):
+		return -1
+	case am == bm && as == bs:
+		return 0
+	default:
+		return 1
+	}
+}
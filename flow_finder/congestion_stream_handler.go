@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+// 混雑状況のリアルタイム配信ルートを登録
+func RegisterCongestionStreamRoutes(r *gin.Engine, redisClient *redis.Client) {
+	var hub *CongestionHub
+	if redisClient != nil {
+		hub = NewCongestionHub(redisClient)
+		go hub.Run(context.Background())
+	}
+
+	// Server-Sent Events（?tourist_spot_id= または ?field_id= で絞り込み）
+	r.GET("/api/congestion/stream", congestionSSEHandler(hub, redisClient))
+
+	// WebSocket（絞り込みはSSEと同じ）
+	r.GET("/api/congestion/ws", congestionWebSocketHandler(hub, redisClient))
+}
+
+// クエリから購読条件を取得
+func parseCongestionFilter(c *gin.Context) (CongestionFilter, error) {
+	var filter CongestionFilter
+	if v := c.Query("tourist_spot_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, newLocalizedError("stream.spot_id_invalid")
+		}
+		filter.TouristSpotID = uint(id)
+	}
+	if v := c.Query("field_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, newLocalizedError("stream.field_id_invalid")
+		}
+		filter.FieldID = uint(id)
+	}
+	return filter, nil
+}
+
+// 購読を開始し、Last-Event-ID以降のイベントを再送用に取得する
+// 購読を先に開始することで、再送と新着の間の取りこぼしを防ぐ
+func openCongestionSubscription(ctx context.Context, hub *CongestionHub, redisClient *redis.Client, filter CongestionFilter, lastID string) (*CongestionSubscription, []*CongestionEvent, bool, error) {
+	sub := hub.Subscribe(filter)
+	if lastID == "" {
+		return sub, nil, false, nil
+	}
+	events, reset, err := ReplayCongestionEvents(ctx, redisClient, lastID, filter)
+	if err != nil {
+		hub.Unsubscribe(sub)
+		return nil, nil, false, err
+	}
+	return sub, events, reset, nil
+}
+
+// SSEハンドラ
+func congestionSSEHandler(hub *CongestionHub, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hub == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": T(c, "stream.unavailable")})
+			return
+		}
+		filter, err := parseCongestionFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		lastID := c.GetHeader("Last-Event-ID")
+		if lastID == "" {
+			lastID = c.Query("last_event_id")
+		}
+
+		ctx := c.Request.Context()
+		sub, replay, reset, err := openCongestionSubscription(ctx, hub, redisClient, filter, lastID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+		defer hub.Unsubscribe(sub)
+
+		// サーバー全体のWriteTimeoutで切断されないよう、書き込みごとに期限を延長する
+		rc := http.NewResponseController(c.Writer)
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		write := func(format string, args ...interface{}) bool {
+			rc.SetWriteDeadline(time.Now().Add(congestionHeartbeat * 2))
+			if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+		sendEvent := func(ev *CongestionEvent) bool {
+			data, _ := json.Marshal(ev)
+			return write("id: %s\nevent: congestion\ndata: %s\n\n", ev.ID, data)
+		}
+
+		if !write("retry: 3000\n\n") {
+			return
+		}
+		if reset {
+			// 再送できない範囲がある場合は、クライアントに最新状態の再取得を促す
+			write("event: reset\ndata: {}\n\n")
+		}
+		lastSent := lastID
+		for _, ev := range replay {
+			if !sendEvent(ev) {
+				return
+			}
+			lastSent = ev.ID
+		}
+
+		heartbeat := time.NewTicker(congestionHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.Done:
+				return
+			case <-heartbeat.C:
+				if !write(": heartbeat\n\n") {
+					return
+				}
+			case ev := <-sub.Events:
+				if lastSent != "" && compareStreamIDs(ev.ID, lastSent) <= 0 {
+					continue // 再送済み
+				}
+				if !sendEvent(ev) {
+					return
+				}
+				lastSent = ev.ID
+			}
+		}
+	}
+}
+
+var congestionUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	// CORSと同様に全オリジンを許可（読み取り専用の公開情報のため）
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WebSocketで送るメッセージ
+type congestionWSMessage struct {
+	Type  string           `json:"type"` // congestion | reset
+	Event *CongestionEvent `json:"event,omitempty"`
+}
+
+// WebSocketハンドラ（サーバーからの送信のみ。?last_event_id= で再開）
+func congestionWebSocketHandler(hub *CongestionHub, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hub == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": T(c, "stream.unavailable")})
+			return
+		}
+		filter, err := parseCongestionFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		lastID := c.Query("last_event_id")
+
+		sub, replay, reset, err := openCongestionSubscription(c.Request.Context(), hub, redisClient, filter, lastID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+		defer hub.Unsubscribe(sub)
+
+		conn, err := congestionUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// 読み取りループ（pong受信で期限延長、切断検知）
+		closed := make(chan struct{})
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(congestionHeartbeat * 2))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(congestionHeartbeat * 2))
+		})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		send := func(msg congestionWSMessage) bool {
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			return conn.WriteJSON(msg) == nil
+		}
+
+		if reset && !send(congestionWSMessage{Type: "reset"}) {
+			return
+		}
+		lastSent := lastID
+		for _, ev := range replay {
+			if !send(congestionWSMessage{Type: "congestion", Event: ev}) {
+				return
+			}
+			lastSent = ev.ID
+		}
+
+		heartbeat := time.NewTicker(congestionHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-sub.Done:
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"), time.Now().Add(time.Second))
+				return
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			case ev := <-sub.Events:
+				if lastSent != "" && compareStreamIDs(ev.ID, lastSent) <= 0 {
+					continue
+				}
+				if !send(congestionWSMessage{Type: "congestion", Event: ev}) {
+					return
+				}
+				lastSent = ev.ID
+			}
+		}
+	}
+}
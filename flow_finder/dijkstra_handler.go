@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -35,42 +37,42 @@ func dijkstraCalculationHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
 		if req.StartNodeID == req.EndNodeID {
-			c.JSON(400, gin.H{"error": "開始ノードと終了ノードは異なる必要があります"})
+			c.JSON(400, gin.H{"error": T(c, "route.same_nodes")})
 			return
 		}
 
 		// ノードの存在確認
 		var startNode, endNode Node
 		if err := db.First(&startNode, req.StartNodeID).Error; err != nil {
-			c.JSON(404, gin.H{"error": "開始ノードが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "route.start_node_not_found")})
 			return
 		}
 		if err := db.First(&endNode, req.EndNodeID).Error; err != nil {
-			c.JSON(404, gin.H{"error": "終了ノードが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "route.end_node_not_found")})
 			return
 		}
 
 		// グラフを構築
 		graph, err := BuildGraph(db)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "グラフ構築に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "route.graph_failed"), "details": err.Error()})
 			return
 		}
 
 		// Dijkstraアルゴリズムを実行
 		result, err := Dijkstra(graph, req.StartNodeID, req.EndNodeID, db)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "経路計算に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "route.failed"), "details": err.Error()})
 			return
 		}
 
 		if result == nil {
-			c.JSON(404, gin.H{"error": "経路が見つかりませんでした"})
+			c.JSON(404, gin.H{"error": T(c, "route.not_found")})
 			return
 		}
 
@@ -108,57 +110,68 @@ func dijkstraCalculationHandler(db *gorm.DB) gin.HandlerFunc {
 func touristSpotRouteHandler(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req struct {
-			StartSpotID uint `json:"start_spot_id" binding:"required"`
-			EndSpotID   uint `json:"end_spot_id" binding:"required"`
+			StartSpotID uint   `json:"start_spot_id" binding:"required"`
+			EndSpotID   uint   `json:"end_spot_id" binding:"required"`
+			DepartAt    string `json:"depart_at"` // 出発時刻（RFC3339、省略時は現在）到着時の混雑予測に使う
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
 		if req.StartSpotID == req.EndSpotID {
-			c.JSON(400, gin.H{"error": "開始観光地と終了観光地は異なる必要があります"})
+			c.JSON(400, gin.H{"error": T(c, "route.same_spots")})
 			return
 		}
 
+		departAt := time.Now()
+		if req.DepartAt != "" {
+			t, err := time.Parse(time.RFC3339, req.DepartAt)
+			if err != nil {
+				c.JSON(400, gin.H{"error": T(c, "common.rfc3339_depart_at")})
+				return
+			}
+			departAt = t
+		}
+
 		// 観光地の存在確認とノード情報取得
 		var startSpot, endSpot TouristSpot
 		if err := db.Preload("Node").First(&startSpot, req.StartSpotID).Error; err != nil {
-			c.JSON(404, gin.H{"error": "開始観光地が見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "route.start_spot_not_found")})
 			return
 		}
 		if err := db.Preload("Node").First(&endSpot, req.EndSpotID).Error; err != nil {
-			c.JSON(404, gin.H{"error": "終了観光地が見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "route.end_spot_not_found")})
 			return
 		}
 
 		// 観光地に関連付けられたノードがあるかチェック
 		if startSpot.NodeID == nil {
-			c.JSON(400, gin.H{"error": "開始観光地にノードが関連付けられていません"})
+			c.JSON(400, gin.H{"error": T(c, "route.start_spot_no_node")})
 			return
 		}
 		if endSpot.NodeID == nil {
-			c.JSON(400, gin.H{"error": "終了観光地にノードが関連付けられていません"})
+			c.JSON(400, gin.H{"error": T(c, "route.end_spot_no_node")})
 			return
 		}
 
 		// グラフを構築
 		graph, err := BuildGraph(db)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "グラフ構築に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "route.graph_failed"), "details": err.Error()})
 			return
 		}
 
 		// Dijkstraアルゴリズムを実行
 		result, err := Dijkstra(graph, *startSpot.NodeID, *endSpot.NodeID, db)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "経路計算に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "route.failed"), "details": err.Error()})
 			return
 		}
 
 		if result == nil {
-			c.JSON(404, gin.H{"error": "経路が見つかりませんでした"})
+			c.JSON(404, gin.H{"error": T(c, "route.not_found")})
 			return
 		}
 
@@ -184,16 +197,41 @@ func touristSpotRouteHandler(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(200, gin.H{
-			"result":         "ok",
-			"start_spot":     startSpot,
-			"end_spot":       endSpot,
-			"path":           pathNodes,
-			"path_steps":     result.Path,
-			"total_distance": result.TotalDistance,
-			"node_count":     len(pathNodes),
-			"estimated_time": result.TotalDistance / 5.0, // 時速5km想定での所要時間（時間）
-		})
+		estimatedTime := result.TotalDistance / 5.0 // 時速5km想定での所要時間（時間）
+
+		// 到着時刻の混雑予測（失敗しても経路は返す）
+		var arrivalForecast *CongestionForecast
+		arriveAt := departAt.Add(time.Duration(estimatedTime * float64(time.Hour)))
+		if f, err := ForecastCongestion(db, endSpot, arriveAt); err == nil {
+			arrivalForecast = f
+		} else {
+			fmt.Printf("⚠️ 到着時の混雑予測に失敗 - SpotID: %d, Error: %v\n", endSpot.ID, err)
+		}
+
+		response := gin.H{
+			"result":           "ok",
+			"start_spot":       startSpot,
+			"end_spot":         endSpot,
+			"path":             pathNodes,
+			"path_steps":       result.Path,
+			"total_distance":   result.TotalDistance,
+			"node_count":       len(pathNodes),
+			"estimated_time":   estimatedTime,
+			"arrival_forecast": arrivalForecast,
+		}
+
+		// 目的地が混雑・満員の場合は近くの代替観光地を案内する（失敗しても経路は返す）
+		if err := FillSpotCapacity(db, &endSpot, startSpot.ID); err == nil {
+			TranslateSpots(db, RequestLocale(c), endSpot.Alternatives)
+			response["end_spot"] = endSpot
+			if warningID := capacityWarningID(endSpot.Capacity); warningID != "" {
+				response["capacity_warning"] = T(c, warningID)
+			}
+		} else {
+			fmt.Printf("⚠️ 目的地の入場状況の取得に失敗 - SpotID: %d, Error: %v\n", endSpot.ID, err)
+		}
+
+		c.JSON(200, response)
 	}
 }
 
@@ -203,14 +241,14 @@ func debugGraphHandler(db *gorm.DB) gin.HandlerFunc {
 		// 全ノード取得
 		var nodes []Node
 		if err := db.Find(&nodes).Error; err != nil {
-			c.JSON(500, gin.H{"error": "ノードの取得に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "node.fetch_failed")})
 			return
 		}
 
 		// 全リンク取得
 		var links []Link
 		if err := db.Preload("FromNode").Preload("ToNode").Find(&links).Error; err != nil {
-			c.JSON(500, gin.H{"error": "リンクの取得に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "link.fetch_failed")})
 			return
 		}
 
@@ -262,18 +300,18 @@ func debugDistanceHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
 		// ノードの存在確認
 		var fromNode, toNode Node
 		if err := db.First(&fromNode, req.FromNodeID).Error; err != nil {
-			c.JSON(404, gin.H{"error": "開始ノードが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "route.start_node_not_found")})
 			return
 		}
 		if err := db.First(&toNode, req.ToNodeID).Error; err != nil {
-			c.JSON(404, gin.H{"error": "終了ノードが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "route.end_node_not_found")})
 			return
 		}
 
@@ -310,14 +348,14 @@ func availableLinksHandler(db *gorm.DB) gin.HandlerFunc {
 		nodeIDStr := c.Param("id")
 		nodeID, err := strconv.ParseUint(nodeIDStr, 10, 32)
 		if err != nil {
-			c.JSON(400, gin.H{"error": "無効なノードIDです"})
+			c.JSON(400, gin.H{"error": T(c, "node.invalid_id")})
 			return
 		}
 
 		// ノードの存在確認
 		var currentNode Node
 		if err := db.First(&currentNode, uint(nodeID)).Error; err != nil {
-			c.JSON(404, gin.H{"error": "ノードが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "node.not_found")})
 			return
 		}
 
@@ -331,7 +369,7 @@ func availableLinksHandler(db *gorm.DB) gin.HandlerFunc {
 		// 出発ノードとしてのリンクのみ取得
 		var outgoingLinks []Link
 		if err := db.Preload("ToNode").Where("from_node_id = ?", nodeID).Find(&outgoingLinks).Error; err != nil {
-			c.JSON(500, gin.H{"error": "リンクの取得に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "link.fetch_failed")})
 			return
 		}
 
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 混雑予測
+// 過去の混雑率（スナップショット）と手動記録の混雑度を1時間単位にまとめ、
+// 「曜日×時間帯」ごとの週次系列に指数平滑化をかけて将来の混雑率を推定する
+const (
+	forecastHistoryWeeks   = 8                   // 予測に使う過去の週数
+	forecastSmoothing      = 0.5                 // 指数平滑化の係数（大きいほど直近の週を重視）
+	forecastNowcastHorizon = 2 * time.Hour       // 現在の人数を予測に反映する範囲
+	forecastMaxHorizon     = 14 * 24 * time.Hour // 予測できる最大の先読み期間
+)
+
+// 予測の根拠
+const (
+	ForecastMethodSeasonal  = "seasonal"    // 同じ曜日・時間帯の履歴
+	ForecastMethodTimeOfDay = "time_of_day" // 曜日を問わず同じ時間帯の履歴
+	ForecastMethodCurrent   = "current"     // 履歴がないため現在の状態のみ
+)
+
+// 1観光地・1時刻の予測結果
+type CongestionForecast struct {
+	TouristSpotID uint            `json:"tourist_spot_id"`
+	At            time.Time       `json:"at"`
+	Ratio         float64         `json:"ratio"`            // 予測混雑率（%）
+	Level         CongestionLevel `json:"level"`            // 予測混雑度（0-5、履歴がない場合は-1）
+	Label         string          `json:"congestion_level"` // 表示名（例: 混雑）
+	Method        string          `json:"method"`
+	Samples       int             `json:"samples"` // 予測に使った週（または日）の数
+}
+
+// 1時間分の履歴
+type forecastSample struct {
+	LocalHour time.Time // 指定タイムゾーンでの時刻（時単位に切り捨て）
+	Value     float64   // 混雑率（%）
+}
+
+// 観光地ごとの予測モデル（一度作れば複数の時刻を予測できる）
+type CongestionForecastModel struct {
+	Spot     TouristSpot
+	Location *time.Location
+	Now      time.Time
+	seasonal map[[2]int][]float64 // [曜日, 時]ごとの週次系列（古い順）
+	daily    map[int][]float64    // 時ごとの日次系列（古い順）
+}
+
+// 履歴から予測モデルを作成
+func BuildCongestionForecastModel(db *gorm.DB, spot TouristSpot, loc *time.Location, now time.Time) (*CongestionForecastModel, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	since := now.Add(-forecastHistoryWeeks * 7 * 24 * time.Hour)
+
+	// スナップショット（自動記録の混雑率）
+	var snapshots []forecastSample
+	if err := db.Model(&CongestionSnapshot{}).
+		Select("date_trunc('hour', recorded_at AT TIME ZONE ?) AS local_hour, AVG(ratio) AS value", loc.String()).
+		Where("tourist_spot_id = ? AND recorded_at >= ?", spot.ID, since).
+		Group("local_hour").Scan(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	// 手動記録（混雑レベルを代表値の混雑率に換算）
+	var records []forecastSample
+	if err := db.Model(&CongestionRecord{}).
+		Select("date_trunc('hour', recorded_at AT TIME ZONE ?) AS local_hour, AVG(ratio) AS value", loc.String()).
+		Where("tourist_spot_id = ? AND recorded_at >= ?", spot.ID, since).
+		Group("local_hour").Scan(&records).Error; err != nil {
+		return nil, err
+	}
+
+	// 同じ時間帯に両方ある場合は実測値であるスナップショットを優先
+	hours := map[time.Time]float64{}
+	for _, s := range records {
+		hours[s.LocalHour] = s.Value
+	}
+	for _, s := range snapshots {
+		hours[s.LocalHour] = s.Value
+	}
+
+	return newCongestionForecastModel(spot, loc, now, hours), nil
+}
+
+func newCongestionForecastModel(spot TouristSpot, loc *time.Location, now time.Time, hours map[time.Time]float64) *CongestionForecastModel {
+	samples := make([]forecastSample, 0, len(hours))
+	for h, v := range hours {
+		samples = append(samples, forecastSample{LocalHour: h, Value: v})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].LocalHour.Before(samples[j].LocalHour) })
+
+	m := &CongestionForecastModel{
+		Spot:     spot,
+		Location: loc,
+		Now:      now,
+		seasonal: map[[2]int][]float64{},
+		daily:    map[int][]float64{},
+	}
+	// date_truncの結果はタイムゾーンなしの現地時刻なので、そのまま曜日・時を取り出す
+	for _, s := range samples {
+		key := [2]int{int(s.LocalHour.Weekday()), s.LocalHour.Hour()}
+		m.seasonal[key] = append(m.seasonal[key], s.Value)
+		m.daily[s.LocalHour.Hour()] = append(m.daily[s.LocalHour.Hour()], s.Value)
+	}
+	return m
+}
+
+// 指数平滑化（古い順の系列を受け取り、最新の平滑値を返す）
+func exponentialSmoothing(series []float64, alpha float64) float64 {
+	if len(series) == 0 {
+		return 0
+	}
+	s := series[0]
+	for _, x := range series[1:] {
+		s = alpha*x + (1-alpha)*s
+	}
+	return s
+}
+
+// 現在の混雑率（%）。許容人数が未設定の場合は不明
+func (m *CongestionForecastModel) currentRatio() (float64, bool) {
+	if m.Spot.MaxCapacity <= 0 {
+		return 0, false
+	}
+	return m.Spot.GetCongestionRatio(), true
+}
+
+// 指定時刻の混雑を予測
+func (m *CongestionForecastModel) Predict(at time.Time) *CongestionForecast {
+	local := at.In(m.Location)
+	f := &CongestionForecast{TouristSpotID: m.Spot.ID, At: local}
+
+	if series := m.seasonal[[2]int{int(local.Weekday()), local.Hour()}]; len(series) > 0 {
+		f.Ratio = exponentialSmoothing(series, forecastSmoothing)
+		f.Method = ForecastMethodSeasonal
+		f.Samples = len(series)
+	} else if series := m.daily[local.Hour()]; len(series) > 0 {
+		f.Ratio = exponentialSmoothing(series, forecastSmoothing)
+		f.Method = ForecastMethodTimeOfDay
+		f.Samples = len(series)
+	} else if current, ok := m.currentRatio(); ok {
+		f.Ratio = current
+		f.Method = ForecastMethodCurrent
+	} else {
+		f.Method = ForecastMethodCurrent
+		f.Level = CongestionUnknown
+		f.Label = f.Level.Label()
+		return f
+	}
+
+	// 直近の予測は現在の人数に寄せる（先の時刻ほど履歴を重視）
+	if lead := at.Sub(m.Now); f.Method != ForecastMethodCurrent && lead < forecastNowcastHorizon {
+		if current, ok := m.currentRatio(); ok {
+			w := 1 - math.Max(lead.Hours(), 0)/forecastNowcastHorizon.Hours()
+			f.Ratio = w*current + (1-w)*f.Ratio
+		}
+	}
+
+	f.Ratio = math.Round(f.Ratio*10) / 10
+	f.Level = CongestionLevelFromRatio(f.Ratio)
+	f.Label = f.Level.Label()
+	return f
+}
+
+// 観光地の指定時刻の混雑を予測（経路・行程の計画から呼び出す）
+func ForecastCongestion(db *gorm.DB, spot TouristSpot, at time.Time) (*CongestionForecast, error) {
+	loc, err := loadSpotLocation(spot.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	model, err := BuildCongestionForecastModel(db, spot, loc, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return model.Predict(at), nil
+}
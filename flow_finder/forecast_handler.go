@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const forecastMaxHours = 48 // ?hours= で返す最大時間数
+
+// 混雑予測関連のルートを登録
+func RegisterForecastRoutes(r *gin.Engine, db *gorm.DB) {
+	// 観光地の混雑予測
+	// ?at=RFC3339 で指定時刻（既定は1時間後）、?hours=N で次の正時からN時間分を返す
+	r.GET("/api/tourist-spots/:id/forecast", func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+
+		// 曜日・時間帯は観光地のタイムゾーンで集計する（?tz= で上書き可能）
+		loc, err := loadSpotLocation(spot.TimeZone)
+		if tz := c.Query("tz"); tz != "" {
+			loc, err = time.LoadLocation(tz)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.tz_invalid")})
+			return
+		}
+
+		now := time.Now()
+		var times []time.Time
+		if v := c.Query("hours"); v != "" {
+			hours, err := strconv.Atoi(v)
+			if err != nil || hours < 1 || hours > forecastMaxHours {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.hours_range", forecastMaxHours)})
+				return
+			}
+			start := now.Truncate(time.Hour).Add(time.Hour)
+			for i := 0; i < hours; i++ {
+				times = append(times, start.Add(time.Duration(i)*time.Hour))
+			}
+		} else {
+			at := now.Add(time.Hour)
+			if v := c.Query("at"); v != "" {
+				if at, err = time.Parse(time.RFC3339, v); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.rfc3339_at")})
+					return
+				}
+			}
+			times = append(times, at)
+		}
+		if last := times[len(times)-1]; last.Sub(now) > forecastMaxHorizon {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "forecast.too_far")})
+			return
+		}
+
+		model, err := BuildCongestionForecastModel(db, spot, loc, now)
+		if err != nil {
+			fmt.Printf("⚠️ 混雑予測モデルの作成に失敗 - SpotID: %d, Error: %v\n", spot.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "forecast.failed")})
+			return
+		}
+
+		forecasts := make([]*CongestionForecast, 0, len(times))
+		for _, t := range times {
+			forecasts = append(forecasts, model.Predict(t))
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"tourist_spot_id": spot.ID,
+			"name":            spot.Name,
+			"tz":              loc.String(),
+			"forecasts":       forecasts,
+		})
+	})
+}
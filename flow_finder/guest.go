@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 新しいゲストユーザーを作成
+func CreateGuestUser(db *gorm.DB) (*User, error) {
+	suffix, err := GenerateToken(6)
+	if err != nil {
+		return nil, err
+	}
+	user := User{Name: "guest-" + suffix, IsGuest: true}
+	if err := db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ゲストから引き継いだデータの件数
+type GuestMergeResult struct {
+	GuestUserID uint  `json:"guest_user_id"`
+	Favorites   int64 `json:"favorites"`
+	Logs        int64 `json:"logs"`
+	Tickets     int64 `json:"queue_tickets"`
+}
+
+// ゲストユーザーのデータを本アカウントへ統合し、ゲストを削除する
+// お気に入りの重複は複合ユニークインデックス（user_id, tourist_spot_id）により本アカウント側を優先する
+func MergeGuestUser(db *gorm.DB, redisClient *redis.Client, guestID, userID uint) (*GuestMergeResult, error) {
+	if guestID == userID {
+		return nil, newLocalizedError("guest.merge_same_user")
+	}
+	result := &GuestMergeResult{GuestUserID: guestID}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var guest User
+		if err := tx.Where("id = ? AND is_guest = ?", guestID, true).First(&guest).Error; err != nil {
+			return err
+		}
+
+		fav := tx.Exec(`
+			INSERT INTO user_favorite_tourist_spots
+				(user_id, tourist_spot_id, added_at, notes, priority, visit_status, visit_date, created_at, updated_at)
+			SELECT ?, tourist_spot_id, added_at, notes, priority, visit_status, visit_date, created_at, NOW()
+			FROM user_favorite_tourist_spots WHERE user_id = ?
+			ON CONFLICT (user_id, tourist_spot_id) DO NOTHING`, userID, guestID)
+		if fav.Error != nil {
+			return fav.Error
+		}
+		result.Favorites = fav.RowsAffected
+		if err := tx.Where("user_id = ?", guestID).Delete(&UserFavoriteTouristSpot{}).Error; err != nil {
+			return err
+		}
+
+		logs := tx.Model(&UserLog{}).Where("user_id = ?", guestID).Update("user_id", userID)
+		if logs.Error != nil {
+			return logs.Error
+		}
+		result.Logs = logs.RowsAffected
+
+		// 整理券は本アカウントで同じ観光地の有効な整理券を持っている場合、ゲスト側を取り消してから引き継ぐ
+		active := []string{QueueTicketWaiting, QueueTicketCalled}
+		if err := tx.Model(&QueueTicket{}).
+			Where("user_id = ? AND status IN ?", guestID, active).
+			Where("tourist_spot_id IN (?)", tx.Model(&QueueTicket{}).Select("tourist_spot_id").Where("user_id = ? AND status IN ?", userID, active)).
+			Updates(map[string]interface{}{"status": QueueTicketCancelled, "cancelled_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		tickets := tx.Model(&QueueTicket{}).Where("user_id = ?", guestID).Update("user_id", userID)
+		if tickets.Error != nil {
+			return tickets.Error
+		}
+		result.Tickets = tickets.RowsAffected
+
+		return tx.Delete(&guest).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ゲストのトークンは以後使えないようにする
+	if redisClient != nil {
+		RevokeUserSessions(context.Background(), redisClient, guestID, "")
+	}
+
+	RecordChangeHistory(db, "users", strconv.Itoa(int(guestID)), &userID, "merge", nil, result)
+	return result, nil
+}
+
+// リクエストのトークンがゲストユーザーのものであればそのIDを返す
+func guestUserIDFromContext(c *gin.Context, db *gorm.DB) (uint, bool) {
+	principal := principalFromContext(c)
+	if principal == nil || principal.IsAPIKey() || principal.UserID == 0 {
+		return 0, false
+	}
+	var user User
+	if err := db.Select("id", "is_guest").First(&user, principal.UserID).Error; err != nil || !user.IsGuest {
+		return 0, false
+	}
+	return user.ID, true
+}
+
+// ログイン・登録時にゲストのデータを統合する（失敗してもログイン自体は成功させる）
+func mergeGuestOnLogin(c *gin.Context, db *gorm.DB, redisClient *redis.Client, userID uint) *GuestMergeResult {
+	guestID, ok := guestUserIDFromContext(c, db)
+	if !ok || guestID == userID {
+		return nil
+	}
+	return mergeGuestByID(db, redisClient, guestID, userID)
+}
+
+func mergeGuestByID(db *gorm.DB, redisClient *redis.Client, guestID, userID uint) *GuestMergeResult {
+	result, err := MergeGuestUser(db, redisClient, guestID, userID)
+	if err != nil {
+		fmt.Printf("⚠️ ゲストデータの統合に失敗 - Guest: %d, User: %d, Error: %v\n", guestID, userID, err)
+		return nil
+	}
+	fmt.Printf("✅ ゲストデータを統合 - Guest: %d → User: %d (お気に入り: %d, ログ: %d)\n",
+		guestID, userID, result.Favorites, result.Logs)
+	return result
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ゲスト（未ログインの来訪者）関連のルートを登録
+func RegisterGuestRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	// ゲストユーザーを作成してトークンを発行（端末に保存して使い続ける）
+	r.POST("/api/guests", func(c *gin.Context) {
+		// 発行数はレート制限ミドルウェアの "guest_create" ポリシーで制限
+		user, err := CreateGuestUser(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.save_failed")})
+			return
+		}
+
+		tokens, err := CreateSession(context.Background(), redisClient, user, c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "auth.token_save_failed")})
+			return
+		}
+
+		sessionID := c.GetHeader("X-Session-Id")
+		if sessionID == "" {
+			sessionID = generateHandlerSessionID()
+		}
+		LogDatabaseOperation(db, &user.ID, sessionID, "create", "users", fmt.Sprintf("%d", user.ID), c)
+
+		c.JSON(http.StatusCreated, gin.H{
+			"token":           tokens.Token,
+			"token_type":      tokens.TokenType,
+			"refresh_token":   tokens.RefreshToken,
+			"expires_in":      int(tokens.ExpiresIn.Seconds()),
+			"auth_session_id": tokens.SessionID,
+			"user_id":         user.ID,
+			"session_id":      sessionID,
+			"is_admin":        false,
+			"is_guest":        true,
+		})
+	})
+}
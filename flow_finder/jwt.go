@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// サポートする署名アルゴリズム
+const (
+	JWTAlgHS256 = "HS256"
+	JWTAlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrJWTMalformed  = errors.New("malformed token")
+	ErrJWTSignature  = errors.New("invalid token signature")
+	ErrJWTExpired    = errors.New("token expired")
+	ErrJWTUnknownKey = errors.New("unknown signing key")
+)
+
+// JWTのクレーム
+type JWTClaims struct {
+	ID        string   `json:"jti"`
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	UserID    uint     `json:"uid"`
+	Roles     []string `json:"roles,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// 有効期限をtime.Timeで取得
+func (c *JWTClaims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// 署名鍵（kidで識別）
+type JWTKey struct {
+	ID         string
+	Alg        string
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// 鍵の設定文字列 "kid:alg:material" をパースする
+// HS256のmaterialは共有シークレット、EdDSAのmaterialはbase64エンコードされた32バイトのシード
+func ParseJWTKey(spec string) (*JWTKey, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("鍵の形式が不正です（kid:alg:material）: %q", spec)
+	}
+	key := &JWTKey{ID: parts[0], Alg: parts[1]}
+	switch key.Alg {
+	case JWTAlgHS256:
+		if len(parts[2]) < 32 {
+			return nil, fmt.Errorf("HS256の鍵 %s は32文字以上必要です", key.ID)
+		}
+		key.secret = []byte(parts[2])
+	case JWTAlgEdDSA:
+		seed, err := base64.StdEncoding.DecodeString(parts[2])
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("EdDSAの鍵 %s は32バイトのシードをbase64で指定してください", key.ID)
+		}
+		key.privateKey = ed25519.NewKeyFromSeed(seed)
+		key.publicKey = key.privateKey.Public().(ed25519.PublicKey)
+	default:
+		return nil, fmt.Errorf("未対応のアルゴリズムです: %s", key.Alg)
+	}
+	return key, nil
+}
+
+func (k *JWTKey) sign(data []byte) []byte {
+	if k.Alg == JWTAlgEdDSA {
+		return ed25519.Sign(k.privateKey, data)
+	}
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func (k *JWTKey) verify(data, sig []byte) bool {
+	if k.Alg == JWTAlgEdDSA {
+		return ed25519.Verify(k.publicKey, data, sig)
+	}
+	return hmac.Equal(k.sign(data), sig)
+}
+
+// 鍵セット: activeの鍵で署名し、登録された全ての鍵で検証する（鍵ローテーション用）
+type JWTKeySet struct {
+	active *JWTKey
+	keys   map[string]*JWTKey
+}
+
+// 鍵セットを作成する（activeKIDが空の場合は先頭の鍵を使用）
+func NewJWTKeySet(keys []*JWTKey, activeKID string) (*JWTKeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("JWTの署名鍵が設定されていません")
+	}
+	ks := &JWTKeySet{keys: make(map[string]*JWTKey)}
+	for _, k := range keys {
+		if _, dup := ks.keys[k.ID]; dup {
+			return nil, fmt.Errorf("鍵IDが重複しています: %s", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+	if activeKID == "" {
+		activeKID = keys[0].ID
+	}
+	active, ok := ks.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("アクティブな鍵 %s が見つかりません", activeKID)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// クレームに署名してトークン文字列を生成
+func (ks *JWTKeySet) Sign(claims *JWTClaims) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: ks.active.Alg, Typ: "JWT", Kid: ks.active.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sig := ks.active.sign([]byte(signingInput))
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// トークンの署名と有効期限を検証してクレームを返す
+func (ks *JWTKeySet) Verify(token string, now time.Time) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+	enc := base64.RawURLEncoding
+
+	headerJSON, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	key, ok := ks.keys[header.Kid]
+	if !ok {
+		return nil, ErrJWTUnknownKey
+	}
+	// alg差し替え攻撃を防ぐため、鍵に設定されたアルゴリズムと一致するか確認
+	if header.Alg != key.Alg {
+		return nil, ErrJWTSignature
+	}
+
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrJWTSignature
+	}
+
+	payload, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var claims JWTClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrJWTExpired
+	}
+	return &claims, nil
+}
+
+// JWT形式（ドット区切り3要素）の文字列かどうか
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
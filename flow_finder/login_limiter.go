@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ログイン失敗回数の制限値
+// IP単位の試行回数は rate_limit.go の "login" ポリシーで制限する
+const (
+	loginWindow          = 15 * time.Minute
+	maxLoginFailsPerName = 5 // 同一ユーザー名の失敗回数上限
+)
+
+func loginNameKey(name string) string {
+	return fmt.Sprintf("login_fail:name:%s", strings.ToLower(strings.TrimSpace(name)))
+}
+
+// 固定ウィンドウのカウンタを加算し、現在値と残りTTLを返す
+func incrWindowCounter(ctx context.Context, client *redis.Client, key string, window time.Duration) (int64, time.Duration, error) {
+	pipe := client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return incr.Val(), ttl.Val(), nil
+}
+
+// ユーザー名単位のログイン試行が許可されるかチェックする
+// 許可されない場合は再試行までの待ち時間を返す
+func CheckLoginAllowed(ctx context.Context, client *redis.Client, name string) (bool, time.Duration, error) {
+	fails, err := client.Get(ctx, loginNameKey(name)).Int64()
+	if err != nil && err != redis.Nil {
+		return false, 0, err
+	}
+	if fails >= maxLoginFailsPerName {
+		ttl, _ := client.TTL(ctx, loginNameKey(name)).Result()
+		return false, ttl, nil
+	}
+	return true, 0, nil
+}
+
+// ログイン失敗を記録する
+func RecordLoginFailure(ctx context.Context, client *redis.Client, name string) {
+	if _, _, err := incrWindowCounter(ctx, client, loginNameKey(name), loginWindow); err != nil {
+		fmt.Printf("ログイン失敗回数の記録エラー: %v\n", err)
+	}
+}
+
+// ログイン成功時にユーザー名単位の失敗回数をリセットする
+func ResetLoginFailures(ctx context.Context, client *redis.Client, name string) {
+	client.Del(ctx, loginNameKey(name))
+}
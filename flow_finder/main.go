@@ -45,7 +45,8 @@ func main() {
 	fmt.Printf("データベース接続を開始します: %s:%s\n", dbHost, dbPort)
 
 	for i := 0; i < maxRetries; i++ {
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		// TranslateError: 一意制約違反などを gorm.ErrDuplicatedKey 等に変換する（errors.Is で判定できるように）
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 		if err == nil {
 			fmt.Println("✅ データベース接続成功")
 			break
@@ -79,20 +80,76 @@ func main() {
 
 	// GORMでテーブル自動作成（外部キー制約の依存関係順序: Field → Node → TouristSpotCategory → TouristSpot → Link → Image → NodeImage → Tutorial → 独立テーブル）
   
-	if err := db.AutoMigrate(&Field{}, &User{}, &Node{}, &CategoryGroup{}, &TouristSpotCategory{}, &TouristSpot{}, &Link{}, &Image{}, &NodeImage{}, &ImagePin{}, &Tutorial{}, &UserLog{}, &UserFavoriteTouristSpot{}, &CongestionRecord{}, &ChangeHistory{}, &AppSetting{}); err != nil {
+	if err := db.AutoMigrate(&Field{}, &User{}, &Node{}, &CategoryGroup{}, &TouristSpotCategory{}, &TouristSpot{}, &Link{}, &Image{}, &NodeImage{}, &ImagePin{}, &Tutorial{}, &UserLog{}, &UserFavoriteTouristSpot{}, &CongestionRecord{}, &ChangeHistory{}, &AppSetting{}, &Role{}, &RolePermission{}, &APIKey{}, &CongestionSnapshot{}, &Webhook{}, &WebhookDelivery{}, &AlertRule{}, &AlertState{}, &SensorDevice{}, &SensorEvent{}, &SpotQueue{}, &QueueTicket{}, &SpotOpeningHours{}, &SpotScheduleException{}, &SearchDocument{}, &Review{}, &ReviewFlag{}, &Translation{}, &SpotCapacityPolicy{}, &SpotCapacityTransition{}); err != nil {
     panic(fmt.Sprintf("AutoMigrate失敗: %v", err))
 	}
 
+	// 旧形式の混雑記録を6段階の尺度に変換
+	legacyCongestionScale, err := NeedsCongestionScaleMigration(db)
+	if err != nil {
+		panic(fmt.Sprintf("混雑記録の確認失敗: %v", err))
+	}
+	if legacyCongestionScale {
+		if err := MigrateCongestionScale(db); err != nil {
+			panic(fmt.Sprintf("混雑記録の変換失敗: %v", err))
+		}
+	}
+
 	// お気に入りテーブルの複合インデックスを作成
 	if err := MigrateUserFavoriteTouristSpot(db); err != nil {
 		panic(fmt.Sprintf("UserFavoriteTouristSpot migration failed: %v", err))
 	}
 
+	// 整理券の部分ユニークインデックスを作成
+	if err := MigrateQueueTicket(db); err != nil {
+		panic(fmt.Sprintf("QueueTicket migration failed: %v", err))
+	}
+
+	// 検索用テーブルのインデックス（pg_trgm）を作成
+	if err := MigrateSearchDocument(db); err != nil {
+		panic(fmt.Sprintf("SearchDocument migration failed: %v", err))
+	}
+	// 検索インデックスはバックグラウンドで作り直す（作成中も検索は可能）
+	go func() {
+		count, err := RebuildSearchIndex(db)
+		if err != nil {
+			fmt.Printf("⚠️ 検索インデックスの作成に失敗: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ 検索インデックスを作成しました（%d件）\n", count)
+	}()
+
 	// 変更履歴テーブルのマイグレーション
 	if err := MigrateChangeHistory(db); err != nil {
 		panic(fmt.Sprintf("ChangeHistory migration failed: %v", err))
 	}
 
+	// 組み込みロール（admin/staff/editor）の作成
+	if err := SeedDefaultRoles(db); err != nil {
+		panic(fmt.Sprintf("ロールの初期化失敗: %v", err))
+	}
+
+	// 管理者のパスワード設定（パスワード未設定の既存ユーザー・初回起動用）
+	adminName := os.Getenv("ADMIN_NAME")
+	if adminName == "" {
+		adminName = "admin"
+	}
+	if err := BootstrapAdminUser(db, adminName, os.Getenv("ADMIN_PASSWORD")); err != nil {
+		panic(fmt.Sprintf("管理者ユーザーの初期化失敗: %v", err))
+	}
+
+	// 認証設定（トークン方式・JWT署名鍵）の読み込み
+	cfg, err := LoadAuthConfig()
+	if err != nil {
+		panic(fmt.Sprintf("認証設定の読み込み失敗: %v", err))
+	}
+	authConfig = cfg
+	fmt.Printf("認証トークン方式: %s\n", authConfig.Mode)
+	if authConfig.OIDC != nil {
+		oidcProvider = NewOIDCProvider(authConfig.OIDC)
+		fmt.Printf("OIDCログイン有効: %s\n", authConfig.OIDC.Issuer)
+	}
+
 	// Redis接続情報
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
@@ -105,6 +162,17 @@ func main() {
 		panic(fmt.Sprintf("Redis接続失敗: %v", err))
 	}
 
+	// 混雑率の定期記録（履歴API用）
+	StartCongestionSnapshotJob(context.Background(), db, redisClient, loadCongestionSnapshotConfig())
+
+	// 混雑アラートのWebhook配信
+	StartWebhookWorker(context.Background(), db)
+
+	// MQTTでしか送信できないカウンターの取り込み（MQTT_BROKER_URL が設定されている場合のみ）
+	if mqttConfig := loadMQTTConfig(); mqttConfig != nil {
+		StartMQTTBridge(context.Background(), db, redisClient, mqttConfig)
+	}
+
 	r := gin.Default()
 
 	// APIアクセスログミドルウェアを追加
@@ -119,6 +187,14 @@ func main() {
 	// 変更履歴関連APIを登録
 	RegisterChangeHistoryRoutes(r, db)
 
+	// 全ルートに認証ポリシーが定義されているか確認
+	if err := CheckRoutePolicies(r.Routes()); err != nil {
+		panic(err.Error())
+	}
+	if err := CheckRateLimitPolicies(); err != nil {
+		panic(err.Error())
+	}
+
 	// HTTPサーバーの設定
 	s := &http.Server{
 		Addr:           ":8080",
@@ -0,0 +1,395 @@
+package main
+
+// エラーメッセージの文言（キーはメッセージID、値はロケールごとの文言）
+// 文言を追加する場合は日本語（ja）を必ず用意し、英語（en）もできるだけ用意すること。
+// 対応言語でも文言がない場合は英語、英語もない場合は日本語を返す
+var messageCatalog = map[string]map[string]string{
+	// 共通
+	"common.invalid_request":   {"ja": "リクエストが無効です", "en": "Invalid request"},
+	"common.invalid_id":        {"ja": "無効なIDです", "en": "Invalid ID"},
+	"common.fetch_failed":      {"ja": "データの取得に失敗しました", "en": "Failed to fetch data"},
+	"common.save_failed":       {"ja": "データの保存に失敗しました", "en": "Failed to save data"},
+	"common.create_failed":     {"ja": "作成に失敗しました", "en": "Failed to create"},
+	"common.delete_failed":     {"ja": "削除に失敗しました", "en": "Failed to delete"},
+	"common.redis_error":       {"ja": "キャッシュサーバーでエラーが発生しました", "en": "Cache server error"},
+	"common.name_required":     {"ja": "nameは必須です", "en": "name is required"},
+	"common.no_updates":        {"ja": "更新するデータがありません", "en": "Nothing to update"},
+	"common.hours_range":       {"ja": "hoursは1〜%dで指定してください", "en": "hours must be between 1 and %d"},
+	"common.try_later":         {"ja": "しばらくしてから再度お試しください", "en": "Please try again later"},
+	"common.access_denied":     {"ja": "アクセスが拒否されました", "en": "Access denied"},
+	"common.too_many_requests": {"ja": "リクエストが多すぎます", "en": "Too many requests"},
+	"common.rfc3339_at":        {"ja": "atはRFC3339形式で指定してください", "en": "at must be in RFC3339 format"},
+	"common.rfc3339_depart_at": {"ja": "depart_atはRFC3339形式で指定してください", "en": "depart_at must be in RFC3339 format"},
+	"common.rfc3339_from":      {"ja": "fromはRFC3339形式で指定してください", "en": "from must be in RFC3339 format"},
+	"common.rfc3339_to":        {"ja": "toはRFC3339形式で指定してください", "en": "to must be in RFC3339 format"},
+	"common.tz_invalid":        {"ja": "tzが不正です", "en": "Invalid tz"},
+	"common.clock_format":      {"ja": "時刻はHH:MM形式で指定してください: %s", "en": "Times must be in HH:MM format: %s"},
+
+	// 認証・セッション
+	"auth.required":                {"ja": "認証が必要です", "en": "Authentication required"},
+	"auth.header_missing":          {"ja": "認証ヘッダーが見つかりません", "en": "Authorization header not found"},
+	"auth.token_invalid":           {"ja": "無効または期限切れのトークンです", "en": "Invalid or expired token"},
+	"auth.token_mismatch":          {"ja": "無効なトークンです", "en": "Invalid token"},
+	"auth.login_again":             {"ja": "再度ログインしてください", "en": "Please log in again"},
+	"auth.user_invalid":            {"ja": "無効なユーザーです", "en": "Invalid user"},
+	"auth.permission_required":     {"ja": "権限が必要です", "en": "Permission required"},
+	"auth.permission_denied":       {"ja": "この操作を実行する権限がありません", "en": "You do not have permission to perform this operation"},
+	"auth.backend_failed":          {"ja": "認証処理に失敗しました", "en": "Authentication failed"},
+	"auth.invalid_credentials":     {"ja": "ユーザー名またはパスワードが正しくありません", "en": "Invalid credentials"},
+	"auth.password_login_disabled": {"ja": "パスワードでのログインは無効です", "en": "Password login is disabled"},
+	"auth.too_many_attempts":       {"ja": "ログインの試行回数が多すぎます", "en": "Too many login attempts"},
+	"auth.token_save_failed":       {"ja": "トークンの保存に失敗しました", "en": "Failed to save token"},
+	"auth.refresh_token_invalid":   {"ja": "無効または期限切れのリフレッシュトークンです", "en": "Invalid or expired refresh token"},
+	"session.not_found":            {"ja": "セッションが見つかりません", "en": "Session not found"},
+	"session.fetch_failed":         {"ja": "セッションの取得に失敗しました", "en": "Failed to fetch sessions"},
+	"session.revoke_failed":        {"ja": "セッションの失効に失敗しました", "en": "Failed to revoke session"},
+
+	// OIDC
+	"oidc.not_configured":        {"ja": "OIDCログインは設定されていません", "en": "OIDC login is not configured"},
+	"oidc.start_failed":          {"ja": "ログインの開始に失敗しました", "en": "Failed to start login"},
+	"oidc.provider_unreachable":  {"ja": "IDプロバイダに接続できません", "en": "Cannot connect to the identity provider"},
+	"oidc.provider_auth_failed":  {"ja": "IDプロバイダでの認証に失敗しました", "en": "Authentication with the identity provider failed"},
+	"oidc.id_token_invalid":      {"ja": "IDトークンの検証に失敗しました", "en": "Failed to verify the ID token"},
+	"oidc.login_request_invalid": {"ja": "無効なログインリクエストです", "en": "Invalid login request"},
+	"oidc.login_request_expired": {"ja": "無効または期限切れのログインリクエストです", "en": "Invalid or expired login request"},
+	"oidc.user_save_failed":      {"ja": "ユーザー情報の保存に失敗しました", "en": "Failed to save user information"},
+
+	// ユーザー・パスワード
+	"user.not_found":             {"ja": "ユーザーが見つかりません", "en": "User not found"},
+	"user.invalid_id":            {"ja": "無効なユーザーIDです", "en": "Invalid user ID"},
+	"user.already_exists":        {"ja": "このユーザー名は既に使われています", "en": "User already exists"},
+	"user.guest_password":        {"ja": "ゲストユーザーはパスワードを設定できません。ユーザー登録を行ってください", "en": "Guest users cannot set a password. Please register an account"},
+	"password.current_incorrect": {"ja": "現在のパスワードが正しくありません", "en": "Current password is incorrect"},
+	"password.update_failed":     {"ja": "パスワードの更新に失敗しました", "en": "Failed to update password"},
+	"password.not_set":           {"ja": "パスワードが設定されていません。管理者にリセットを依頼してください", "en": "No password is set. Ask an administrator to reset it"},
+	"password.change_required":   {"ja": "パスワードの変更が必要です", "en": "Password change required"},
+	"password.change_first":      {"ja": "パスワードを変更してから利用してください", "en": "Change your password before continuing"},
+	"password.reset_failed":      {"ja": "パスワードのリセットに失敗しました", "en": "Failed to reset password"},
+	"password.generate_failed":   {"ja": "一時パスワードの生成に失敗しました", "en": "Failed to generate a temporary password"},
+	"password.too_short":         {"ja": "パスワードは8文字以上で指定してください", "en": "Password must be at least 8 characters"},
+	"password.too_long":          {"ja": "パスワードが長すぎます（72バイト以内）", "en": "Password is too long (72 bytes max)"},
+	"guest.merge_same_user":      {"ja": "同じユーザーは統合できません", "en": "Cannot merge a user into itself"},
+
+	// ロール・APIキー
+	"role.not_found":            {"ja": "ロールが見つかりません", "en": "Role not found"},
+	"role.fetch_failed":         {"ja": "ロールの取得に失敗しました", "en": "Failed to fetch roles"},
+	"role.create_failed":        {"ja": "ロールの作成に失敗しました", "en": "Failed to create role"},
+	"role.update_failed":        {"ja": "ロールの更新に失敗しました", "en": "Failed to update role"},
+	"role.delete_failed":        {"ja": "ロールの削除に失敗しました", "en": "Failed to delete role"},
+	"role.deleted":              {"ja": "ロールを削除しました", "en": "Role deleted"},
+	"role.assign_failed":        {"ja": "ロールの割り当てに失敗しました", "en": "Failed to assign roles"},
+	"role.name_taken":           {"ja": "同じ名前のロールが既に存在します", "en": "A role with the same name already exists"},
+	"role.unknown_permission":   {"ja": "未定義の権限が含まれています", "en": "Contains an undefined permission"},
+	"role.unknown_role":         {"ja": "存在しないロールが含まれています", "en": "Contains a role that does not exist"},
+	"role.admin_immutable":      {"ja": "adminロールの権限は変更できません", "en": "The permissions of the admin role cannot be changed"},
+	"role.system_rename":        {"ja": "組み込みロールの名前は変更できません", "en": "Built-in roles cannot be renamed"},
+	"role.system_delete":        {"ja": "組み込みロールは削除できません", "en": "Built-in roles cannot be deleted"},
+	"apikey.not_found":          {"ja": "APIキーが見つかりません", "en": "API key not found"},
+	"apikey.fetch_failed":       {"ja": "APIキーの取得に失敗しました", "en": "Failed to fetch API keys"},
+	"apikey.create_failed":      {"ja": "APIキーの作成に失敗しました", "en": "Failed to create API key"},
+	"apikey.generate_failed":    {"ja": "APIキーの生成に失敗しました", "en": "Failed to generate API key"},
+	"apikey.rotate_failed":      {"ja": "APIキーの再発行に失敗しました", "en": "Failed to rotate API key"},
+	"apikey.revoke_failed":      {"ja": "APIキーの失効に失敗しました", "en": "Failed to revoke API key"},
+	"apikey.revoked_rotate":     {"ja": "失効済みのAPIキーは再発行できません", "en": "A revoked API key cannot be rotated"},
+	"apikey.scope_required":     {"ja": "スコープを1つ以上指定してください", "en": "Specify at least one scope"},
+	"apikey.unknown_scope":      {"ja": "未定義のスコープが含まれています", "en": "Contains an undefined scope"},
+	"apikey.unknown_spot":       {"ja": "存在しない観光地が含まれています", "en": "Contains a tourist spot that does not exist"},
+	"apikey.expiry_past":        {"ja": "有効期限は未来の日時を指定してください", "en": "The expiry must be in the future"},
+	"apikey.invalid":            {"ja": "無効なAPIキーです", "en": "Invalid API key"},
+	"apikey.revoked_or_expired": {"ja": "キーが失効しているか期限切れです", "en": "The key has been revoked or has expired"},
+	"apikey.not_allowed":        {"ja": "APIキーでは利用できません", "en": "Not available with an API key"},
+	"apikey.login_as_user":      {"ja": "ユーザーとしてログインしてください", "en": "Please log in as a user"},
+	"apikey.spot_denied":        {"ja": "この観光地を操作する権限がありません", "en": "You do not have permission for this tourist spot"},
+	"apikey.spot_out_of_scope":  {"ja": "APIキーの対象外の観光地です", "en": "This tourist spot is outside the API key's scope"},
+	"apikey.scope_not_held":     {"ja": "自分が持っていない権限はスコープに指定できません: %s", "en": "You cannot grant a scope you do not hold: %s"},
+	"apikey.store_secret":       {"ja": "このキーは再表示できません。安全な場所に保管してください", "en": "This key cannot be shown again. Store it in a safe place"},
+	"apikey.already_revoked":    {"ja": "既に失効しています", "en": "Already revoked"},
+	"apikey.revoked":            {"ja": "APIキーを失効しました", "en": "API key revoked"},
+
+	// 観光地・カテゴリ・グループ
+	"spot.not_found":                 {"ja": "観光地が見つかりません", "en": "Tourist spot not found"},
+	"spot.invalid_id":                {"ja": "無効な観光地IDです", "en": "Invalid tourist spot ID"},
+	"spot.fetch_failed":              {"ja": "観光地の取得に失敗しました", "en": "Failed to fetch tourist spots"},
+	"spot.create_failed":             {"ja": "観光地作成に失敗しました", "en": "Failed to create tourist spot"},
+	"spot.update_failed":             {"ja": "観光地更新に失敗しました", "en": "Failed to update tourist spot"},
+	"spot.delete_failed":             {"ja": "観光地削除に失敗しました", "en": "Failed to delete tourist spot"},
+	"spot.nearest_nodes_failed":      {"ja": "最寄りノードの設定に失敗しました", "en": "Failed to assign nearest nodes"},
+	"spot.visitors_failed":           {"ja": "来場者数の更新に失敗しました", "en": "Failed to update visitor count"},
+	"spot.capacity_exceeded":         {"ja": "許容人数を超過します（現在: %d, 増加: %d, 最大: %d）", "en": "Exceeds capacity (current: %d, increase: %d, max: %d)"},
+	"spot.capacity_full":             {"ja": "%sは満員のため入場できません", "en": "%s is full and not accepting entry"},
+	"spot.count_below_zero":          {"ja": "現在の人数を下回ることはできません（現在: %d, 減少: %d）", "en": "Cannot go below the current count (current: %d, decrease: %d)"},
+	"spot.count_negative":            {"ja": "来場者数は0以上である必要があります", "en": "The visitor count must be 0 or more"},
+	"spot.visitor_count_invalid":     {"ja": "countは1以上である必要があります", "en": "count must be 1 or more"},
+	"spot.visitor_action_invalid":    {"ja": "無効なアクションです。'increment' または 'decrement' を指定するか、'current_count' を指定してください", "en": "Invalid action. Specify 'increment' or 'decrement', or set 'current_count'"},
+	"spot_query.category_id_invalid": {"ja": "category_idは数値（カンマ区切り）で指定してください", "en": "category_id must be numbers (comma separated)"},
+	"spot_query.number_required":     {"ja": "%sは数値で指定してください", "en": "%s must be a number"},
+	"spot_query.congestion_range":    {"ja": "%sは0から%dの混雑度で指定してください", "en": "%s must be a congestion level between 0 and %d"},
+	"spot_query.non_negative":        {"ja": "%sは0以上の数値で指定してください", "en": "%s must be a number of 0 or more"},
+	"spot_query.rating_range":        {"ja": "rating_minは0から5で指定してください", "en": "rating_min must be between 0 and 5"},
+	"spot_query.sort_invalid":        {"ja": "sortには id, name, rating, congestion, distance, entry_fee を指定してください（降順は先頭に -）", "en": "sort must be one of id, name, rating, congestion, distance, entry_fee (prefix - for descending)"},
+	"spot_query.near_node_required":  {"ja": "距離で並べ替える場合はnear_nodeを指定してください", "en": "near_node is required to sort by distance"},
+	"spot_query.unknown_field":       {"ja": "不明な項目です: %s", "en": "Unknown field: %s"},
+	"spot_query.limit_range":         {"ja": "limitは1から%dで指定してください", "en": "limit must be between 1 and %d"},
+	"spot_query.cursor_invalid":      {"ja": "cursorが無効です（sortを変えた場合は最初のページから取得してください）", "en": "Invalid cursor (start from the first page if you changed sort)"},
+	"spot_query.near_node_not_found": {"ja": "near_nodeのノードが見つかりません", "en": "The near_node node was not found"},
+	"category.not_found":             {"ja": "カテゴリが見つかりません", "en": "Category not found"},
+	"category.invalid_id":            {"ja": "無効なカテゴリーIDです", "en": "Invalid category ID"},
+	"category.fetch_failed":          {"ja": "カテゴリの取得に失敗しました", "en": "Failed to fetch categories"},
+	"category.create_failed":         {"ja": "カテゴリの作成に失敗しました", "en": "Failed to create category"},
+	"category.update_failed":         {"ja": "カテゴリの更新に失敗しました", "en": "Failed to update category"},
+	"category.delete_failed":         {"ja": "カテゴリの削除に失敗しました", "en": "Failed to delete category"},
+	"category.in_use":                {"ja": "このカテゴリを使用している観光地が存在するため削除できません", "en": "Cannot delete because tourist spots use this category"},
+	"group.not_found":                {"ja": "グループが見つかりません", "en": "Group not found"},
+
+	// 営業時間
+	"schedule.exception_not_found":     {"ja": "例外が見つかりません", "en": "Exception not found"},
+	"schedule.exception_create_failed": {"ja": "例外の登録に失敗しました", "en": "Failed to register exception"},
+	"schedule.exception_delete_failed": {"ja": "例外の削除に失敗しました", "en": "Failed to delete exception"},
+	"schedule.update_failed":           {"ja": "営業時間の更新に失敗しました", "en": "Failed to update opening hours"},
+	"schedule.open_equals_close":       {"ja": "開場時刻と閉場時刻が同じです（終日営業は 00:00〜24:00）", "en": "Opening and closing times are the same (use 00:00-24:00 for all day)"},
+	"schedule.weekday_invalid":         {"ja": "weekdayは0（日曜日）から6（土曜日）で指定してください", "en": "weekday must be 0 (Sunday) to 6 (Saturday)"},
+	"schedule.start_date_invalid":      {"ja": "start_dateはYYYY-MM-DD形式で指定してください", "en": "start_date must be in YYYY-MM-DD format"},
+	"schedule.end_date_invalid":        {"ja": "end_dateはYYYY-MM-DD形式で指定してください", "en": "end_date must be in YYYY-MM-DD format"},
+	"schedule.end_before_start":        {"ja": "end_dateはstart_date以降を指定してください", "en": "end_date must be on or after start_date"},
+	"schedule.unknown_time_zone":       {"ja": "不明なタイムゾーンです: %s", "en": "Unknown time zone: %s"},
+
+	// 混雑度・履歴・予測・配信
+	"congestion.level_range":   {"ja": "混雑度は0から%dの範囲で指定してください", "en": "Congestion level must be between 0 and %d"},
+	"congestion.record_failed": {"ja": "混雑記録の保存に失敗しました", "en": "Failed to save congestion record"},
+	"congestion.fetch_failed":  {"ja": "混雑状況の取得に失敗しました", "en": "Failed to fetch congestion status"},
+	"history.fetch_failed":     {"ja": "混雑履歴の取得に失敗しました", "en": "Failed to fetch congestion history"},
+	"history.interval_invalid": {"ja": "intervalは5m・1h・1dのいずれかを指定してください", "en": "interval must be one of 5m, 1h or 1d"},
+	"history.source_invalid":   {"ja": "sourceはsnapshot・record・sensorのいずれかを指定してください", "en": "source must be one of snapshot, record or sensor"},
+	"history.range_reversed":   {"ja": "fromはtoより前の日時を指定してください", "en": "from must be earlier than to"},
+	"history.range_too_long":   {"ja": "期間が長すぎます（最大%d区間）", "en": "The period is too long (max %d buckets)"},
+	"forecast.failed":          {"ja": "混雑予測に失敗しました", "en": "Failed to forecast congestion"},
+	"forecast.too_far":         {"ja": "予測できるのは14日後までです", "en": "Forecasts are available up to 14 days ahead"},
+	"stream.unavailable":       {"ja": "リアルタイム配信は利用できません", "en": "Real-time updates are not available"},
+	"stream.spot_id_invalid":   {"ja": "tourist_spot_idが不正です", "en": "Invalid tourist_spot_id"},
+	"stream.field_id_invalid":  {"ja": "field_idが不正です", "en": "Invalid field_id"},
+
+	// アラート・Webhook
+	"alert.not_found":               {"ja": "アラートルールが見つかりません", "en": "Alert rule not found"},
+	"alert.fetch_failed":            {"ja": "アラートルールの取得に失敗しました", "en": "Failed to fetch alert rules"},
+	"alert.create_failed":           {"ja": "アラートルールの作成に失敗しました", "en": "Failed to create alert rule"},
+	"alert.update_failed":           {"ja": "アラートルールの更新に失敗しました", "en": "Failed to update alert rule"},
+	"alert.delete_failed":           {"ja": "アラートルールの削除に失敗しました", "en": "Failed to delete alert rule"},
+	"alert.state_fetch_failed":      {"ja": "発報状態の取得に失敗しました", "en": "Failed to fetch alert states"},
+	"alert.target_conflict":         {"ja": "tourist_spot_idとcategory_idはどちらか一方を指定してください", "en": "Specify either tourist_spot_id or category_id, not both"},
+	"alert.threshold_level_invalid": {"ja": "threshold_levelが不正です", "en": "Invalid threshold_level"},
+	"alert.webhook_required":        {"ja": "webhook_idは必須です", "en": "webhook_id is required"},
+	"alert.threshold_invalid":       {"ja": "閾値は0より大きい混雑率（%%）を指定してください", "en": "The threshold must be an occupancy rate (%%) greater than 0"},
+	"alert.hysteresis_invalid":      {"ja": "hysteresisは0以上かつ閾値未満で指定してください", "en": "hysteresis must be 0 or more and less than the threshold"},
+	"alert.quiet_hours_incomplete":  {"ja": "quiet_startとquiet_endは両方指定してください", "en": "Specify both quiet_start and quiet_end"},
+	"webhook.not_found":             {"ja": "Webhookが見つかりません", "en": "Webhook not found"},
+	"webhook.fetch_failed":          {"ja": "Webhookの取得に失敗しました", "en": "Failed to fetch webhooks"},
+	"webhook.create_failed":         {"ja": "Webhookの作成に失敗しました", "en": "Failed to create webhook"},
+	"webhook.update_failed":         {"ja": "Webhookの更新に失敗しました", "en": "Failed to update webhook"},
+	"webhook.delete_failed":         {"ja": "Webhookの削除に失敗しました", "en": "Failed to delete webhook"},
+	"webhook.in_use":                {"ja": "このWebhookを使用しているアラートルールまたはキャパシティポリシーがあります", "en": "Alert rules or capacity policies are using this webhook"},
+	"webhook.secret_failed":         {"ja": "シークレットの生成に失敗しました", "en": "Failed to generate secret"},
+	"webhook.disabled":              {"ja": "無効化されたWebhookには送信できません", "en": "Cannot send to a disabled webhook"},
+	"webhook.url_invalid":           {"ja": "urlはhttpまたはhttpsの絶対URLを指定してください", "en": "url must be an absolute http or https URL"},
+	"delivery.not_found":            {"ja": "配信記録が見つかりません", "en": "Delivery not found"},
+	"delivery.fetch_failed":         {"ja": "配信記録の取得に失敗しました", "en": "Failed to fetch deliveries"},
+	"delivery.create_failed":        {"ja": "配信の登録に失敗しました", "en": "Failed to register delivery"},
+	"delivery.retry_failed":         {"ja": "再送の登録に失敗しました", "en": "Failed to register retry"},
+	"delivery.in_progress":          {"ja": "送信中の配信です", "en": "The delivery is in progress"},
+
+	// センサー
+	"sensor.not_found":           {"ja": "センサーが見つかりません", "en": "Sensor not found"},
+	"sensor.fetch_failed":        {"ja": "センサーの取得に失敗しました", "en": "Failed to fetch sensors"},
+	"sensor.create_failed":       {"ja": "センサーの登録に失敗しました", "en": "Failed to register sensor"},
+	"sensor.update_failed":       {"ja": "センサーの更新に失敗しました", "en": "Failed to update sensor"},
+	"sensor.delete_failed":       {"ja": "センサーの削除に失敗しました", "en": "Failed to delete sensor"},
+	"sensor.device_id_taken":     {"ja": "このdevice_idは既に登録されています", "en": "This device_id is already registered"},
+	"sensor.device_id_required":  {"ja": "device_idを指定してください", "en": "Specify device_id"},
+	"sensor.events_required":     {"ja": "eventsを1件以上指定してください", "en": "Specify at least one event"},
+	"sensor.batch_too_large":     {"ja": "1回に送信できるイベントは%d件までです", "en": "Up to %d events can be sent at once"},
+	"sensor.ingest_failed":       {"ja": "イベントの取り込みに失敗しました", "en": "Failed to ingest events"},
+	"sensor.events_fetch_failed": {"ja": "イベントの取得に失敗しました", "en": "Failed to fetch events"},
+
+	// 整理券
+	"queue.ticket_not_found":          {"ja": "整理券が見つかりません", "en": "Ticket not found"},
+	"queue.ticket_fetch_failed":       {"ja": "整理券の取得に失敗しました", "en": "Failed to fetch tickets"},
+	"queue.summary_failed":            {"ja": "整理券の状況の取得に失敗しました", "en": "Failed to fetch queue status"},
+	"queue.settings_fetch_failed":     {"ja": "整理券の設定の取得に失敗しました", "en": "Failed to fetch queue settings"},
+	"queue.settings_update_failed":    {"ja": "整理券の設定の更新に失敗しました", "en": "Failed to update queue settings"},
+	"queue.issue_failed":              {"ja": "整理券の発行に失敗しました", "en": "Failed to issue ticket"},
+	"queue.call_failed":               {"ja": "整理券の呼び出しに失敗しました", "en": "Failed to call tickets"},
+	"queue.check_in_failed":           {"ja": "チェックインに失敗しました", "en": "Failed to check in"},
+	"queue.cancel_failed":             {"ja": "整理券の取り消しに失敗しました", "en": "Failed to cancel ticket"},
+	"queue.ticket_or_number_required": {"ja": "ticket_idまたはnumberを指定してください", "en": "Specify ticket_id or number"},
+	"queue.not_issuing":               {"ja": "この観光地は整理券を発行していません", "en": "This tourist spot does not issue tickets"},
+	"queue.closed":                    {"ja": "営業時間外のため整理券を発行できません", "en": "Tickets cannot be issued outside opening hours"},
+	"queue.party_too_large":           {"ja": "1枚の整理券で入場できるのは%d人までです", "en": "Up to %d people can enter with one ticket"},
+	"queue.already_taken":             {"ja": "この観光地の整理券は既に取得済みです", "en": "You already have a ticket for this tourist spot"},
+	"queue.not_cancellable":           {"ja": "この整理券は取り消せません", "en": "This ticket cannot be cancelled"},
+	"queue.not_called":                {"ja": "まだ呼び出されていません", "en": "The ticket has not been called yet"},
+	"queue.cannot_check_in":           {"ja": "この整理券ではチェックインできません", "en": "This ticket cannot be checked in"},
+	"queue.too_early":                 {"ja": "指定の時間帯になってからチェックインしてください", "en": "Please check in during your return window"},
+	"queue.too_late":                  {"ja": "指定の時間帯を過ぎたためチェックインできません", "en": "The return window has passed"},
+	"queue.throughput_invalid":        {"ja": "throughput_per_hourとbatch_sizeは0以上で指定してください", "en": "throughput_per_hour and batch_size must be 0 or more"},
+	"queue.minutes_invalid":           {"ja": "avg_visit_minutesとwindow_minutesは1440分以下で指定してください", "en": "avg_visit_minutes and window_minutes must be 1440 or less"},
+	"queue.party_size_invalid":        {"ja": "max_party_sizeは100以下で指定してください", "en": "max_party_size must be 100 or less"},
+
+	// 入場制限
+	"capacity.busy_warning":             {"ja": "混雑しています。近くの観光地もご検討ください", "en": "This spot is busy. Please consider nearby spots"},
+	"capacity.full_warning":             {"ja": "満員のため入場を制限しています。近くの観光地をご検討ください", "en": "Entry is restricted because this spot is full. Please consider nearby spots"},
+	"capacity.fetch_failed":             {"ja": "入場状況の取得に失敗しました", "en": "Failed to fetch capacity status"},
+	"capacity.update_failed":            {"ja": "キャパシティポリシーの更新に失敗しました", "en": "Failed to update capacity policy"},
+	"capacity.transitions_fetch_failed": {"ja": "入場状態の遷移記録の取得に失敗しました", "en": "Failed to fetch capacity transitions"},
+	"capacity.alternatives_failed":      {"ja": "代替観光地の取得に失敗しました", "en": "Failed to fetch alternative spots"},
+	"capacity.hard_limit_invalid":       {"ja": "hard_limit_ratioは100以下で指定してください", "en": "hard_limit_ratio must be 100 or less"},
+	"capacity.soft_limit_invalid":       {"ja": "soft_limit_ratioはhard_limit_ratio以下で指定してください", "en": "soft_limit_ratio must not exceed hard_limit_ratio"},
+	"capacity.reopen_invalid":           {"ja": "reopen_ratioはhard_limit_ratio未満で指定してください", "en": "reopen_ratio must be less than hard_limit_ratio"},
+
+	// 検索・レビュー
+	"search.failed":                {"ja": "検索に失敗しました", "en": "Search failed"},
+	"search.reindex_failed":        {"ja": "検索インデックスの再作成に失敗しました", "en": "Failed to rebuild the search index"},
+	"search.query_required":        {"ja": "検索語（q）を指定してください", "en": "Specify a search term (q)"},
+	"search.types_invalid":         {"ja": "typesには spot, node, category, group を指定してください", "en": "types must be spot, node, category or group"},
+	"review.not_found":             {"ja": "レビューが見つかりません", "en": "Review not found"},
+	"review.or_spot_not_found":     {"ja": "レビューまたは観光地が見つかりません", "en": "Review or tourist spot not found"},
+	"review.invalid_id":            {"ja": "無効なレビューIDです", "en": "Invalid review ID"},
+	"review.fetch_failed":          {"ja": "レビューの取得に失敗しました", "en": "Failed to fetch reviews"},
+	"review.registration_required": {"ja": "レビューを投稿するにはユーザー登録が必要です", "en": "Please register an account to post reviews"},
+	"review.sort_invalid":          {"ja": "sortには newest, rating_high, rating_low を指定してください", "en": "sort must be newest, rating_high or rating_low"},
+	"review.status_invalid":        {"ja": "statusには pending, hidden, published を指定してください", "en": "status must be pending, hidden or published"},
+	"review.action_invalid":        {"ja": "actionには publish または hide を指定してください", "en": "action must be publish or hide"},
+	"review.flags_fetch_failed":    {"ja": "通報の取得に失敗しました", "en": "Failed to fetch flags"},
+	"review.summary_failed":        {"ja": "評価の集計に失敗しました", "en": "Failed to aggregate ratings"},
+	"review.recalculate_failed":    {"ja": "評価の再計算に失敗しました", "en": "Failed to recalculate ratings"},
+	"review.create_failed":         {"ja": "レビューの投稿に失敗しました", "en": "Failed to post review"},
+	"review.update_failed":         {"ja": "レビューの更新に失敗しました", "en": "Failed to update review"},
+	"review.delete_failed":         {"ja": "レビューの削除に失敗しました", "en": "Failed to delete review"},
+	"review.flag_failed":           {"ja": "レビューの通報に失敗しました", "en": "Failed to flag review"},
+	"review.rating_invalid":        {"ja": "評価は1から5で指定してください", "en": "Rating must be between 1 and 5"},
+	"review.text_too_long":         {"ja": "本文は%d文字以内にしてください", "en": "Text must be %d characters or fewer"},
+	"review.image_not_found":       {"ja": "指定された画像が見つかりません", "en": "The specified image was not found"},
+	"review.already_posted":        {"ja": "この観光地のレビューは投稿済みです（編集してください）", "en": "You have already reviewed this tourist spot (edit it instead)"},
+	"review.cannot_flag_own":       {"ja": "自分のレビューは通報できません", "en": "You cannot flag your own review"},
+	"review.already_flagged":       {"ja": "このレビューは通報済みです", "en": "You have already flagged this review"},
+	"review.status_unknown":        {"ja": "無効な状態です", "en": "Invalid status"},
+
+	// お気に入り
+	"favorite.fetch_failed":     {"ja": "お気に入り観光地の取得に失敗しました", "en": "Failed to fetch favorites"},
+	"favorite.add_failed":       {"ja": "お気に入りの追加に失敗しました", "en": "Failed to add favorite"},
+	"favorite.remove_failed":    {"ja": "お気に入りの削除に失敗しました", "en": "Failed to remove favorite"},
+	"favorite.update_failed":    {"ja": "お気に入りの更新に失敗しました", "en": "Failed to update favorite"},
+	"favorite.check_failed":     {"ja": "お気に入り状態の確認に失敗しました", "en": "Failed to check favorite status"},
+	"favorite.already_added":    {"ja": "この観光地は既にお気に入りに追加されています", "en": "This tourist spot is already in your favorites"},
+	"favorite.priority_invalid": {"ja": "無効な優先度です", "en": "Invalid priority"},
+
+	// マップ（フィールド・ノード・リンク・経路）
+	"field.not_found":            {"ja": "フィールドが見つかりません", "en": "Field not found"},
+	"field.active_not_found":     {"ja": "アクティブなフィールドが見つかりません", "en": "No active field found"},
+	"field.fetch_failed":         {"ja": "フィールド取得に失敗しました", "en": "Failed to fetch field"},
+	"field.update_failed":        {"ja": "フィールド更新に失敗しました", "en": "Failed to update field"},
+	"field.delete_failed":        {"ja": "フィールド削除に失敗しました", "en": "Failed to delete field"},
+	"field.activate_failed":      {"ja": "フィールドのアクティブ化に失敗しました", "en": "Failed to activate field"},
+	"field.name_required":        {"ja": "フィールド名は必須です", "en": "Field name is required"},
+	"node.not_found":             {"ja": "ノードが見つかりません", "en": "Node not found"},
+	"node.invalid_id":            {"ja": "無効なノードIDです", "en": "Invalid node ID"},
+	"node.fetch_failed":          {"ja": "ノードの取得に失敗しました", "en": "Failed to fetch nodes"},
+	"node.update_failed":         {"ja": "ノード更新に失敗しました", "en": "Failed to update node"},
+	"node.delete_failed":         {"ja": "ノード削除に失敗しました", "en": "Failed to delete node"},
+	"node.linked":                {"ja": "このノードは他のノードとリンクされているため削除できません", "en": "Cannot delete a node that is linked to other nodes"},
+	"node.delete_links_first":    {"ja": "先に関連するリンクを削除してください", "en": "Delete the connected links first"},
+	"node.link_check_failed":     {"ja": "リンクのチェックに失敗しました", "en": "Failed to check links"},
+	"link.not_found":             {"ja": "リンクが見つかりません", "en": "Link not found"},
+	"link.fetch_failed":          {"ja": "リンクの取得に失敗しました", "en": "Failed to fetch links"},
+	"link.update_failed":         {"ja": "リンク更新に失敗しました", "en": "Failed to update link"},
+	"link.delete_failed":         {"ja": "リンク削除に失敗しました", "en": "Failed to delete link"},
+	"link.from_not_found":        {"ja": "指定された出発ノードが存在しません", "en": "The specified origin node does not exist"},
+	"link.to_not_found":          {"ja": "指定された到着ノードが存在しません", "en": "The specified destination node does not exist"},
+	"link.distance_invalid":      {"ja": "距離は正の値である必要があります", "en": "Distance must be positive"},
+	"link.weight_invalid":        {"ja": "重みは正の値である必要があります", "en": "Weight must be positive"},
+	"route.start_node_not_found": {"ja": "開始ノードが見つかりません", "en": "Start node not found"},
+	"route.end_node_not_found":   {"ja": "終了ノードが見つかりません", "en": "End node not found"},
+	"route.same_nodes":           {"ja": "開始ノードと終了ノードは異なる必要があります", "en": "Start and end nodes must differ"},
+	"route.start_spot_not_found": {"ja": "開始観光地が見つかりません", "en": "Start tourist spot not found"},
+	"route.end_spot_not_found":   {"ja": "終了観光地が見つかりません", "en": "End tourist spot not found"},
+	"route.same_spots":           {"ja": "開始観光地と終了観光地は異なる必要があります", "en": "Start and end tourist spots must differ"},
+	"route.start_spot_no_node":   {"ja": "開始観光地にノードが関連付けられていません", "en": "The start tourist spot has no node"},
+	"route.end_spot_no_node":     {"ja": "終了観光地にノードが関連付けられていません", "en": "The end tourist spot has no node"},
+	"route.graph_failed":         {"ja": "グラフ構築に失敗しました", "en": "Failed to build the graph"},
+	"route.failed":               {"ja": "経路計算に失敗しました", "en": "Failed to calculate the route"},
+	"route.not_found":            {"ja": "経路が見つかりませんでした", "en": "No route found"},
+
+	// 画像・ピン・アップロード・チュートリアル
+	"image.not_found":               {"ja": "画像が見つかりません", "en": "Image not found"},
+	"image.invalid_id":              {"ja": "無効な画像IDです", "en": "Invalid image ID"},
+	"image.fetch_failed":            {"ja": "画像の取得に失敗しました", "en": "Failed to fetch images"},
+	"image.save_failed":             {"ja": "画像情報の保存に失敗しました", "en": "Failed to save image information"},
+	"image.delete_failed":           {"ja": "画像の削除に失敗しました", "en": "Failed to delete image"},
+	"image.file_required":           {"ja": "画像ファイルが必要です", "en": "An image file is required"},
+	"image.file_not_found":          {"ja": "画像ファイルが見つかりません", "en": "Image file not found"},
+	"node_image.not_found":          {"ja": "NodeImageが見つかりません", "en": "Node image not found"},
+	"node_image.invalid_id":         {"ja": "無効なNodeImage IDです", "en": "Invalid node image ID"},
+	"pin.not_found":                 {"ja": "ピンが見つかりません", "en": "Pin not found"},
+	"pin.fetch_failed":              {"ja": "ピンの取得に失敗しました", "en": "Failed to fetch pins"},
+	"pin.create_failed":             {"ja": "ピンの作成に失敗しました", "en": "Failed to create pin"},
+	"pin.update_failed":             {"ja": "ピンの更新に失敗しました", "en": "Failed to update pin"},
+	"upload.file_missing":           {"ja": "ファイルが選択されていません", "en": "No file selected"},
+	"upload.too_large":              {"ja": "ファイルサイズが大きすぎます（10MB以下にしてください）", "en": "The file is too large (10MB max)"},
+	"upload.unsupported_type":       {"ja": "サポートされていないファイル形式です", "en": "Unsupported file type"},
+	"upload.unsupported_field_type": {"ja": "サポートされていないファイル形式です（jpg, png, gif のみ）", "en": "Unsupported file type (jpg, png and gif only)"},
+	"upload.dir_failed":             {"ja": "アップロードディレクトリの作成に失敗しました", "en": "Failed to create the upload directory"},
+	"upload.read_failed":            {"ja": "ファイルの読み取りに失敗しました", "en": "Failed to read the file"},
+	"upload.hash_failed":            {"ja": "ファイルハッシュの計算に失敗しました", "en": "Failed to hash the file"},
+	"upload.save_failed":            {"ja": "ファイルの保存に失敗しました", "en": "Failed to save the file"},
+	"upload.copy_failed":            {"ja": "ファイルのコピーに失敗しました", "en": "Failed to copy the file"},
+	"upload.form_failed":            {"ja": "フォームの解析に失敗しました", "en": "Failed to parse the form"},
+	"tutorial.not_found":            {"ja": "チュートリアルが見つかりません", "en": "Tutorial not found"},
+	"tutorial.fetch_failed":         {"ja": "チュートリアルの取得に失敗しました", "en": "Failed to fetch tutorials"},
+	"tutorial.save_failed":          {"ja": "チュートリアルの保存に失敗しました", "en": "Failed to save tutorial"},
+	"tutorial.update_failed":        {"ja": "チュートリアルの更新に失敗しました", "en": "Failed to update tutorial"},
+	"tutorial.delete_failed":        {"ja": "チュートリアルの削除に失敗しました", "en": "Failed to delete tutorial"},
+	"tutorial.order_failed":         {"ja": "表示順の更新に失敗しました", "en": "Failed to update display order"},
+
+	// 設定・ログ
+	"setting.not_found":           {"ja": "設定が見つかりません", "en": "Setting not found"},
+	"setting.create_failed":       {"ja": "設定の作成に失敗しました", "en": "Failed to create setting"},
+	"setting.update_failed":       {"ja": "設定の更新に失敗しました", "en": "Failed to update setting"},
+	"setting.value_required":      {"ja": "valueは必須です", "en": "value is required"},
+	"log.fetch_failed":            {"ja": "ログの取得に失敗しました", "en": "Failed to fetch logs"},
+	"log.save_failed":             {"ja": "ログの保存に失敗しました", "en": "Failed to save log"},
+	"log.target_required":         {"ja": "user_idまたはsession_idを指定してください", "en": "user_id or session_id is required"},
+	"history.change_fetch_failed": {"ja": "変更履歴の取得に失敗しました", "en": "Failed to fetch change history"},
+
+	// 翻訳
+	"translation.not_found":           {"ja": "翻訳が見つかりません", "en": "Translation not found"},
+	"translation.fetch_failed":        {"ja": "翻訳の取得に失敗しました", "en": "Failed to fetch translations"},
+	"translation.save_failed":         {"ja": "翻訳の保存に失敗しました", "en": "Failed to save translations"},
+	"translation.delete_failed":       {"ja": "翻訳の削除に失敗しました", "en": "Failed to delete translation"},
+	"translation.entity_type_invalid": {"ja": "entity_typeには tourist_spot, category, category_group, tutorial, node を指定してください", "en": "entity_type must be tourist_spot, category, category_group, tutorial or node"},
+	"translation.field_invalid":       {"ja": "翻訳できない項目です", "en": "This field cannot be translated"},
+	"translation.locale_invalid":      {"ja": "localeには en, zh-Hans, zh-Hant, ko を指定してください", "en": "locale must be en, zh-Hans, zh-Hant or ko"},
+	"translation.entity_not_found":    {"ja": "翻訳対象が見つかりません", "en": "The entity to translate was not found"},
+
+	// 観光地の一括取り込み
+	"spot_import.failed":              {"ja": "観光地の取り込みに失敗しました", "en": "Failed to import tourist spots"},
+	"spot_import.export_failed":       {"ja": "観光地の書き出しに失敗しました", "en": "Failed to export tourist spots"},
+	"spot_import.format_invalid":      {"ja": "ファイル形式は csv または xlsx を指定してください", "en": "The file format must be csv or xlsx"},
+	"spot_import.parse_failed":        {"ja": "ファイルを読み込めませんでした", "en": "The file could not be parsed"},
+	"spot_import.empty":               {"ja": "取り込む行がありません", "en": "There are no rows to import"},
+	"spot_import.too_many_rows":       {"ja": "一度に取り込めるのは%d行までです", "en": "Up to %d rows can be imported at once"},
+	"spot_import.unknown_column":      {"ja": "未定義の列です: %s", "en": "Unknown column: %s"},
+	"spot_import.duplicate_column":    {"ja": "列が重複しています: %s", "en": "Duplicate column: %s"},
+	"spot_import.key_column_required": {"ja": "external_key列は必須です", "en": "The external_key column is required"},
+	"spot_import.key_required":        {"ja": "external_keyを入力してください", "en": "Enter an external_key"},
+	"spot_import.key_duplicate":       {"ja": "同じexternal_keyが%d行目にあります", "en": "The same external_key is on row %d"},
+	"spot_import.spot_duplicate":      {"ja": "同じ観光地が%d行目にあります", "en": "The same tourist spot is on row %d"},
+	"spot_import.id_not_found":        {"ja": "idの観光地が見つかりません", "en": "No tourist spot has this id"},
+	"spot_import.id_key_conflict":     {"ja": "idの観光地には別のexternal_keyが設定されています", "en": "The tourist spot with this id has a different external_key"},
+	"spot_import.number_invalid":      {"ja": "数値を入力してください", "en": "Enter a number"},
+	"spot_import.negative":            {"ja": "0以上の値を入力してください", "en": "Enter a value of 0 or more"},
+	"spot_import.bool_invalid":        {"ja": "true または false を入力してください", "en": "Enter true or false"},
+	"spot_import.name_required":       {"ja": "nameは必須です", "en": "name is required"},
+	"spot_import.capacity_invalid":    {"ja": "max_capacityは1以上で指定してください", "en": "max_capacity must be 1 or more"},
+	"spot_import.category_not_found":  {"ja": "カテゴリが見つかりません: %s", "en": "Category not found: %s"},
+	"spot_import.node_not_found":      {"ja": "ノードが見つかりません: %s", "en": "Node not found: %s"},
+	"spot_import.node_ambiguous":      {"ja": "同じ名前のノードが複数あります: %s", "en": "More than one node has this name: %s"},
+	"spot_import.has_errors":          {"ja": "エラーのある行があるため取り込みませんでした", "en": "Nothing was imported because some rows have errors"},
+}
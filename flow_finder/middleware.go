@@ -1,13 +1,8 @@
 package main
 
 import (
-	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 )
 
 // セッションIDを生成する関数
@@ -21,29 +16,3 @@ func generateSessionIDForHandler() string {
 func generateHandlerSessionID() string {
 	return generateSessionIDForHandler()
 }
-
-// 認証ミドルウェア: AuthorizationヘッダーのトークンをRedisで検証
-func AuthMiddleware(redisClient *redis.Client) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userID := c.GetHeader("X-User-Id")
-		token := c.GetHeader("Authorization")
-
-		if userID == "" || token == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "認証ヘッダーが見つかりません"})
-			return
-		}
-		// トークンベースでRedisキーを生成（実際の保存形式と一致）
-		key := "auth_token:" + token
-		val, err := redisClient.Get(context.Background(), key).Result()
-		if err == redis.Nil || val != userID {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-			return
-		} else if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "redis error"})
-			return
-		}
-		// ユーザーIDをコンテキストに設定
-		c.Set("userID", userID)
-		c.Next()
-	}
-}
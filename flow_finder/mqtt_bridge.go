@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// MQTTブリッジ
+// MQTTでしか送信できないカウンター向けに、ブローカーのトピックを購読して
+// 来場者数の更新（APIと同じ ApplyVisitorUpdate）またはセンサーイベントの取り込み（IngestSensorBatch）に渡す。
+// MQTT_BROKER_URL が設定されている場合のみ有効
+const (
+	defaultMQTTTopics      = "flowfinder/spots/+/visitors,flowfinder/sensors/+/events"
+	defaultMQTTSharedGroup = "flowfinder" // 複数インスタンスで同じメッセージを重複して処理しないための共有購読グループ
+	defaultMQTTQoS         = 1
+	mqttInitialBackoff     = time.Second
+	defaultMQTTMaxBackoff  = time.Minute
+	mqttConnectTimeout     = 10 * time.Second
+	mqttKeepAlive          = 30 * time.Second
+)
+
+// MQTTブリッジの設定
+type MQTTConfig struct {
+	BrokerURL   string // 例: tcp://mosquitto:1883
+	ClientID    string
+	Username    string
+	Password    string
+	Topics      []string
+	SharedGroup string // 空の場合は共有購読を使わない
+	QoS         byte
+	MaxBackoff  time.Duration // 再接続間隔の上限
+}
+
+// 環境変数から設定を読み込む（MQTT_BROKER_URL が空の場合は nil）
+func loadMQTTConfig() *MQTTConfig {
+	broker := os.Getenv("MQTT_BROKER_URL")
+	if broker == "" {
+		return nil
+	}
+	cfg := &MQTTConfig{
+		BrokerURL:   broker,
+		ClientID:    os.Getenv("MQTT_CLIENT_ID"),
+		Username:    os.Getenv("MQTT_USERNAME"),
+		Password:    os.Getenv("MQTT_PASSWORD"),
+		Topics:      splitList(defaultMQTTTopics),
+		SharedGroup: defaultMQTTSharedGroup,
+		QoS:         defaultMQTTQoS,
+		MaxBackoff:  defaultMQTTMaxBackoff,
+	}
+	if cfg.ClientID == "" {
+		host, _ := os.Hostname()
+		cfg.ClientID = "flowfinder-" + host
+	}
+	if v := os.Getenv("MQTT_TOPICS"); v != "" {
+		cfg.Topics = splitList(v)
+	}
+	if v, ok := os.LookupEnv("MQTT_SHARED_GROUP"); ok {
+		cfg.SharedGroup = v
+	}
+	if v := os.Getenv("MQTT_QOS"); v != "" {
+		if q, err := strconv.Atoi(v); err == nil && q >= 0 && q <= 2 {
+			cfg.QoS = byte(q)
+		} else {
+			fmt.Printf("⚠️ MQTT_QOSが不正です: %s\n", v)
+		}
+	}
+	if v := os.Getenv("MQTT_MAX_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= mqttInitialBackoff {
+			cfg.MaxBackoff = d
+		} else {
+			fmt.Printf("⚠️ MQTT_MAX_BACKOFFが不正です: %s\n", v)
+		}
+	}
+	return cfg
+}
+
+// 購読するトピック（共有購読の場合は $share/<グループ>/<トピック>）
+func (cfg *MQTTConfig) subscriptionTopics() map[string]byte {
+	filters := make(map[string]byte, len(cfg.Topics))
+	for _, topic := range cfg.Topics {
+		if cfg.SharedGroup != "" {
+			topic = "$share/" + cfg.SharedGroup + "/" + topic
+		}
+		filters[topic] = cfg.QoS
+	}
+	return filters
+}
+
+// MQTTブリッジの稼働状況
+type MQTTMetrics struct {
+	Connected         atomic.Bool
+	Connects          atomic.Int64 // 接続（再接続を含む）に成功した回数
+	ConnectionLost    atomic.Int64 // 切断された回数
+	ConnectAttempts   atomic.Int64 // 接続を試みた回数
+	MessagesReceived  atomic.Int64
+	VisitorUpdates    atomic.Int64 // 来場者数の更新として処理した件数
+	SensorBatches     atomic.Int64 // センサーイベントとして処理した件数
+	MessagesInvalid   atomic.Int64 // 形式が不正で処理しなかった件数
+	MessagesFailed    atomic.Int64 // 処理中にエラーになった件数
+	lastMessageAt     atomic.Int64 // UNIXミリ秒
+	mu                sync.Mutex
+	lastError         string
+	lastErrorAt       time.Time
+	enabled           bool
+	brokerURL         string
+	subscriptionCount int
+}
+
+// 処理中のブリッジの稼働状況（無効の場合も参照できる）
+var mqttMetrics = &MQTTMetrics{}
+
+func (m *MQTTMetrics) recordError(err error) {
+	m.mu.Lock()
+	m.lastError = err.Error()
+	m.lastErrorAt = time.Now()
+	m.mu.Unlock()
+}
+
+// APIで返す形
+func (m *MQTTMetrics) Snapshot() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := map[string]interface{}{
+		"enabled":           m.enabled,
+		"broker_url":        m.brokerURL,
+		"subscriptions":     m.subscriptionCount,
+		"connected":         m.Connected.Load(),
+		"connects":          m.Connects.Load(),
+		"connection_lost":   m.ConnectionLost.Load(),
+		"connect_attempts":  m.ConnectAttempts.Load(),
+		"messages_received": m.MessagesReceived.Load(),
+		"visitor_updates":   m.VisitorUpdates.Load(),
+		"sensor_batches":    m.SensorBatches.Load(),
+		"messages_invalid":  m.MessagesInvalid.Load(),
+		"messages_failed":   m.MessagesFailed.Load(),
+		"last_message_at":   nil,
+		"last_error":        m.lastError,
+		"last_error_at":     nil,
+	}
+	if ms := m.lastMessageAt.Load(); ms > 0 {
+		snapshot["last_message_at"] = time.UnixMilli(ms)
+	}
+	if !m.lastErrorAt.IsZero() {
+		snapshot["last_error_at"] = m.lastErrorAt
+	}
+	return snapshot
+}
+
+// MQTTで受け付けるメッセージ
+// events または device_id があればセンサーイベント、それ以外は来場者数の更新として扱う
+type mqttPayload struct {
+	TouristSpotID *uint `json:"tourist_spot_id"` // 省略時はトピックの spots/<id> から取得
+	VisitorUpdate
+	SentAt *time.Time         `json:"sent_at"`
+	Events []SensorEventInput `json:"events"`
+	SensorEventInput
+}
+
+// 形式が不正なメッセージのエラー
+type mqttPayloadError struct {
+	Message string
+}
+
+func (e *mqttPayloadError) Error() string {
+	return e.Message
+}
+
+// トピックの "spots/<id>" から観光地IDを取得
+func spotIDFromTopic(topic string) (uint, bool) {
+	parts := strings.Split(topic, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "spots" {
+			if id, err := strconv.ParseUint(parts[i+1], 10, 32); err == nil {
+				return uint(id), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// 1件のメッセージを処理する（ブローカーに依存しないため、ブローカーなしでも呼び出して確認できる）
+func HandleMQTTMessage(db *gorm.DB, redisClient *redis.Client, topic string, body []byte) error {
+	var payload mqttPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return &mqttPayloadError{"JSONとして解釈できません: " + err.Error()}
+	}
+
+	if len(payload.Events) > 0 || payload.DeviceID != "" {
+		batch := SensorBatch{SentAt: payload.SentAt, Events: payload.Events}
+		if len(batch.Events) == 0 {
+			batch.Events = []SensorEventInput{payload.SensorEventInput}
+		}
+		result, err := IngestSensorBatch(db, redisClient, batch, nil)
+		if err != nil {
+			var batchErr *SensorBatchError
+			if errors.As(err, &batchErr) {
+				return &mqttPayloadError{batchErr.Error()}
+			}
+			return err
+		}
+		mqttMetrics.SensorBatches.Add(1)
+		if result.Rejected > 0 {
+			fmt.Printf("⚠️ MQTTのセンサーイベントを一部拒否 - Topic: %s, Rejected: %d, Errors: %v\n", topic, result.Rejected, result.Errors)
+		}
+		return nil
+	}
+
+	spotID, ok := uint(0), false
+	if payload.TouristSpotID != nil {
+		spotID, ok = *payload.TouristSpotID, true
+	} else {
+		spotID, ok = spotIDFromTopic(topic)
+	}
+	if !ok {
+		return &mqttPayloadError{"tourist_spot_idがありません（トピックに spots/<id> を含めるか、本文で指定してください）"}
+	}
+	if _, err := ApplyVisitorUpdate(db, redisClient, spotID, payload.VisitorUpdate, nil, "mqtt"); err != nil {
+		var countErr *VisitorCountError
+		switch {
+		case errors.As(err, &countErr):
+			return &mqttPayloadError{countErr.Error()}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return &mqttPayloadError{fmt.Sprintf("観光地が見つかりません（ID: %d）", spotID)}
+		}
+		return err
+	}
+	mqttMetrics.VisitorUpdates.Add(1)
+	return nil
+}
+
+// MQTTブリッジを開始（ctx が終了すると切断する）
+func StartMQTTBridge(ctx context.Context, db *gorm.DB, redisClient *redis.Client, cfg *MQTTConfig) {
+	filters := cfg.subscriptionTopics()
+	mqttMetrics.mu.Lock()
+	mqttMetrics.enabled = true
+	mqttMetrics.brokerURL = cfg.BrokerURL
+	if u, err := url.Parse(cfg.BrokerURL); err == nil {
+		mqttMetrics.brokerURL = u.Redacted() // URLに含まれるパスワードは返さない
+	}
+	mqttMetrics.subscriptionCount = len(filters)
+	mqttMetrics.mu.Unlock()
+
+	onMessage := func(_ mqtt.Client, msg mqtt.Message) {
+		mqttMetrics.MessagesReceived.Add(1)
+		mqttMetrics.lastMessageAt.Store(time.Now().UnixMilli())
+		if err := HandleMQTTMessage(db, redisClient, msg.Topic(), msg.Payload()); err != nil {
+			var payloadErr *mqttPayloadError
+			if errors.As(err, &payloadErr) {
+				mqttMetrics.MessagesInvalid.Add(1)
+			} else {
+				mqttMetrics.MessagesFailed.Add(1)
+			}
+			mqttMetrics.recordError(err)
+			fmt.Printf("⚠️ MQTTメッセージの処理に失敗 - Topic: %s, Error: %v\n", msg.Topic(), err)
+		}
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.BrokerURL).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetCleanSession(false). // 切断中のQoS1以上のメッセージはブローカーに保持させる
+		SetKeepAlive(mqttKeepAlive).
+		SetConnectTimeout(mqttConnectTimeout).
+		SetAutoReconnect(true). // 切断後は paho が1秒から倍々で MaxReconnectInterval まで間隔を空けて再接続する
+		SetMaxReconnectInterval(cfg.MaxBackoff).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			mqttMetrics.Connected.Store(true)
+			mqttMetrics.Connects.Add(1)
+			// 再接続時も購読し直す（セッションが失われている場合に備える）
+			token := c.SubscribeMultiple(filters, onMessage)
+			if token.WaitTimeout(mqttConnectTimeout) && token.Error() == nil {
+				fmt.Printf("✅ MQTTブローカーに接続 - %s (%d topics)\n", cfg.BrokerURL, len(filters))
+				return
+			}
+			err := token.Error()
+			if err == nil {
+				err = fmt.Errorf("subscribe timeout")
+			}
+			mqttMetrics.recordError(err)
+			fmt.Printf("⚠️ MQTTトピックの購読に失敗: %v\n", err)
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			mqttMetrics.Connected.Store(false)
+			mqttMetrics.ConnectionLost.Add(1)
+			mqttMetrics.recordError(err)
+			fmt.Printf("⚠️ MQTTブローカーとの接続が切れました（再接続します）: %v\n", err)
+		}).
+		SetReconnectingHandler(func(mqtt.Client, *mqtt.ClientOptions) {
+			mqttMetrics.ConnectAttempts.Add(1)
+		})
+	client := mqtt.NewClient(opts)
+
+	go func() {
+		// 初回接続は指数バックオフ（ジッター付き）で繰り返す
+		backoff := mqttInitialBackoff
+		for {
+			mqttMetrics.ConnectAttempts.Add(1)
+			token := client.Connect()
+			if token.WaitTimeout(mqttConnectTimeout) && token.Error() == nil {
+				break
+			}
+			err := token.Error()
+			if err == nil {
+				err = fmt.Errorf("connect timeout")
+			}
+			mqttMetrics.recordError(err)
+			wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+			fmt.Printf("⚠️ MQTTブローカーに接続できません（%v後に再試行）: %v\n", wait.Round(time.Millisecond), err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			if backoff *= 2; backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+		}
+
+		<-ctx.Done()
+		client.Disconnect(250)
+		mqttMetrics.Connected.Store(false)
+	}()
+}
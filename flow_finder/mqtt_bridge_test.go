@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// DBに届く前に不正と判定されるメッセージ
+func TestHandleMQTTMessageInvalidPayload(t *testing.T) {
+	tooManyEvents := `{"events": [` + strings.TrimSuffix(strings.Repeat(`{"device_id": "gate", "seq": 1},`, sensorMaxBatchEvents+1), ",") + `]}`
+	tests := []struct {
+		name  string
+		topic string
+		body  string
+	}{
+		{name: "JSONではない", topic: "flowfinder/spots/1/visitors", body: `increment`},
+		{name: "観光地IDがない", topic: "flowfinder/visitors", body: `{"action": "increment"}`},
+		{name: "トピックの観光地IDが数値ではない", topic: "flowfinder/spots/north/visitors", body: `{"action": "increment"}`},
+		{name: "不明なaction", topic: "flowfinder/spots/1/visitors", body: `{"action": "jump"}`},
+		{name: "負の人数", topic: "flowfinder/spots/1/visitors", body: `{"action": "increment", "count": -2}`},
+		{name: "センサーイベントが多すぎる", topic: "flowfinder/sensors/gate/events", body: tooManyEvents},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := HandleMQTTMessage(nil, nil, tt.topic, []byte(tt.body))
+			var payloadErr *mqttPayloadError
+			if !errors.As(err, &payloadErr) {
+				t.Fatalf("err = %v, want mqttPayloadError", err)
+			}
+		})
+	}
+}
+
+func TestHandleMQTTMessage(t *testing.T) {
+	db := openTestDB(t)
+
+	tests := []struct {
+		name       string
+		topic      string
+		body       func(spotID uint, deviceID string) string
+		wantCount  int
+		wantErr    bool // mqttPayloadError
+		spotInBody bool // トピックには別の観光地IDを指定する
+	}{
+		{
+			name:      "トピックの観光地ID",
+			topic:     "flowfinder/spots/%d/visitors",
+			body:      func(uint, string) string { return `{"action": "increment", "count": 3}` },
+			wantCount: 3,
+		},
+		{
+			name:  "本文の観光地IDを優先",
+			topic: "flowfinder/spots/%d/visitors",
+			body: func(id uint, _ string) string {
+				return fmt.Sprintf(`{"tourist_spot_id": %d, "action": "increment", "count": 2}`, id)
+			},
+			wantCount:  2,
+			spotInBody: true,
+		},
+		{
+			name:      "来場者数の直接設定",
+			topic:     "flowfinder/spots/%d/visitors",
+			body:      func(uint, string) string { return `{"current_count": 7}` },
+			wantCount: 7,
+		},
+		{
+			name:  "センサーイベントのまとまり",
+			topic: "flowfinder/sensors/gate/events",
+			body: func(_ uint, deviceID string) string {
+				now := time.Now().Format(time.RFC3339)
+				return fmt.Sprintf(`{"events": [{"device_id": %q, "seq": 1, "timestamp": %q, "in": 5, "out": 1}, {"device_id": %q, "seq": 2, "timestamp": %q, "in": 2, "out": 0}]}`, deviceID, now, deviceID, now)
+			},
+			wantCount: 6,
+		},
+		{
+			name:  "センサーイベント1件",
+			topic: "flowfinder/sensors/gate/events",
+			body: func(_ uint, deviceID string) string {
+				return fmt.Sprintf(`{"device_id": %q, "seq": 1, "timestamp": %q, "in": 4, "out": 0}`, deviceID, time.Now().Format(time.RFC3339))
+			},
+			wantCount: 4,
+		},
+		{
+			name:    "許容人数を超える増加",
+			topic:   "flowfinder/spots/%d/visitors",
+			body:    func(uint, string) string { return `{"action": "increment", "count": 11}` },
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spot := createTestSpot(t, db, TouristSpot{Name: "MQTTテスト", MaxCapacity: 10})
+			deviceID := fmt.Sprintf("mqtt-test-%d-%d", time.Now().UnixNano(), i)
+			device := SensorDevice{DeviceID: deviceID, TouristSpotID: spot.ID, Enabled: true}
+			if err := db.Create(&device).Error; err != nil {
+				t.Fatalf("機器の作成失敗: %v", err)
+			}
+			t.Cleanup(func() {
+				db.Where("sensor_device_id = ?", device.ID).Delete(&SensorEvent{})
+				db.Delete(&SensorDevice{}, device.ID)
+			})
+
+			topicSpotID := spot.ID
+			if tt.spotInBody {
+				other := createTestSpot(t, db, TouristSpot{Name: "MQTTテスト（トピック側）", MaxCapacity: 10})
+				topicSpotID = other.ID
+			}
+			topic := tt.topic
+			if strings.Contains(topic, "%d") {
+				topic = fmt.Sprintf(topic, topicSpotID)
+			}
+
+			err := HandleMQTTMessage(db, nil, topic, []byte(tt.body(spot.ID, deviceID)))
+			var payloadErr *mqttPayloadError
+			if tt.wantErr {
+				if !errors.As(err, &payloadErr) {
+					t.Fatalf("err = %v, want mqttPayloadError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleMQTTMessage: %v", err)
+			}
+
+			var got TouristSpot
+			if err := db.First(&got, spot.ID).Error; err != nil {
+				t.Fatalf("観光地の取得失敗: %v", err)
+			}
+			if got.CurrentCount != tt.wantCount {
+				t.Errorf("current_count=%d, want %d", got.CurrentCount, tt.wantCount)
+			}
+			if tt.spotInBody {
+				var other TouristSpot
+				if err := db.First(&other, topicSpotID).Error; err == nil && other.CurrentCount != 0 {
+					t.Errorf("トピック側の観光地が更新された: current_count=%d", other.CurrentCount)
+				}
+			}
+		})
+	}
+}
+
+// ブローカー経由の取り込み（MQTT_BROKER_URL が未設定の場合はスキップ）
+// 例: docker-compose --profile mqtt up -d mosquitto && MQTT_BROKER_URL=tcp://localhost:1883 go test -run MQTTBridge
+func TestMQTTBridgeIntegration(t *testing.T) {
+	broker := os.Getenv("MQTT_BROKER_URL")
+	if broker == "" {
+		t.Skip("MQTT_BROKER_URL が未設定のためスキップ")
+	}
+	db := openTestDB(t)
+	spot := createTestSpot(t, db, TouristSpot{Name: "MQTTブリッジテスト", MaxCapacity: 10})
+
+	// 他の実行と混ざらないよう、実行ごとのトピックを使う
+	prefix := fmt.Sprintf("flowfinder-test/%d", time.Now().UnixNano())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	StartMQTTBridge(ctx, db, nil, &MQTTConfig{
+		BrokerURL:  broker,
+		ClientID:   "flowfinder-test-bridge-" + strings.ReplaceAll(prefix, "/", "-"),
+		Topics:     []string{prefix + "/spots/+/visitors"},
+		QoS:        1,
+		MaxBackoff: mqttInitialBackoff,
+	})
+
+	publisher := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID("flowfinder-test-publisher-" + strings.ReplaceAll(prefix, "/", "-")))
+	if token := publisher.Connect(); !token.WaitTimeout(mqttConnectTimeout) || token.Error() != nil {
+		t.Fatalf("ブローカーに接続できません: %v", token.Error())
+	}
+	defer publisher.Disconnect(250)
+
+	// ブリッジの購読より先に届いても取り込まれるよう retained で送る（終了時に消す）
+	topic := fmt.Sprintf("%s/spots/%d/visitors", prefix, spot.ID)
+	if token := publisher.Publish(topic, 1, true, `{"action": "increment", "count": 3}`); !token.WaitTimeout(mqttConnectTimeout) || token.Error() != nil {
+		t.Fatalf("publish失敗: %v", token.Error())
+	}
+	defer func() {
+		publisher.Publish(topic, 1, true, []byte{}).WaitTimeout(mqttConnectTimeout)
+	}()
+
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		var got TouristSpot
+		if err := db.First(&got, spot.ID).Error; err != nil {
+			t.Fatalf("観光地の取得失敗: %v", err)
+		}
+		if got.CurrentCount == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("current_count=%d, want 3 (last_error: %v)", got.CurrentCount, mqttMetrics.Snapshot()["last_error"])
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	cancel()
+}
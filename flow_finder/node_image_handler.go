@@ -20,13 +20,13 @@ func getNodeImagesHandler(db *gorm.DB) gin.HandlerFunc {
 		nodeIDStr := c.Param("id")
 		nodeID, err := strconv.Atoi(nodeIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なノードIDです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "node.invalid_id")})
 			return
 		}
 
 		var images []NodeImage
 		if err := db.Where("node_id = ?", nodeID).Order("\"order\" ASC, id ASC").Find(&images).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "画像の取得に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "image.fetch_failed")})
 			return
 		}
 
@@ -45,14 +45,14 @@ func uploadNodeImageHandler(db *gorm.DB) gin.HandlerFunc {
 		nodeIDStr := c.Param("id")
 		nodeID, err := strconv.Atoi(nodeIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なノードIDです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "node.invalid_id")})
 			return
 		}
 
 		// ノードが存在するか確認
 		var node Node
 		if err := db.First(&node, nodeID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "ノードが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "node.not_found")})
 			return
 		}
 
@@ -62,7 +62,7 @@ func uploadNodeImageHandler(db *gorm.DB) gin.HandlerFunc {
 			// "file"フィールドも試行
 			file, err = c.FormFile("file")
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "画像ファイルが見つかりません"})
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "image.file_not_found")})
 				return
 			}
 		}
@@ -72,21 +72,21 @@ func uploadNodeImageHandler(db *gorm.DB) gin.HandlerFunc {
 
 		// ディレクトリが存在しない場合は作成
 		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("ディレクトリ作成エラー: %v", err)})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.dir_failed"), "details": err.Error()})
 			return
 		}
 
 		// ファイル内容を読み取ってハッシュを計算
 		src, err := file.Open()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ファイルの読み取りに失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.read_failed")})
 			return
 		}
 		defer src.Close()
 
 		hash := md5.New()
 		if _, err := io.Copy(hash, src); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ファイルハッシュの計算に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.hash_failed")})
 			return
 		}
 		fileHash := fmt.Sprintf("%x", hash.Sum(nil))
@@ -99,7 +99,7 @@ func uploadNodeImageHandler(db *gorm.DB) gin.HandlerFunc {
 
 		// ファイルを保存
 		if err := c.SaveUploadedFile(file, filePath); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("ファイル保存エラー: %v", err)})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.save_failed"), "details": err.Error()})
 			return
 		}
 
@@ -118,7 +118,7 @@ func uploadNodeImageHandler(db *gorm.DB) gin.HandlerFunc {
 		if err := db.Create(&nodeImage).Error; err != nil {
 			// データベース保存に失敗した場合、ファイルを削除
 			os.Remove(filePath)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "データベース保存エラー"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.save_failed")})
 			return
 		}
 
@@ -138,19 +138,19 @@ func deleteNodeImageHandler(db *gorm.DB) gin.HandlerFunc {
 		imageIDStr := c.Param("id")
 		imageID, err := strconv.Atoi(imageIDStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効な画像IDです"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "image.invalid_id")})
 			return
 		}
 
 		var nodeImage NodeImage
 		if err := db.First(&nodeImage, imageID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "画像が見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "image.not_found")})
 			return
 		}
 
 		// データベースから削除
 		if err := db.Delete(&nodeImage).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "削除に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.delete_failed")})
 			return
 		}
 
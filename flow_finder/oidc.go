@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenID Connect（認可コードフロー + PKCE）の設定
+type OIDCConfig struct {
+	Issuer            string
+	ClientID          string
+	ClientSecret      string // 公開クライアントの場合は空
+	RedirectURL       string // /api/auth/oidc/callback の外部URL
+	Scopes            []string
+	GroupsClaim       string            // グループ一覧が入るIDトークンのクレーム名
+	AdminGroups       []string          // 管理者フラグを付与するグループ
+	GroupRoles        map[string]string // グループ名 → ロール名
+	PostLoginRedirect string            // ログイン後にトークンをフラグメントで渡すフロントエンドURL（空の場合はJSONで返す）
+}
+
+var (
+	ErrOIDCIDToken       = errors.New("invalid id token")
+	ErrOIDCTokenExchange = errors.New("token exchange failed")
+)
+
+const (
+	oidcDiscoveryTTL   = time.Hour
+	oidcJWKSRefreshMin = time.Minute // 未知のkidによるJWKS再取得の最小間隔
+	oidcClockSkew      = time.Minute
+)
+
+// 環境変数からOIDC設定を読み込む（OIDC_ISSUERが未設定の場合はnil）
+//
+//	OIDC_ISSUER               IdPのissuer URL
+//	OIDC_CLIENT_ID            クライアントID
+//	OIDC_CLIENT_SECRET        クライアントシークレット（任意）
+//	OIDC_REDIRECT_URL         コールバックURL（例: https://example.com/api/auth/oidc/callback）
+//	OIDC_SCOPES               スコープ（デフォルト: "openid profile email"）
+//	OIDC_GROUPS_CLAIM         グループのクレーム名（デフォルト: groups）
+//	OIDC_ADMIN_GROUPS         管理者とするグループ（カンマ区切り）
+//	OIDC_GROUP_ROLES          "グループ:ロール" のカンマ区切り
+//	OIDC_POST_LOGIN_REDIRECT  ログイン後のリダイレクト先
+func loadOIDCConfig() (*OIDCConfig, error) {
+	issuer := strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		return nil, nil
+	}
+	cfg := &OIDCConfig{
+		Issuer:            issuer,
+		ClientID:          os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:      os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:       os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:            strings.Fields(os.Getenv("OIDC_SCOPES")),
+		GroupsClaim:       os.Getenv("OIDC_GROUPS_CLAIM"),
+		AdminGroups:       splitList(os.Getenv("OIDC_ADMIN_GROUPS")),
+		GroupRoles:        map[string]string{},
+		PostLoginRedirect: os.Getenv("OIDC_POST_LOGIN_REDIRECT"),
+	}
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC_ISSUERを設定する場合はOIDC_CLIENT_IDとOIDC_REDIRECT_URLも必要です")
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	for _, pair := range splitList(os.Getenv("OIDC_GROUP_ROLES")) {
+		group, role, ok := strings.Cut(pair, ":")
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("OIDC_GROUP_ROLESの形式が不正です: %s", pair)
+		}
+		cfg.GroupRoles[group] = role
+	}
+	return cfg, nil
+}
+
+// ディスカバリードキュメント（必要な項目のみ）
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDトークンから取り出したユーザー情報
+type OIDCIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string // preferred_username → email → name の順で採用
+	Email    string
+	Groups   []string
+}
+
+// OIDCプロバイダのクライアント（ディスカバリーとJWKSをキャッシュする）
+type OIDCProvider struct {
+	cfg        *OIDCConfig
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(cfg *OIDCConfig) *OIDCProvider {
+	return &OIDCProvider{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// 起動時に生成したプロバイダ（未設定の場合はnil）
+var oidcProvider *OIDCProvider
+
+// ディスカバリードキュメントを取得（キャッシュ付き）
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.discovery, nil
+	}
+
+	var doc oidcDiscovery
+	if err := p.getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("issuerが一致しません: %s", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("ディスカバリードキュメントに必要な項目がありません")
+	}
+	p.discovery = &doc
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// 認可エンドポイントのURLを組み立てる
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return doc.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// 認可コードをトークンに交換し、検証済みのIDトークンからユーザー情報を返す
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, ErrOIDCTokenExchange
+	}
+	if resp.StatusCode != http.StatusOK || tokenResp.IDToken == "" {
+		return nil, fmt.Errorf("%w: status %d %s", ErrOIDCTokenExchange, resp.StatusCode, tokenResp.Error)
+	}
+
+	return p.verifyIDToken(ctx, tokenResp.IDToken, nonce, time.Now())
+}
+
+// IDトークンの署名とクレーム（iss, aud, exp, nonce）を検証
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, token, nonce string, now time.Time) (*OIDCIdentity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrOIDCIDToken
+	}
+	enc := base64.RawURLEncoding
+	headerJSON, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrOIDCIDToken
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrOIDCIDToken
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrOIDCIDToken
+	}
+
+	key, err := p.publicKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyOIDCSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrOIDCIDToken
+	}
+
+	payload, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrOIDCIDToken
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrOIDCIDToken
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimRight(iss, "/") != p.cfg.Issuer {
+		return nil, ErrOIDCIDToken
+	}
+	if !containsString(stringListClaim(claims["aud"]), p.cfg.ClientID) {
+		return nil, ErrOIDCIDToken
+	}
+	exp, _ := claims["exp"].(float64)
+	if now.After(time.Unix(int64(exp), 0).Add(oidcClockSkew)) {
+		return nil, ErrOIDCIDToken
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, ErrOIDCIDToken
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, ErrOIDCIDToken
+	}
+
+	identity := &OIDCIdentity{
+		Issuer:  p.cfg.Issuer,
+		Subject: sub,
+		Groups:  stringListClaim(claims[p.cfg.GroupsClaim]),
+	}
+	identity.Email, _ = claims["email"].(string)
+	for _, name := range []string{"preferred_username", "email", "name"} {
+		if v, _ := claims[name].(string); v != "" {
+			identity.Username = v
+			break
+		}
+	}
+	return identity, nil
+}
+
+// kidに対応する公開鍵を取得（見つからない場合はJWKSを再取得）
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	canRefresh := time.Since(p.keysFetchedAt) >= oidcJWKSRefreshMin
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !canRefresh {
+		return nil, ErrOIDCIDToken
+	}
+
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var jwks struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := p.getJSON(ctx, doc.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrOIDCIDToken
+}
+
+// JWKの1エントリ（RSAとP-256のみ対応）
+type oidcJWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k oidcJWK) publicKey() (crypto.PublicKey, error) {
+	if k.Use != "" && k.Use != "sig" {
+		return nil, errors.New("not a signing key")
+	}
+	enc := base64.RawURLEncoding
+	switch k.Kty {
+	case "RSA":
+		n, err := enc.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := enc.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, errors.New("unsupported curve")
+		}
+		x, err := enc.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := enc.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("invalid ec key")
+		}
+		return pub, nil
+	}
+	return nil, errors.New("unsupported key type")
+}
+
+// IDトークンの署名を検証（RS256 / ES256）
+func verifyOIDCSignature(alg string, key crypto.PublicKey, data, sig []byte) bool {
+	digest := sha256.Sum256(data)
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest[:], r, s)
+	}
+	return false
+}
+
+// 文字列または文字列配列のクレームをスライスに変換
+func stringListClaim(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		result := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// state・nonce・PKCEのcode_verifierに使うランダム文字列
+func randomURLToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 認可リクエストからコールバックまでの一時情報
+const oidcStateTTL = 10 * time.Minute
+
+type oidcLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	GuestUserID  uint   `json:"guest_user_id,omitempty"` // ログイン後にデータを引き継ぐゲスト
+}
+
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
+
+// OIDCログイン関連のルートを登録（OIDC未設定の場合は404を返す）
+func RegisterOIDCRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	// IdPの認可エンドポイントへリダイレクト
+	r.GET("/api/auth/oidc/login", oidcLoginHandler(db, redisClient))
+
+	// IdPからのコールバック（認可コードをトークンに交換してログイン）
+	r.GET("/api/auth/oidc/callback", oidcCallbackHandler(db, redisClient))
+}
+
+// OIDCログイン開始ハンドラ
+func oidcLoginHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if oidcProvider == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "oidc.not_configured")})
+			return
+		}
+
+		state, err1 := randomURLToken(24)
+		nonce, err2 := randomURLToken(24)
+		verifier, err3 := randomURLToken(48)
+		if err1 != nil || err2 != nil || err3 != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "oidc.start_failed")})
+			return
+		}
+
+		ctx := c.Request.Context()
+		loginState := oidcLoginState{Nonce: nonce, CodeVerifier: verifier}
+		if guestID, ok := guestUserIDFromContext(c, db); ok {
+			loginState.GuestUserID = guestID
+		}
+		data, _ := json.Marshal(loginState)
+		if err := redisClient.Set(ctx, oidcStateKey(state), data, oidcStateTTL).Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+
+		authURL, err := oidcProvider.AuthCodeURL(ctx, state, nonce, verifier)
+		if err != nil {
+			fmt.Printf("❌ OIDCディスカバリー失敗: %v\n", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": T(c, "oidc.provider_unreachable")})
+			return
+		}
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// OIDCコールバックハンドラ
+func oidcCallbackHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if oidcProvider == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "oidc.not_configured")})
+			return
+		}
+
+		sessionID := c.GetHeader("X-Session-Id")
+		if sessionID == "" {
+			sessionID = generateHandlerSessionID()
+		}
+
+		if errCode := c.Query("error"); errCode != "" {
+			logLoginAttempt(db, c, nil, sessionID, "oidc: "+errCode)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "oidc.provider_auth_failed"), "details": errCode})
+			return
+		}
+
+		// stateは1回限り有効（GETDELで取り出す）
+		ctx := c.Request.Context()
+		raw, err := redisClient.GetDel(ctx, oidcStateKey(c.Query("state"))).Result()
+		if err == redis.Nil || c.Query("state") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "oidc.login_request_expired")})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+		var state oidcLoginState
+		if err := json.Unmarshal([]byte(raw), &state); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "oidc.login_request_invalid")})
+			return
+		}
+
+		identity, err := oidcProvider.Exchange(ctx, c.Query("code"), state.CodeVerifier, state.Nonce)
+		if err != nil {
+			fmt.Printf("❌ OIDCトークン交換失敗: %v\n", err)
+			logLoginAttempt(db, c, nil, sessionID, "oidc: invalid token")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "oidc.id_token_invalid")})
+			return
+		}
+
+		user, err := upsertOIDCUser(db, authConfig.OIDC, identity)
+		if err != nil {
+			fmt.Printf("❌ OIDCユーザー作成失敗: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "oidc.user_save_failed")})
+			return
+		}
+
+		tokens, err := CreateSession(context.Background(), redisClient, user, c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "auth.token_save_failed")})
+			return
+		}
+		logLoginAttempt(db, c, &user.ID, sessionID, "")
+
+		if state.GuestUserID != 0 && state.GuestUserID != user.ID {
+			mergeGuestByID(db, redisClient, state.GuestUserID, user.ID)
+		}
+
+		result := gin.H{
+			"token":                tokens.Token,
+			"token_type":           tokens.TokenType,
+			"refresh_token":        tokens.RefreshToken,
+			"expires_in":           int(tokens.ExpiresIn.Seconds()),
+			"auth_session_id":      tokens.SessionID,
+			"user_id":              user.ID,
+			"session_id":           sessionID,
+			"is_admin":             user.IsAdmin,
+			"must_change_password": false,
+		}
+
+		// フロントエンドへはURLフラグメントで渡す（サーバーログやRefererに残さない）
+		if redirect := authConfig.OIDC.PostLoginRedirect; redirect != "" {
+			fragment := url.Values{}
+			for k, v := range result {
+				fragment.Set(k, fmt.Sprint(v))
+			}
+			c.Redirect(http.StatusFound, redirect+"#"+fragment.Encode())
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// IdPのsubjectに対応するユーザーを作成・更新し、グループから管理者フラグとロールを同期する
+func upsertOIDCUser(db *gorm.DB, cfg *OIDCConfig, identity *OIDCIdentity) (*User, error) {
+	var user User
+	err := db.Preload("Roles").
+		Where("oidc_issuer = ? AND oidc_subject = ?", identity.Issuer, identity.Subject).
+		First(&user).Error
+	isNew := err == gorm.ErrRecordNotFound
+	if err != nil && !isNew {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if isNew {
+			subject := identity.Subject
+			user = User{
+				Name:        oidcUserName(tx, identity),
+				OIDCIssuer:  identity.Issuer,
+				OIDCSubject: &subject,
+			}
+		}
+		if len(cfg.AdminGroups) > 0 {
+			user.IsAdmin = false
+			for _, g := range identity.Groups {
+				if containsString(cfg.AdminGroups, g) {
+					user.IsAdmin = true
+					break
+				}
+			}
+		}
+		if err := tx.Omit("Roles").Save(&user).Error; err != nil {
+			return err
+		}
+
+		if len(cfg.GroupRoles) == 0 {
+			return nil
+		}
+		var roleNames []string
+		for _, g := range identity.Groups {
+			if role, ok := cfg.GroupRoles[g]; ok {
+				roleNames = append(roleNames, role)
+			}
+		}
+		var roles []Role
+		if len(roleNames) > 0 {
+			if err := tx.Where("name IN ?", roleNames).Find(&roles).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&user).Association("Roles").Replace(roles); err != nil {
+			return err
+		}
+		user.Roles = roles
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if isNew {
+		RecordChangeHistory(db, "users", strconv.Itoa(int(user.ID)), &user.ID, "create", nil, gin.H{
+			"name": user.Name, "oidc_issuer": user.OIDCIssuer, "is_admin": user.IsAdmin,
+		})
+	}
+	return &user, nil
+}
+
+// 新規OIDCユーザーの表示名（既存ユーザーと重複する場合はsubjectの一部を付ける）
+func oidcUserName(db *gorm.DB, identity *OIDCIdentity) string {
+	name := identity.Username
+	if name == "" {
+		name = "oidc"
+	}
+	var count int64
+	db.Model(&User{}).Where("name = ?", name).Count(&count)
+	if count == 0 {
+		return name
+	}
+	suffix := identity.Subject
+	if len(suffix) > 8 {
+		suffix = suffix[:8]
+	}
+	return name + "-" + strings.ToLower(suffix)
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testOIDCClientID = "flow-finder-test"
+
+// テスト用のOIDCプロバイダ（ディスカバリー・JWKS・トークンエンドポイントを提供する）
+type mockOIDCServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+	kid string
+
+	mu            sync.Mutex
+	codes         map[string]mockOIDCCode
+	tokenRequests int
+}
+
+// 発行済みの認可コード（PKCEのchallengeとIDトークンのクレームを保持）
+type mockOIDCCode struct {
+	challenge string
+	claims    map[string]interface{}
+	kid       string
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("鍵の生成失敗: %v", err)
+	}
+	m := &mockOIDCServer{key: key, kid: "mock-key-1", codes: map[string]mockOIDCCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []oidcJWK{{
+			Kid: m.kid,
+			Kty: "RSA",
+			Use: "sig",
+			N:   enc.EncodeToString(key.N.Bytes()),
+			E:   enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", m.handleToken)
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// 認可エンドポイントの代わりに、認可URLのパラメータから認可コードを発行する
+// claims は標準クレーム（iss, aud, exp, nonce）を上書きする
+func (m *mockOIDCServer) authorize(t *testing.T, authURL string, claims map[string]interface{}, kid string) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("認可URLが不正: %v", err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != testOIDCClientID || q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("認可リクエストが不正: %s", authURL)
+	}
+
+	idClaims := map[string]interface{}{
+		"iss":   m.URL,
+		"aud":   testOIDCClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		"nonce": q.Get("nonce"),
+	}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+	if kid == "" {
+		kid = m.kid
+	}
+
+	code = fmt.Sprintf("code-%d", time.Now().UnixNano())
+	m.mu.Lock()
+	m.codes[code] = mockOIDCCode{challenge: q.Get("code_challenge"), claims: idClaims, kid: kid}
+	m.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (m *mockOIDCServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.tokenRequests++
+	issued, ok := m.codes[r.PostFormValue("code")]
+	delete(m.codes, r.PostFormValue("code"))
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("client_id") != testOIDCClientID ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != issued.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	enc := base64.RawURLEncoding
+	header, _ := json.Marshal(jwtHeader{Alg: "RS256", Typ: "JWT", Kid: issued.kid})
+	payload, _ := json.Marshal(issued.claims)
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signingInput + "." + enc.EncodeToString(sig)})
+}
+
+func (m *mockOIDCServer) config() *OIDCConfig {
+	return &OIDCConfig{
+		Issuer:      m.URL,
+		ClientID:    testOIDCClientID,
+		RedirectURL: "http://localhost/api/auth/oidc/callback",
+		Scopes:      []string{"openid", "profile"},
+		GroupsClaim: "groups",
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	srv := newMockOIDCServer(t)
+
+	same := func(s string) string { return s }
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		kid      string
+		verifier func(string) string
+		nonce    func(string) string
+		wantErr  error
+	}{
+		{
+			name:     "正常",
+			claims:   map[string]interface{}{"sub": "staff-1", "preferred_username": "hanako", "email": "hanako@example.com", "groups": []string{"staff", "admins"}},
+			verifier: same,
+			nonce:    same,
+		},
+		{
+			name:     "PKCEのcode_verifierが一致しない",
+			claims:   map[string]interface{}{"sub": "staff-1"},
+			verifier: func(string) string { return "another-verifier" },
+			nonce:    same,
+			wantErr:  ErrOIDCTokenExchange,
+		},
+		{
+			name:     "nonceが一致しない",
+			claims:   map[string]interface{}{"sub": "staff-1"},
+			verifier: same,
+			nonce:    func(string) string { return "another-nonce" },
+			wantErr:  ErrOIDCIDToken,
+		},
+		{
+			name:     "IDトークンにnonceがない",
+			claims:   map[string]interface{}{"sub": "staff-1", "nonce": ""},
+			verifier: same,
+			nonce:    same,
+			wantErr:  ErrOIDCIDToken,
+		},
+		{
+			name:     "audienceが別のクライアント",
+			claims:   map[string]interface{}{"sub": "staff-1", "aud": "other-client"},
+			verifier: same,
+			nonce:    same,
+			wantErr:  ErrOIDCIDToken,
+		},
+		{
+			name:     "issuerが異なる",
+			claims:   map[string]interface{}{"sub": "staff-1", "iss": "https://idp.example.com"},
+			verifier: same,
+			nonce:    same,
+			wantErr:  ErrOIDCIDToken,
+		},
+		{
+			name:     "有効期限切れ",
+			claims:   map[string]interface{}{"sub": "staff-1", "exp": time.Now().Add(-oidcClockSkew - time.Minute).Unix()},
+			verifier: same,
+			nonce:    same,
+			wantErr:  ErrOIDCIDToken,
+		},
+		{
+			name:     "JWKSにない鍵",
+			claims:   map[string]interface{}{"sub": "staff-1"},
+			kid:      "unknown-key",
+			verifier: same,
+			nonce:    same,
+			wantErr:  ErrOIDCIDToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			provider := NewOIDCProvider(srv.config())
+			state, _ := randomURLToken(24)
+			nonce, _ := randomURLToken(24)
+			verifier, _ := randomURLToken(48)
+
+			authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			code, gotState := srv.authorize(t, authURL, tt.claims, tt.kid)
+			if gotState != state {
+				t.Fatalf("state = %q, want %q", gotState, state)
+			}
+
+			identity, err := provider.Exchange(ctx, code, tt.verifier(verifier), tt.nonce(nonce))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if identity.Issuer != srv.URL || identity.Subject != "staff-1" || identity.Username != "hanako" || identity.Email != "hanako@example.com" {
+				t.Errorf("identity = %+v", identity)
+			}
+			if len(identity.Groups) != 2 || identity.Groups[1] != "admins" {
+				t.Errorf("groups = %v", identity.Groups)
+			}
+		})
+	}
+}
+
+// IdPのグループから管理者フラグとロールを同期する
+func TestUpsertOIDCUserGroupMapping(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&Role{}, &RolePermission{}, &User{}); err != nil {
+		t.Fatalf("AutoMigrate失敗: %v", err)
+	}
+
+	suffix := time.Now().UnixNano()
+	role := Role{Name: fmt.Sprintf("oidc-test-editor-%d", suffix)}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("ロールの作成失敗: %v", err)
+	}
+	issuer := fmt.Sprintf("https://idp-%d.example.com", suffix)
+	t.Cleanup(func() {
+		var ids []uint
+		db.Model(&User{}).Unscoped().Where("oidc_issuer = ?", issuer).Pluck("id", &ids)
+		if len(ids) > 0 {
+			db.Exec("DELETE FROM user_roles WHERE user_id IN ?", ids)
+			db.Unscoped().Delete(&User{}, ids)
+		}
+		db.Delete(&role)
+	})
+
+	cfg := &OIDCConfig{
+		AdminGroups: []string{"staff-admins"},
+		GroupRoles:  map[string]string{"editors": role.Name},
+	}
+	// 同じsubjectで順にログインする
+	tests := []struct {
+		name      string
+		cfg       *OIDCConfig
+		groups    []string
+		wantAdmin bool
+		wantRoles []string
+	}{
+		{name: "管理者グループとロールのグループ", cfg: cfg, groups: []string{"staff-admins", "editors"}, wantAdmin: true, wantRoles: []string{role.Name}},
+		{name: "グループから外れると降格", cfg: cfg, groups: []string{"staff"}, wantAdmin: false},
+		{name: "ロールのグループのみ", cfg: cfg, groups: []string{"editors"}, wantAdmin: false, wantRoles: []string{role.Name}},
+		{name: "管理者グループ未設定なら管理者フラグは変更しない", cfg: &OIDCConfig{}, groups: []string{"staff-admins"}, wantAdmin: false, wantRoles: []string{role.Name}},
+	}
+	var userID uint
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := upsertOIDCUser(db, tt.cfg, &OIDCIdentity{Issuer: issuer, Subject: "staff-1", Username: "hanako", Groups: tt.groups})
+			if err != nil {
+				t.Fatalf("upsertOIDCUser: %v", err)
+			}
+			if userID == 0 {
+				userID = user.ID
+			} else if user.ID != userID {
+				t.Fatalf("別のユーザーが作成された: %d, want %d", user.ID, userID)
+			}
+
+			var got User
+			if err := db.Preload("Roles").First(&got, user.ID).Error; err != nil {
+				t.Fatalf("ユーザーの取得失敗: %v", err)
+			}
+			if got.IsAdmin != tt.wantAdmin {
+				t.Errorf("is_admin = %v, want %v", got.IsAdmin, tt.wantAdmin)
+			}
+			var roles []string
+			for _, r := range got.Roles {
+				roles = append(roles, r.Name)
+			}
+			if fmt.Sprint(roles) != fmt.Sprint(tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", roles, tt.wantRoles)
+			}
+		})
+	}
+}
+
+// ログイン開始からコールバックまで（stateは1回限り・別のstateは拒否）
+func TestOIDCLoginFlow(t *testing.T) {
+	db := openTestDB(t)
+	redisClient := openTestRedis(t)
+	if err := db.AutoMigrate(&Role{}, &RolePermission{}, &User{}, &UserLog{}); err != nil {
+		t.Fatalf("AutoMigrate失敗: %v", err)
+	}
+
+	srv := newMockOIDCServer(t)
+	cfg := srv.config()
+	cfg.AdminGroups = []string{"staff-admins"}
+
+	savedConfig, savedProvider := authConfig, oidcProvider
+	t.Cleanup(func() { authConfig, oidcProvider = savedConfig, savedProvider })
+	authConfig = &AuthConfig{Mode: AuthModeOpaque, PasswordLoginEnabled: true, OIDC: cfg}
+	oidcProvider = NewOIDCProvider(cfg)
+	t.Cleanup(func() {
+		var ids []uint
+		db.Model(&User{}).Unscoped().Where("oidc_issuer = ?", srv.URL).Pluck("id", &ids)
+		for _, id := range ids {
+			RevokeUserSessions(context.Background(), redisClient, id, "")
+		}
+		if len(ids) > 0 {
+			db.Unscoped().Delete(&User{}, ids)
+		}
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterOIDCRoutes(r, db, redisClient)
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/api/auth/oidc/login")
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d", w.Code, http.StatusFound)
+	}
+	code, state := srv.authorize(t, w.Header().Get("Location"), map[string]interface{}{
+		"sub": "staff-1", "preferred_username": "oidc-flow-test", "groups": []string{"staff-admins"},
+	}, "")
+
+	// 発行していないstateは、トークン交換の前に拒否する
+	w = get("/api/auth/oidc/callback?" + url.Values{"code": {code}, "state": {state + "-forged"}}.Encode())
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("forged state status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	srv.mu.Lock()
+	tokenRequests := srv.tokenRequests
+	srv.mu.Unlock()
+	if tokenRequests != 0 {
+		t.Fatalf("不正なstateでトークンエンドポイントが呼ばれた")
+	}
+
+	w = get("/api/auth/oidc/callback?" + url.Values{"code": {code}, "state": {state}}.Encode())
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result struct {
+		Token   string `json:"token"`
+		IsAdmin bool   `json:"is_admin"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("レスポンスが不正: %v", err)
+	}
+	if result.Token == "" || !result.IsAdmin {
+		t.Errorf("result = %+v, want token and is_admin", result)
+	}
+
+	// 同じstateは再利用できない
+	w = get("/api/auth/oidc/callback?" + url.Values{"code": {code}, "state": {state}}.Encode())
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("reused state status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 営業時間スケジュール
+// 曜日ごとの営業時間（SpotOpeningHours）と日付指定の例外（SpotScheduleException）を観光地のタイムゾーンで評価する。
+// 閉場時刻が開場時刻以前の場合は翌日にまたがる営業（例: 18:00〜02:00）として扱う。
+// 曜日ごとの営業時間が1件もない場合は旧来の opening_time / closing_time を毎日の営業時間とし、それもなければ終日営業とする
+const (
+	scheduleDateLayout    = "2006-01-02"
+	scheduleLookaheadDays = 14 // 次の開場時刻を探す日数
+)
+
+// 曜日ごとの営業時間（同じ曜日に複数登録すると休憩をはさんだ営業を表せる）
+type SpotOpeningHours struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	TouristSpotID uint   `gorm:"not null;index" json:"tourist_spot_id"`
+	Weekday       int    `gorm:"not null" json:"weekday"`    // 0=日曜日 … 6=土曜日
+	OpenTime      string `gorm:"not null" json:"open_time"`  // "09:00"
+	CloseTime     string `gorm:"not null" json:"close_time"` // "18:00"（"24:00" 可、開場時刻以前なら翌日）
+}
+
+// 日付指定の例外（祝日の特別営業・臨時休業など）
+// 期間内の日はその日の曜日ごとの営業時間の代わりに例外の営業時間を使う（closed の場合は休業）
+type SpotScheduleException struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	TouristSpotID uint   `gorm:"not null;index:idx_schedule_exception_spot_date,priority:1" json:"tourist_spot_id"`
+	StartDate     string `gorm:"not null" json:"start_date"`                                                 // "2025-05-03"
+	EndDate       string `gorm:"not null;index:idx_schedule_exception_spot_date,priority:2" json:"end_date"` // 終了日（この日を含む）
+	Closed        bool   `json:"closed"`
+	OpenTime      string `json:"open_time"`
+	CloseTime     string `json:"close_time"`
+	Note          string `json:"note"`
+}
+
+// 現在の営業状況
+type OpenStatus struct {
+	IsOpen   bool       `json:"is_open"`
+	OpensAt  *time.Time `json:"opens_at,omitempty"`  // 営業時間外の場合、次の開場時刻（14日以内）
+	ClosesAt *time.Time `json:"closes_at,omitempty"` // 営業中の場合、閉場時刻（14日以内に閉まらない場合は省略）
+	TimeZone string     `json:"time_zone"`
+}
+
+// 営業している時間帯
+type openInterval struct {
+	Start, End time.Time
+}
+
+// "HH:MM" を0時からの分に変換（閉場時刻は "24:00" も可）
+func parseScheduleClock(s string, allowEndOfDay bool) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) == 2 && len(parts[0]) == 2 && len(parts[1]) == 2 {
+		h, err1 := strconv.Atoi(parts[0])
+		m, err2 := strconv.Atoi(parts[1])
+		if err1 == nil && err2 == nil && m >= 0 && m < 60 {
+			if h >= 0 && h < 24 {
+				return h*60 + m, nil
+			}
+			if allowEndOfDay && h == 24 && m == 0 {
+				return 24 * 60, nil
+			}
+		}
+	}
+	return 0, newLocalizedError("common.clock_format", s)
+}
+
+// 開場・閉場時刻の検証
+func validateOpenClose(open, close string) error {
+	o, err := parseScheduleClock(open, false)
+	if err != nil {
+		return err
+	}
+	cl, err := parseScheduleClock(close, true)
+	if err != nil {
+		return err
+	}
+	if o == cl%(24*60) {
+		return newLocalizedError("schedule.open_equals_close")
+	}
+	return nil
+}
+
+// 曜日ごとの営業時間の検証
+func (h *SpotOpeningHours) Validate() error {
+	if h.Weekday < 0 || h.Weekday > 6 {
+		return newLocalizedError("schedule.weekday_invalid")
+	}
+	return validateOpenClose(h.OpenTime, h.CloseTime)
+}
+
+// 例外の検証（終了日を省略した場合は開始日のみ）
+func (e *SpotScheduleException) Validate() error {
+	if e.EndDate == "" {
+		e.EndDate = e.StartDate
+	}
+	start, err := time.Parse(scheduleDateLayout, e.StartDate)
+	if err != nil {
+		return newLocalizedError("schedule.start_date_invalid")
+	}
+	end, err := time.Parse(scheduleDateLayout, e.EndDate)
+	if err != nil {
+		return newLocalizedError("schedule.end_date_invalid")
+	}
+	if end.Before(start) {
+		return newLocalizedError("schedule.end_before_start")
+	}
+	if e.Closed {
+		e.OpenTime, e.CloseTime = "", ""
+		return nil
+	}
+	return validateOpenClose(e.OpenTime, e.CloseTime)
+}
+
+// タイムゾーン名の検証（空の場合は既定のタイムゾーン）
+func loadSpotLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = defaultHistoryTimeZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, newLocalizedError("schedule.unknown_time_zone", name)
+	}
+	return loc, nil
+}
+
+// 観光地の営業時間スケジュール
+type OpeningSchedule struct {
+	Location   *time.Location
+	Weekly     []SpotOpeningHours
+	Exceptions []SpotScheduleException
+	AlwaysOpen bool // 曜日ごとの営業時間がない場合
+}
+
+// 観光地の営業時間スケジュールを組み立てる（OpeningHours・ScheduleExceptions は事前に読み込んでおく）
+func (ts *TouristSpot) Schedule() *OpeningSchedule {
+	loc, err := loadSpotLocation(ts.TimeZone)
+	if err != nil {
+		loc = time.Local
+	}
+	schedule := &OpeningSchedule{Location: loc, Weekly: ts.OpeningHours, Exceptions: ts.ScheduleExceptions}
+	if len(schedule.Weekly) > 0 {
+		return schedule
+	}
+	if validateOpenClose(ts.OpeningTime, ts.ClosingTime) == nil {
+		// 旧来の開場・閉場時刻を毎日の営業時間とする
+		for wd := 0; wd < 7; wd++ {
+			schedule.Weekly = append(schedule.Weekly, SpotOpeningHours{Weekday: wd, OpenTime: ts.OpeningTime, CloseTime: ts.ClosingTime})
+		}
+		return schedule
+	}
+	schedule.AlwaysOpen = true
+	return schedule
+}
+
+// 開場・閉場時刻から、指定日に始まる営業時間帯を作る
+func intervalOn(year int, month time.Month, day int, open, close string, loc *time.Location) (openInterval, bool) {
+	o, err1 := parseScheduleClock(open, false)
+	cl, err2 := parseScheduleClock(close, true)
+	if err1 != nil || err2 != nil {
+		return openInterval{}, false
+	}
+	if cl <= o {
+		cl += 24 * 60 // 翌日にまたがる
+	}
+	// time.Date は範囲外の時・分を正規化するため、翌日や夏時間の切り替えもそのまま扱える
+	return openInterval{
+		Start: time.Date(year, month, day, o/60, o%60, 0, 0, loc),
+		End:   time.Date(year, month, day, cl/60, cl%60, 0, 0, loc),
+	}, true
+}
+
+// 指定日（現地時間）に始まる営業時間帯
+func (s *OpeningSchedule) intervalsOn(year int, month time.Month, day int) []openInterval {
+	date := time.Date(year, month, day, 0, 0, 0, 0, s.Location)
+	key := date.Format(scheduleDateLayout)
+
+	var intervals []openInterval
+	exception := false
+	for _, e := range s.Exceptions {
+		if key < e.StartDate || key > e.EndDate {
+			continue
+		}
+		if e.Closed {
+			return nil
+		}
+		exception = true
+		if iv, ok := intervalOn(year, month, day, e.OpenTime, e.CloseTime, s.Location); ok {
+			intervals = append(intervals, iv)
+		}
+	}
+	if exception {
+		return intervals
+	}
+
+	if s.AlwaysOpen {
+		iv, _ := intervalOn(year, month, day, "00:00", "24:00", s.Location)
+		return []openInterval{iv}
+	}
+	for _, h := range s.Weekly {
+		if h.Weekday != int(date.Weekday()) {
+			continue
+		}
+		if iv, ok := intervalOn(year, month, day, h.OpenTime, h.CloseTime, s.Location); ok {
+			intervals = append(intervals, iv)
+		}
+	}
+	return intervals
+}
+
+// 指定した期間の営業時間帯（重なり・連続する時間帯はまとめる）
+func (s *OpeningSchedule) intervalsBetween(from time.Time, days int) []openInterval {
+	local := from.In(s.Location)
+	var intervals []openInterval
+	// 前日から始まる翌日にまたがる営業も含める
+	for i := -1; i <= days; i++ {
+		intervals = append(intervals, s.intervalsOn(local.Year(), local.Month(), local.Day()+i)...)
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	var merged []openInterval
+	for _, iv := range intervals {
+		if n := len(merged); n > 0 && !iv.Start.After(merged[n-1].End) {
+			if iv.End.After(merged[n-1].End) {
+				merged[n-1].End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// 指定時刻の営業状況と、次の開場・閉場時刻
+func (s *OpeningSchedule) StatusAt(t time.Time) OpenStatus {
+	status := OpenStatus{TimeZone: s.Location.String()}
+	intervals := s.intervalsBetween(t, scheduleLookaheadDays)
+	local := t.In(s.Location)
+	horizon := time.Date(local.Year(), local.Month(), local.Day()+scheduleLookaheadDays, 0, 0, 0, 0, s.Location)
+
+	for _, iv := range intervals {
+		if !t.Before(iv.Start) && t.Before(iv.End) {
+			status.IsOpen = true
+			if iv.End.Before(horizon) {
+				end := iv.End.In(s.Location)
+				status.ClosesAt = &end
+			}
+			return status
+		}
+		if iv.Start.After(t) {
+			start := iv.Start.In(s.Location)
+			status.OpensAt = &start
+			return status
+		}
+	}
+	return status
+}
+
+// 指定時刻の営業状況（手動で休業にしている場合は次の開場時刻も返さない）
+func (ts *TouristSpot) OpenStatusAt(t time.Time) OpenStatus {
+	schedule := ts.Schedule()
+	if !ts.IsOpen {
+		return OpenStatus{TimeZone: schedule.Location.String()}
+	}
+	return schedule.StatusAt(t)
+}
+
+// 指定日からの各日の営業時間（表示用）
+type ScheduleDay struct {
+	Date      string     `json:"date"`
+	Weekday   int        `json:"weekday"`
+	Exception bool       `json:"exception"`
+	Hours     []OpenSpan `json:"hours"`
+}
+
+type OpenSpan struct {
+	Open  time.Time `json:"open"`
+	Close time.Time `json:"close"`
+}
+
+// 指定日から days 日分の営業時間
+func (s *OpeningSchedule) Days(from time.Time, days int) []ScheduleDay {
+	local := from.In(s.Location)
+	result := make([]ScheduleDay, 0, days)
+	for i := 0; i < days; i++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, s.Location)
+		key := date.Format(scheduleDateLayout)
+		day := ScheduleDay{Date: key, Weekday: int(date.Weekday()), Hours: []OpenSpan{}}
+		for _, e := range s.Exceptions {
+			if key >= e.StartDate && key <= e.EndDate {
+				day.Exception = true
+			}
+		}
+		for _, iv := range s.intervalsOn(date.Year(), date.Month(), date.Day()) {
+			day.Hours = append(day.Hours, OpenSpan{Open: iv.Start, Close: iv.End})
+		}
+		result = append(result, day)
+	}
+	return result
+}
+
+// 営業時間スケジュールを読み込む条件（終了済みの例外は読み込まない）
+func preloadSpotSchedule(db *gorm.DB) *gorm.DB {
+	since := time.Now().AddDate(0, 0, -2).Format(scheduleDateLayout)
+	return db.Preload("OpeningHours", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("weekday ASC, open_time ASC")
+	}).Preload("ScheduleExceptions", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("end_date >= ?", since).Order("start_date ASC")
+	})
+}
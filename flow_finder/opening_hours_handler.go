@@ -0,0 +1,190 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 営業時間スケジュール関連のルートを登録
+func RegisterOpeningHoursRoutes(r *gin.Engine, db *gorm.DB) {
+	schedule := r.Group("/api/tourist-spots/:id/schedule")
+	{
+		// 営業時間・例外・現在の営業状況と、今日から ?days=7 日分の営業時間
+		schedule.GET("", getSpotScheduleHandler(db))
+
+		// タイムゾーンと曜日ごとの営業時間を置き換える（spot:edit）
+		schedule.PUT("", updateSpotScheduleHandler(db))
+
+		// 日付指定の例外を追加（spot:edit）
+		schedule.POST("/exceptions", createScheduleExceptionHandler(db))
+
+		// 日付指定の例外を削除（spot:edit）
+		schedule.DELETE("/exceptions/:exception_id", deleteScheduleExceptionHandler(db))
+	}
+}
+
+// 営業時間スケジュール取得ハンドラ
+func getSpotScheduleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := preloadSpotSchedule(db).First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+		if err != nil || days <= 0 || days > 60 {
+			days = 7
+		}
+
+		now := time.Now()
+		schedule := spot.Schedule()
+		c.JSON(http.StatusOK, gin.H{
+			"tourist_spot_id": spot.ID,
+			"time_zone":       schedule.Location.String(),
+			"is_open":         spot.IsOpen, // false の場合はスケジュールにかかわらず休業
+			"weekly":          spot.OpeningHours,
+			"exceptions":      spot.ScheduleExceptions,
+			"always_open":     schedule.AlwaysOpen,
+			"status":          spot.OpenStatusAt(now),
+			"days":            schedule.Days(now, days),
+		})
+	}
+}
+
+type spotScheduleRequest struct {
+	TimeZone *string `json:"time_zone"`
+	Weekly   *[]struct {
+		Weekday   int    `json:"weekday"`
+		OpenTime  string `json:"open_time"`
+		CloseTime string `json:"close_time"`
+	} `json:"weekly"`
+}
+
+// 営業時間スケジュール更新ハンドラ（weekly を指定した場合は全件置き換え、空配列で削除）
+func updateSpotScheduleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.Preload("OpeningHours").First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		before := gin.H{"time_zone": spot.TimeZone, "weekly": spot.OpeningHours}
+
+		var req spotScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if req.TimeZone != nil {
+			if _, err := loadSpotLocation(*req.TimeZone); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+				return
+			}
+			spot.TimeZone = *req.TimeZone
+		}
+		var weekly []SpotOpeningHours
+		if req.Weekly != nil {
+			weekly = make([]SpotOpeningHours, 0, len(*req.Weekly))
+			for _, w := range *req.Weekly {
+				hours := SpotOpeningHours{TouristSpotID: spot.ID, Weekday: w.Weekday, OpenTime: w.OpenTime, CloseTime: w.CloseTime}
+				if err := hours.Validate(); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+					return
+				}
+				weekly = append(weekly, hours)
+			}
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&TouristSpot{}).Where("id = ?", spot.ID).UpdateColumn("time_zone", spot.TimeZone).Error; err != nil {
+				return err
+			}
+			if req.Weekly == nil {
+				return nil
+			}
+			if err := tx.Where("tourist_spot_id = ?", spot.ID).Delete(&SpotOpeningHours{}).Error; err != nil {
+				return err
+			}
+			if len(weekly) == 0 {
+				return nil
+			}
+			return tx.Create(&weekly).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "schedule.update_failed")})
+			return
+		}
+		if req.Weekly != nil {
+			spot.OpeningHours = weekly
+		}
+
+		after := gin.H{"time_zone": spot.TimeZone, "weekly": spot.OpeningHours}
+		RecordChangeHistory(db, "tourist_spot_schedules", strconv.Itoa(int(spot.ID)), currentUserIDPtr(c), "update", before, after)
+		c.JSON(http.StatusOK, after)
+	}
+}
+
+// 例外追加ハンドラ
+func createScheduleExceptionHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.Select("id").First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		var req struct {
+			StartDate string `json:"start_date"`
+			EndDate   string `json:"end_date"`
+			Closed    bool   `json:"closed"`
+			OpenTime  string `json:"open_time"`
+			CloseTime string `json:"close_time"`
+			Note      string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		exception := SpotScheduleException{
+			TouristSpotID: spot.ID,
+			StartDate:     req.StartDate,
+			EndDate:       req.EndDate,
+			Closed:        req.Closed,
+			OpenTime:      req.OpenTime,
+			CloseTime:     req.CloseTime,
+			Note:          req.Note,
+		}
+		if err := exception.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if err := db.Create(&exception).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "schedule.exception_create_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "spot_schedule_exceptions", strconv.Itoa(int(exception.ID)), currentUserIDPtr(c), "create", nil, exception)
+		c.JSON(http.StatusCreated, exception)
+	}
+}
+
+// 例外削除ハンドラ
+func deleteScheduleExceptionHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var exception SpotScheduleException
+		if err := db.Where("id = ? AND tourist_spot_id = ?", c.Param("exception_id"), c.Param("id")).First(&exception).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "schedule.exception_not_found")})
+			return
+		}
+		if err := db.Delete(&exception).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "schedule.exception_delete_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "spot_schedule_exceptions", strconv.Itoa(int(exception.ID)), currentUserIDPtr(c), "delete", exception, nil)
+		c.JSON(http.StatusOK, gin.H{"message": "例外を削除しました"})
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+// 2025-06-06 は金曜日
+func TestOpeningScheduleIntervalsOn(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 6, day, hour, min, 0, 0, tokyo) }
+
+	tests := []struct {
+		name     string
+		schedule OpeningSchedule
+		day      int
+		want     []openInterval
+	}{
+		{
+			name:     "翌日にまたがる営業",
+			schedule: OpeningSchedule{Weekly: []SpotOpeningHours{{Weekday: 5, OpenTime: "22:00", CloseTime: "02:00"}}},
+			day:      6,
+			want:     []openInterval{{at(6, 22, 0), at(7, 2, 0)}},
+		},
+		{
+			name:     "別の曜日",
+			schedule: OpeningSchedule{Weekly: []SpotOpeningHours{{Weekday: 5, OpenTime: "22:00", CloseTime: "02:00"}}},
+			day:      7,
+		},
+		{
+			name: "休憩をはさんだ営業と24:00閉場",
+			schedule: OpeningSchedule{Weekly: []SpotOpeningHours{
+				{Weekday: 5, OpenTime: "09:00", CloseTime: "12:00"},
+				{Weekday: 5, OpenTime: "13:00", CloseTime: "24:00"},
+			}},
+			day:  6,
+			want: []openInterval{{at(6, 9, 0), at(6, 12, 0)}, {at(6, 13, 0), at(7, 0, 0)}},
+		},
+		{
+			name: "例外の営業時間が曜日の営業時間に優先する",
+			schedule: OpeningSchedule{
+				Weekly:     []SpotOpeningHours{{Weekday: 5, OpenTime: "09:00", CloseTime: "17:00"}},
+				Exceptions: []SpotScheduleException{{StartDate: "2025-06-06", EndDate: "2025-06-06", OpenTime: "10:00", CloseTime: "15:00"}},
+			},
+			day:  6,
+			want: []openInterval{{at(6, 10, 0), at(6, 15, 0)}},
+		},
+		{
+			name: "臨時休業",
+			schedule: OpeningSchedule{
+				Weekly:     []SpotOpeningHours{{Weekday: 5, OpenTime: "09:00", CloseTime: "17:00"}},
+				Exceptions: []SpotScheduleException{{StartDate: "2025-06-01", EndDate: "2025-06-30", Closed: true}},
+			},
+			day: 6,
+		},
+		{
+			name:     "終日営業",
+			schedule: OpeningSchedule{AlwaysOpen: true},
+			day:      6,
+			want:     []openInterval{{at(6, 0, 0), at(7, 0, 0)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.schedule.Location = tokyo
+			got := tt.schedule.intervalsOn(2025, time.June, tt.day)
+			assertIntervals(t, got, tt.want)
+		})
+	}
+}
+
+func TestOpeningScheduleIntervalsBetween(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 6, day, hour, min, 0, 0, tokyo) }
+
+	tests := []struct {
+		name     string
+		schedule OpeningSchedule
+		from     time.Time
+		days     int
+		want     []openInterval
+	}{
+		{
+			name:     "前日から続く営業を含める",
+			schedule: OpeningSchedule{Weekly: []SpotOpeningHours{{Weekday: 5, OpenTime: "22:00", CloseTime: "02:00"}}},
+			from:     at(7, 1, 0),
+			days:     0,
+			want:     []openInterval{{at(6, 22, 0), at(7, 2, 0)}},
+		},
+		{
+			name: "日付をまたいで連続する時間帯はまとめる",
+			schedule: OpeningSchedule{Weekly: []SpotOpeningHours{
+				{Weekday: 5, OpenTime: "20:00", CloseTime: "24:00"},
+				{Weekday: 6, OpenTime: "00:00", CloseTime: "03:00"},
+			}},
+			from: at(6, 12, 0),
+			days: 1,
+			want: []openInterval{{at(6, 20, 0), at(7, 3, 0)}},
+		},
+		{
+			name: "重なる時間帯はまとめる",
+			schedule: OpeningSchedule{Weekly: []SpotOpeningHours{
+				{Weekday: 5, OpenTime: "22:00", CloseTime: "06:00"},
+				{Weekday: 6, OpenTime: "05:00", CloseTime: "09:00"},
+			}},
+			from: at(6, 12, 0),
+			days: 1,
+			want: []openInterval{{at(6, 22, 0), at(7, 9, 0)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.schedule.Location = tokyo
+			assertIntervals(t, tt.schedule.intervalsBetween(tt.from, tt.days), tt.want)
+		})
+	}
+}
+
+func TestOpeningScheduleStatusAt(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	newYork := mustLoadLocation(t, "America/New_York")
+	jst := func(month time.Month, day, hour, min int) *time.Time {
+		v := time.Date(2025, month, day, hour, min, 0, 0, tokyo)
+		return &v
+	}
+	utc := func(month time.Month, day, hour, min int) *time.Time {
+		v := time.Date(2025, month, day, hour, min, 0, 0, time.UTC)
+		return &v
+	}
+	overnight := []SpotOpeningHours{{Weekday: 5, OpenTime: "22:00", CloseTime: "02:00"}} // 金曜 22:00〜土曜 02:00
+
+	tests := []struct {
+		name      string
+		schedule  OpeningSchedule
+		at        time.Time
+		wantOpen  bool
+		wantOpens *time.Time
+		wantClose *time.Time
+	}{
+		{
+			name:      "翌日にまたがる営業の開始後",
+			schedule:  OpeningSchedule{Location: tokyo, Weekly: overnight},
+			at:        *jst(time.June, 6, 23, 0),
+			wantOpen:  true,
+			wantClose: jst(time.June, 7, 2, 0),
+		},
+		{
+			name:      "翌日にまたがる営業の日付が変わった後",
+			schedule:  OpeningSchedule{Location: tokyo, Weekly: overnight},
+			at:        *jst(time.June, 7, 1, 30),
+			wantOpen:  true,
+			wantClose: jst(time.June, 7, 2, 0),
+		},
+		{
+			name:      "閉場時刻ちょうど",
+			schedule:  OpeningSchedule{Location: tokyo, Weekly: overnight},
+			at:        *jst(time.June, 7, 2, 0),
+			wantOpens: jst(time.June, 13, 22, 0),
+		},
+		{
+			// 例外は開始日の営業時間を置き換えるため、前日から続く営業には影響しない
+			name: "翌日が臨時休業でも前日からの営業は続く",
+			schedule: OpeningSchedule{Location: tokyo, Weekly: overnight, Exceptions: []SpotScheduleException{
+				{StartDate: "2025-06-07", EndDate: "2025-06-07", Closed: true},
+			}},
+			at:        *jst(time.June, 7, 1, 30),
+			wantOpen:  true,
+			wantClose: jst(time.June, 7, 2, 0),
+		},
+		{
+			name: "開始日が臨時休業なら日付が変わった後も休業",
+			schedule: OpeningSchedule{Location: tokyo, Weekly: overnight, Exceptions: []SpotScheduleException{
+				{StartDate: "2025-06-06", EndDate: "2025-06-06", Closed: true},
+			}},
+			at:        *jst(time.June, 7, 1, 30),
+			wantOpens: jst(time.June, 13, 22, 0),
+		},
+		{
+			name: "1週間の臨時休業をまたいで次の開場時刻を返す",
+			schedule: OpeningSchedule{Location: tokyo, Weekly: []SpotOpeningHours{{Weekday: 1, OpenTime: "10:00", CloseTime: "16:00"}}, Exceptions: []SpotScheduleException{
+				{StartDate: "2025-06-09", EndDate: "2025-06-15", Closed: true},
+			}},
+			at:        *jst(time.June, 7, 12, 0),
+			wantOpens: jst(time.June, 16, 10, 0),
+		},
+		{
+			name: "探索期間を超える休業",
+			schedule: OpeningSchedule{Location: tokyo, Weekly: []SpotOpeningHours{{Weekday: 1, OpenTime: "10:00", CloseTime: "16:00"}}, Exceptions: []SpotScheduleException{
+				{StartDate: "2025-06-08", EndDate: "2025-07-31", Closed: true},
+			}},
+			at: *jst(time.June, 7, 12, 0),
+		},
+		{
+			// 2025-03-09 02:00 に夏時間が始まる（EST → EDT）
+			name:      "夏時間の開始をまたぐ深夜営業",
+			schedule:  OpeningSchedule{Location: newYork, Weekly: []SpotOpeningHours{{Weekday: 6, OpenTime: "22:00", CloseTime: "04:00"}}},
+			at:        *utc(time.March, 9, 7, 30), // 03:30 EDT
+			wantOpen:  true,
+			wantClose: utc(time.March, 9, 8, 0), // 04:00 EDT
+		},
+		{
+			// 2025-11-02 02:00 に夏時間が終わる（EDT → EST）
+			name:      "夏時間の終了後の開場時刻",
+			schedule:  OpeningSchedule{Location: newYork, Weekly: []SpotOpeningHours{{Weekday: 0, OpenTime: "09:00", CloseTime: "17:00"}}},
+			at:        *utc(time.November, 2, 13, 30), // 08:30 EST
+			wantOpens: utc(time.November, 2, 14, 0),   // 09:00 EST
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schedule.StatusAt(tt.at)
+			if got.IsOpen != tt.wantOpen {
+				t.Errorf("is_open = %v, want %v", got.IsOpen, tt.wantOpen)
+			}
+			assertTimePtr(t, "opens_at", got.OpensAt, tt.wantOpens)
+			assertTimePtr(t, "closes_at", got.ClosesAt, tt.wantClose)
+			if got.TimeZone != tt.schedule.Location.String() {
+				t.Errorf("time_zone = %s, want %s", got.TimeZone, tt.schedule.Location)
+			}
+		})
+	}
+}
+
+func assertIntervals(t *testing.T, got, want []openInterval) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("intervals = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("intervals[%d] = %v〜%v, want %v〜%v", i, got[i].Start, got[i].End, want[i].Start, want[i].End)
+		}
+	}
+}
+
+func assertTimePtr(t *testing.T, name string, got, want *time.Time) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, want %v", name, got, want)
+	case !got.Equal(*want):
+		t.Errorf("%s = %v, want %v", name, *got, *want)
+	}
+}
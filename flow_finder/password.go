@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto/rand"
+	"math/big"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// パスワード長の制約（bcryptは72バイトまでしか扱えない）
+const (
+	MinPasswordLength = 8
+	MaxPasswordBytes  = 72
+)
+
+var (
+	ErrPasswordTooShort = newLocalizedError("password.too_short")
+	ErrPasswordTooLong  = newLocalizedError("password.too_long")
+)
+
+// ユーザーが存在しない場合でも比較処理を行い、応答時間からユーザーの有無を推測されないようにするためのハッシュ
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("flow-finder-dummy-password"), bcrypt.DefaultCost)
+
+// パスワードの強度を検証
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > MaxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+	return nil
+}
+
+// パスワードをbcryptでハッシュ化
+func HashPassword(password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// パスワードとハッシュを照合
+// hashが空の場合もダミーハッシュで比較を行い、常にfalseを返す
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// 管理者リセット用の一時パスワードを生成
+func GenerateTemporaryPassword(length int) (string, error) {
+	const charset = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = charset[n.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// バーチャル整理券
+// ユーザーは観光地の整理券を取り、順番が来たらスタッフが次の組を呼び出す。
+// 呼び出された整理券には戻ってくる時間帯（return window）が割り当てられ、その間に観光地でチェックインする。
+// 待っている人数と処理能力（1時間あたりの入場人数）から待ち時間を見積もり、TouristSpot.WaitTime に反映する
+const (
+	defaultQueueAvgVisitMinutes = 30 // 平均滞在時間（処理能力を許容人数から見積もる場合に使用）
+	defaultQueueWindowMinutes   = 15 // 戻ってくる時間帯の長さ
+	defaultQueueMaxPartySize    = 6  // 1枚の整理券で入場できる最大人数
+	defaultQueueThroughput      = 60 // 許容人数も処理能力も未設定の場合の1時間あたりの入場人数
+	queueCheckInGrace           = 5 * time.Minute
+)
+
+// 整理券の状態
+const (
+	QueueTicketWaiting   = "waiting"    // 順番待ち
+	QueueTicketCalled    = "called"     // 呼び出し済み（戻ってくる時間帯が確定）
+	QueueTicketCheckedIn = "checked_in" // 入場済み
+	QueueTicketCancelled = "cancelled"  // 取り消し
+	QueueTicketExpired   = "expired"    // 時間帯内にチェックインしなかった
+)
+
+// 観光地ごとの整理券の設定
+type SpotQueue struct {
+	TouristSpotID     uint      `gorm:"primaryKey" json:"tourist_spot_id"`
+	Enabled           bool      `json:"enabled"`
+	ThroughputPerHour int       `json:"throughput_per_hour"` // 1時間あたりの入場人数（0の場合は許容人数と平均滞在時間から見積もる）
+	AvgVisitMinutes   int       `json:"avg_visit_minutes"`   // 平均滞在時間（分）
+	WindowMinutes     int       `json:"window_minutes"`      // 戻ってくる時間帯の長さ（分）
+	BatchSize         int       `json:"batch_size"`          // 1回の呼び出しで案内する人数（0の場合は時間帯あたりの処理能力）
+	MaxPartySize      int       `json:"max_party_size"`
+	LastNumber        int       `json:"last_number"` // 最後に発行した整理番号
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// 整理券
+type QueueTicket struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TouristSpotID uint       `gorm:"not null;index:idx_queue_ticket_spot_status,priority:1" json:"tourist_spot_id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	Number        int        `gorm:"not null" json:"number"` // 観光地ごとの整理番号
+	PartySize     int        `gorm:"not null" json:"party_size"`
+	Status        string     `gorm:"not null;index:idx_queue_ticket_spot_status,priority:2" json:"status"`
+	ReturnStart   *time.Time `json:"return_start"` // 呼び出し時に確定する時間帯
+	ReturnEnd     *time.Time `json:"return_end"`
+	CalledAt      *time.Time `json:"called_at"`
+	CheckedInAt   *time.Time `json:"checked_in_at"`
+	CancelledAt   *time.Time `json:"cancelled_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// 整理券の状態と見積もり（レスポンス用）
+type QueueTicketView struct {
+	QueueTicket
+	TouristSpotName      string     `json:"tourist_spot_name"`
+	Position             int        `json:"position"`               // 何組目か（順番待ちの場合のみ）
+	PeopleAhead          int        `json:"people_ahead"`           // 前に並んでいる人数
+	EstimatedWaitMinutes int        `json:"estimated_wait_minutes"` // 呼び出しまでの見積もり
+	EstimatedReturnStart *time.Time `json:"estimated_return_start,omitempty"`
+	EstimatedReturnEnd   *time.Time `json:"estimated_return_end,omitempty"`
+}
+
+// 観光地の整理券の状況（公開用）
+type QueueSummary struct {
+	TouristSpotID        uint `json:"tourist_spot_id"`
+	Enabled              bool `json:"enabled"`
+	WaitingTickets       int  `json:"waiting_tickets"`
+	WaitingPeople        int  `json:"waiting_people"`
+	CalledTickets        int  `json:"called_tickets"`
+	NowServing           int  `json:"now_serving"` // 最後に呼び出した整理番号
+	ThroughputPerHour    int  `json:"throughput_per_hour"`
+	WindowMinutes        int  `json:"window_minutes"`
+	MaxPartySize         int  `json:"max_party_size"`
+	EstimatedWaitMinutes int  `json:"estimated_wait_minutes"` // 今整理券を取った場合の見積もり
+}
+
+// 整理券の操作が受け付けられない場合のエラー
+type QueueError struct {
+	Status int    // HTTPステータス
+	ID     string // メッセージID
+	Args   []interface{}
+}
+
+func newQueueError(status int, id string, args ...interface{}) *QueueError {
+	return &QueueError{Status: status, ID: id, Args: args}
+}
+
+func (e *QueueError) Error() string {
+	return Message(defaultLocale, e.ID, e.Args...)
+}
+
+// 未設定の項目を既定値で補う
+func (q *SpotQueue) applyDefaults() {
+	if q.AvgVisitMinutes <= 0 {
+		q.AvgVisitMinutes = defaultQueueAvgVisitMinutes
+	}
+	if q.WindowMinutes <= 0 {
+		q.WindowMinutes = defaultQueueWindowMinutes
+	}
+	if q.MaxPartySize <= 0 {
+		q.MaxPartySize = defaultQueueMaxPartySize
+	}
+}
+
+// 設定の検証
+func (q *SpotQueue) Validate() error {
+	if q.ThroughputPerHour < 0 || q.BatchSize < 0 {
+		return newLocalizedError("queue.throughput_invalid")
+	}
+	if q.AvgVisitMinutes > 24*60 || q.WindowMinutes > 24*60 {
+		return newLocalizedError("queue.minutes_invalid")
+	}
+	if q.MaxPartySize > 100 {
+		return newLocalizedError("queue.party_size_invalid")
+	}
+	return nil
+}
+
+// 1時間あたりの入場人数（設定がなければ許容人数 × 60 / 平均滞在時間）
+func (q *SpotQueue) Throughput(spot *TouristSpot) int {
+	if q.ThroughputPerHour > 0 {
+		return q.ThroughputPerHour
+	}
+	if spot.MaxCapacity > 0 {
+		return int(math.Max(1, math.Round(float64(spot.MaxCapacity)*60/float64(q.AvgVisitMinutes))))
+	}
+	return defaultQueueThroughput
+}
+
+// 1回の呼び出しで案内する人数（設定がなければ時間帯あたりの処理能力）
+func (q *SpotQueue) EffectiveBatchSize(spot *TouristSpot) int {
+	if q.BatchSize > 0 {
+		return q.BatchSize
+	}
+	return int(math.Max(1, math.Ceil(float64(q.Throughput(spot))*float64(q.WindowMinutes)/60)))
+}
+
+// 指定人数が前に並んでいる場合の待ち時間（分）
+func (q *SpotQueue) EstimateWaitMinutes(spot *TouristSpot, peopleAhead int) int {
+	return int(math.Ceil(float64(peopleAhead) * 60 / float64(q.Throughput(spot))))
+}
+
+// 観光地の整理券設定（未作成の場合は無効の既定値）
+func GetSpotQueue(db *gorm.DB, spotID uint) (*SpotQueue, error) {
+	queue := SpotQueue{TouristSpotID: spotID}
+	if err := db.Where("tourist_spot_id = ?", spotID).Limit(1).Find(&queue).Error; err != nil {
+		return nil, err
+	}
+	queue.applyDefaults()
+	return &queue, nil
+}
+
+// 時間帯を過ぎてもチェックインしなかった整理券を期限切れにする
+func expireQueueTickets(db *gorm.DB, spotID uint, now time.Time) error {
+	return db.Model(&QueueTicket{}).
+		Where("tourist_spot_id = ? AND status = ? AND return_end < ?", spotID, QueueTicketCalled, now.Add(-queueCheckInGrace)).
+		Update("status", QueueTicketExpired).Error
+}
+
+// 待っている人数から TouristSpot.WaitTime を更新する
+func refreshQueueWaitTime(db *gorm.DB, spot *TouristSpot, queue *SpotQueue) error {
+	var waitingPeople int64
+	if err := db.Model(&QueueTicket{}).Select("COALESCE(SUM(party_size), 0)").
+		Where("tourist_spot_id = ? AND status = ?", spot.ID, QueueTicketWaiting).Scan(&waitingPeople).Error; err != nil {
+		return err
+	}
+	waitTime := queue.EstimateWaitMinutes(spot, int(waitingPeople))
+	spot.WaitTime = waitTime
+	// 来場者数の更新日時（LastUpdated）は変えない
+	return db.Model(&TouristSpot{}).Where("id = ?", spot.ID).UpdateColumn("wait_time", waitTime).Error
+}
+
+// 観光地の整理券の状況
+func GetQueueSummary(db *gorm.DB, spot *TouristSpot) (*QueueSummary, error) {
+	queue, err := GetSpotQueue(db, spot.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := expireQueueTickets(db, spot.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		Status  string
+		Tickets int
+		People  int
+	}
+	if err := db.Model(&QueueTicket{}).
+		Select("status, COUNT(*) AS tickets, COALESCE(SUM(party_size), 0) AS people").
+		Where("tourist_spot_id = ? AND status IN ?", spot.ID, []string{QueueTicketWaiting, QueueTicketCalled}).
+		Group("status").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	summary := &QueueSummary{
+		TouristSpotID:     spot.ID,
+		Enabled:           queue.Enabled,
+		ThroughputPerHour: queue.Throughput(spot),
+		WindowMinutes:     queue.WindowMinutes,
+		MaxPartySize:      queue.MaxPartySize,
+	}
+	for _, c := range counts {
+		switch c.Status {
+		case QueueTicketWaiting:
+			summary.WaitingTickets, summary.WaitingPeople = c.Tickets, c.People
+		case QueueTicketCalled:
+			summary.CalledTickets = c.Tickets
+		}
+	}
+	db.Model(&QueueTicket{}).Select("COALESCE(MAX(number), 0)").
+		Where("tourist_spot_id = ? AND called_at IS NOT NULL", spot.ID).Scan(&summary.NowServing)
+	summary.EstimatedWaitMinutes = queue.EstimateWaitMinutes(spot, summary.WaitingPeople)
+	return summary, nil
+}
+
+// 整理券に順番と見積もりを付ける
+func NewQueueTicketView(db *gorm.DB, ticket *QueueTicket, now time.Time) (*QueueTicketView, error) {
+	var spot TouristSpot
+	if err := db.First(&spot, ticket.TouristSpotID).Error; err != nil {
+		return nil, err
+	}
+	view := &QueueTicketView{QueueTicket: *ticket, TouristSpotName: spot.Name}
+	if ticket.Status != QueueTicketWaiting {
+		return view, nil
+	}
+
+	queue, err := GetSpotQueue(db, spot.ID)
+	if err != nil {
+		return nil, err
+	}
+	var ahead struct {
+		Tickets int
+		People  int
+	}
+	if err := db.Model(&QueueTicket{}).
+		Select("COUNT(*) AS tickets, COALESCE(SUM(party_size), 0) AS people").
+		Where("tourist_spot_id = ? AND status = ? AND number < ?", spot.ID, QueueTicketWaiting, ticket.Number).
+		Scan(&ahead).Error; err != nil {
+		return nil, err
+	}
+	view.Position = ahead.Tickets + 1
+	view.PeopleAhead = ahead.People
+	view.EstimatedWaitMinutes = queue.EstimateWaitMinutes(&spot, ahead.People)
+	start := now.Add(time.Duration(view.EstimatedWaitMinutes) * time.Minute).Truncate(time.Minute)
+	end := start.Add(time.Duration(queue.WindowMinutes) * time.Minute)
+	view.EstimatedReturnStart, view.EstimatedReturnEnd = &start, &end
+	return view, nil
+}
+
+// 整理券を発行する（同じ観光地で有効な整理券は1人1枚まで）
+func TakeQueueTicket(db *gorm.DB, spotID, userID uint, partySize int) (*QueueTicket, error) {
+	var ticket QueueTicket
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var spot TouristSpot
+		if err := preloadSpotSchedule(tx).First(&spot, spotID).Error; err != nil {
+			return err
+		}
+		// 設定の行をロックして整理番号の採番を直列化する
+		var queue SpotQueue
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("tourist_spot_id = ?", spotID).First(&queue).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newQueueError(400, "queue.not_issuing")
+			}
+			return err
+		}
+		queue.applyDefaults()
+		if !queue.Enabled {
+			return newQueueError(400, "queue.not_issuing")
+		}
+		if !spot.IsCurrentlyOpen() {
+			return newQueueError(400, "queue.closed")
+		}
+		if partySize <= 0 {
+			partySize = 1
+		}
+		if partySize > queue.MaxPartySize {
+			return newQueueError(400, "queue.party_too_large", queue.MaxPartySize)
+		}
+
+		var active int64
+		if err := tx.Model(&QueueTicket{}).
+			Where("tourist_spot_id = ? AND user_id = ? AND status IN ?", spotID, userID, []string{QueueTicketWaiting, QueueTicketCalled}).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return newQueueError(409, "queue.already_taken")
+		}
+
+		queue.LastNumber++
+		if err := tx.Model(&queue).UpdateColumn("last_number", queue.LastNumber).Error; err != nil {
+			return err
+		}
+		ticket = QueueTicket{
+			TouristSpotID: spotID,
+			UserID:        userID,
+			Number:        queue.LastNumber,
+			PartySize:     partySize,
+			Status:        QueueTicketWaiting,
+		}
+		if err := tx.Create(&ticket).Error; err != nil {
+			return err
+		}
+		return refreshQueueWaitTime(tx, &spot, &queue)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// 整理券を取り消す（順番待ち・呼び出し済みのみ）
+func CancelQueueTicket(db *gorm.DB, ticket *QueueTicket) error {
+	now := time.Now()
+	result := db.Model(&QueueTicket{}).
+		Where("id = ? AND status IN ?", ticket.ID, []string{QueueTicketWaiting, QueueTicketCalled}).
+		Updates(map[string]interface{}{"status": QueueTicketCancelled, "cancelled_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return newQueueError(409, "queue.not_cancellable")
+	}
+	ticket.Status, ticket.CancelledAt = QueueTicketCancelled, &now
+	return refreshSpotQueueWaitTime(db, ticket.TouristSpotID)
+}
+
+// 観光地の待ち時間を再計算
+func refreshSpotQueueWaitTime(db *gorm.DB, spotID uint) error {
+	var spot TouristSpot
+	if err := db.First(&spot, spotID).Error; err != nil {
+		return err
+	}
+	queue, err := GetSpotQueue(db, spotID)
+	if err != nil || !queue.Enabled {
+		return err
+	}
+	return refreshQueueWaitTime(db, &spot, queue)
+}
+
+// 次の組を呼び出す（people を省略した場合は設定の人数）
+// 先頭から順に、合計人数が people を超えない範囲で呼び出す（先頭の1組は人数にかかわらず呼び出す）
+func CallNextQueueTickets(db *gorm.DB, spotID uint, people int) ([]QueueTicket, error) {
+	now := time.Now()
+	var called []QueueTicket
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var spot TouristSpot
+		if err := tx.First(&spot, spotID).Error; err != nil {
+			return err
+		}
+		queue, err := GetSpotQueue(tx, spotID)
+		if err != nil {
+			return err
+		}
+		if err := expireQueueTickets(tx, spotID, now); err != nil {
+			return err
+		}
+		if people <= 0 {
+			people = queue.EffectiveBatchSize(&spot)
+		}
+
+		// 複数の端末から同時に呼び出しても同じ整理券を二重に呼ばないよう SKIP LOCKED で確保する
+		var waiting []QueueTicket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("tourist_spot_id = ? AND status = ?", spotID, QueueTicketWaiting).
+			Order("number ASC").Limit(people).Find(&waiting).Error; err != nil {
+			return err
+		}
+
+		start := now
+		end := now.Add(time.Duration(queue.WindowMinutes) * time.Minute)
+		total := 0
+		var ids []uint
+		for _, t := range waiting {
+			if len(ids) > 0 && total+t.PartySize > people {
+				break
+			}
+			total += t.PartySize
+			ids = append(ids, t.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&QueueTicket{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       QueueTicketCalled,
+			"called_at":    now,
+			"return_start": start,
+			"return_end":   end,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Order("number ASC").Find(&called).Error; err != nil {
+			return err
+		}
+		return refreshQueueWaitTime(tx, &spot, queue)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return called, nil
+}
+
+// 観光地でチェックインする（呼び出し済みで、時間帯の終了から猶予時間内のもの）
+func CheckInQueueTicket(db *gorm.DB, ticket *QueueTicket) error {
+	now := time.Now()
+	if ticket.Status == QueueTicketWaiting {
+		return newQueueError(409, "queue.not_called")
+	}
+	if ticket.Status != QueueTicketCalled {
+		return newQueueError(409, "queue.cannot_check_in")
+	}
+	if ticket.ReturnStart != nil && now.Before(*ticket.ReturnStart) {
+		return newQueueError(409, "queue.too_early")
+	}
+	result := db.Model(&QueueTicket{}).
+		Where("id = ? AND status = ? AND return_end >= ?", ticket.ID, QueueTicketCalled, now.Add(-queueCheckInGrace)).
+		Updates(map[string]interface{}{"status": QueueTicketCheckedIn, "checked_in_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return newQueueError(409, "queue.too_late")
+	}
+	ticket.Status, ticket.CheckedInAt = QueueTicketCheckedIn, &now
+	return nil
+}
+
+// 整理券テーブルのマイグレーション（有効な整理券は1人1観光地1枚まで）
+func MigrateQueueTicket(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_queue_ticket_active_user
+		ON queue_tickets(tourist_spot_id, user_id) WHERE status IN ('waiting', 'called')
+	`).Error
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 整理券関連のルートを登録
+func RegisterQueueRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	spots := r.Group("/api/tourist-spots/:id/queue")
+	{
+		// 整理券の状況（待ち組数・見積もり）
+		spots.GET("", getQueueSummaryHandler(db))
+
+		// 整理券の設定（queue:manage）
+		spots.PUT("", updateSpotQueueHandler(db))
+
+		// 整理券を取る（ログインユーザー）
+		spots.POST("/tickets", takeQueueTicketHandler(db))
+
+		// 整理券一覧（queue:manage、?status=waiting）
+		spots.GET("/tickets", listSpotQueueTicketsHandler(db))
+
+		// 次の組を呼び出す（queue:manage、{"people": 10} で人数を指定）
+		spots.POST("/call-next", callNextQueueHandler(db))
+
+		// スタッフによるチェックイン（queue:manage、{"ticket_id": 1} または {"number": 12}）
+		spots.POST("/check-in", staffCheckInQueueHandler(db))
+	}
+
+	tickets := r.Group("/api/queue/tickets")
+	{
+		// 自分の有効な整理券
+		tickets.GET("/me", listMyQueueTicketsHandler(db))
+
+		// 整理券の詳細（順番と見積もり）
+		tickets.GET("/:id", getQueueTicketHandler(db))
+
+		// 整理券の取り消し
+		tickets.DELETE("/:id", cancelQueueTicketHandler(db))
+
+		// 観光地でのチェックイン（呼び出し後の時間帯内）
+		tickets.POST("/:id/check-in", checkInQueueTicketHandler(db))
+	}
+}
+
+// 整理券の操作エラーをレスポンスに変換
+func respondQueueError(c *gin.Context, err error, fallback string) {
+	var queueErr *QueueError
+	if errors.As(err, &queueErr) {
+		c.JSON(queueErr.Status, gin.H{"error": T(c, queueErr.ID, queueErr.Args...)})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+		return
+	}
+	fmt.Printf("⚠️ %s: %v\n", Message(defaultLocale, fallback), err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, fallback)})
+}
+
+// 自分の整理券を取得（本人以外は queue:manage を持つ場合のみ）
+func loadQueueTicket(c *gin.Context, db *gorm.DB) (*QueueTicket, bool) {
+	userID, exists := GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+		return nil, false
+	}
+	var ticket QueueTicket
+	if err := db.First(&ticket, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": T(c, "queue.ticket_not_found")})
+		return nil, false
+	}
+	if ticket.UserID != userID {
+		principal := principalFromContext(c)
+		if principal == nil || !principal.HasPermission(PermQueueManage) || !principal.CanAccessSpot(ticket.TouristSpotID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "queue.ticket_not_found")})
+			return nil, false
+		}
+	}
+	return &ticket, true
+}
+
+// 整理券の状況取得ハンドラ
+func getQueueSummaryHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		summary, err := GetQueueSummary(db, &spot)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.summary_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, summary)
+	}
+}
+
+type spotQueueRequest struct {
+	Enabled           *bool `json:"enabled"`
+	ThroughputPerHour *int  `json:"throughput_per_hour"`
+	AvgVisitMinutes   *int  `json:"avg_visit_minutes"`
+	WindowMinutes     *int  `json:"window_minutes"`
+	BatchSize         *int  `json:"batch_size"`
+	MaxPartySize      *int  `json:"max_party_size"`
+}
+
+// 整理券の設定更新ハンドラ（未作成の場合は作成）
+func updateSpotQueueHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		var req spotQueueRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		var queue SpotQueue
+		created := false
+		if err := db.Where("tourist_spot_id = ?", spot.ID).First(&queue).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.settings_fetch_failed")})
+				return
+			}
+			queue = SpotQueue{TouristSpotID: spot.ID}
+			created = true
+		}
+		before := queue
+
+		if req.Enabled != nil {
+			queue.Enabled = *req.Enabled
+		}
+		if req.ThroughputPerHour != nil {
+			queue.ThroughputPerHour = *req.ThroughputPerHour
+		}
+		if req.AvgVisitMinutes != nil {
+			queue.AvgVisitMinutes = *req.AvgVisitMinutes
+		}
+		if req.WindowMinutes != nil {
+			queue.WindowMinutes = *req.WindowMinutes
+		}
+		if req.BatchSize != nil {
+			queue.BatchSize = *req.BatchSize
+		}
+		if req.MaxPartySize != nil {
+			queue.MaxPartySize = *req.MaxPartySize
+		}
+		queue.applyDefaults()
+		if err := queue.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if created {
+				if err := tx.Create(&queue).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Model(&queue).Select("enabled", "throughput_per_hour", "avg_visit_minutes", "window_minutes", "batch_size", "max_party_size").Updates(&queue).Error; err != nil {
+				return err
+			}
+			if !queue.Enabled {
+				// 整理券を止めた場合、待ち時間は手入力に戻す（既存の整理券はそのまま）
+				return nil
+			}
+			return refreshQueueWaitTime(tx, &spot, &queue)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.settings_update_failed")})
+			return
+		}
+
+		action := "update"
+		var beforeValue interface{} = before
+		if created {
+			action, beforeValue = "create", nil
+		}
+		RecordChangeHistory(db, "spot_queues", strconv.Itoa(int(spot.ID)), currentUserIDPtr(c), action, beforeValue, queue)
+		c.JSON(http.StatusOK, queue)
+	}
+}
+
+// 整理券発行ハンドラ
+func takeQueueTicketHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+			return
+		}
+		spotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_id")})
+			return
+		}
+		var req struct {
+			PartySize int `json:"party_size"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+				return
+			}
+		}
+
+		ticket, err := TakeQueueTicket(db, uint(spotID), userID, req.PartySize)
+		if err != nil {
+			respondQueueError(c, err, "queue.issue_failed")
+			return
+		}
+		view, err := NewQueueTicketView(db, ticket, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.ticket_fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusCreated, view)
+	}
+}
+
+// 観光地の整理券一覧ハンドラ（番号順）
+func listSpotQueueTicketsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		if err := expireQueueTickets(db, spot.ID, time.Now()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.ticket_fetch_failed")})
+			return
+		}
+
+		query := db.Where("tourist_spot_id = ?", spot.ID).Order("number ASC")
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		} else {
+			query = query.Where("status IN ?", []string{QueueTicketWaiting, QueueTicketCalled})
+		}
+		var tickets []QueueTicket
+		if err := query.Limit(1000).Find(&tickets).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.ticket_fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, tickets)
+	}
+}
+
+// 次の組の呼び出しハンドラ
+func callNextQueueHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_id")})
+			return
+		}
+		var req struct {
+			People int `json:"people"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+				return
+			}
+		}
+
+		called, err := CallNextQueueTickets(db, uint(spotID), req.People)
+		if err != nil {
+			respondQueueError(c, err, "queue.call_failed")
+			return
+		}
+		if len(called) > 0 {
+			RecordChangeHistory(db, "queue_tickets", strconv.Itoa(int(spotID)), currentUserIDPtr(c), "call", nil, called)
+		}
+		c.JSON(http.StatusOK, gin.H{"called": called})
+	}
+}
+
+// スタッフによるチェックインハンドラ（整理券の画面を確認して入場させる）
+func staffCheckInQueueHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			TicketID uint `json:"ticket_id"`
+			Number   int  `json:"number"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || (req.TicketID == 0 && req.Number == 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "queue.ticket_or_number_required")})
+			return
+		}
+
+		query := db.Where("tourist_spot_id = ?", c.Param("id"))
+		if req.TicketID != 0 {
+			query = query.Where("id = ?", req.TicketID)
+		} else {
+			query = query.Where("number = ?", req.Number)
+		}
+		var ticket QueueTicket
+		if err := query.First(&ticket).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "queue.ticket_not_found")})
+			return
+		}
+		if err := CheckInQueueTicket(db, &ticket); err != nil {
+			respondQueueError(c, err, "queue.check_in_failed")
+			return
+		}
+		RecordChangeHistory(db, "queue_tickets", strconv.Itoa(int(ticket.ID)), currentUserIDPtr(c), "check_in", nil, ticket)
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// 自分の有効な整理券一覧ハンドラ
+func listMyQueueTicketsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+			return
+		}
+		var tickets []QueueTicket
+		if err := db.Where("user_id = ? AND status IN ?", userID, []string{QueueTicketWaiting, QueueTicketCalled}).
+			Order("created_at ASC").Find(&tickets).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.ticket_fetch_failed")})
+			return
+		}
+
+		now := time.Now()
+		views := make([]*QueueTicketView, 0, len(tickets))
+		for i := range tickets {
+			view, err := NewQueueTicketView(db, &tickets[i], now)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.ticket_fetch_failed")})
+				return
+			}
+			views = append(views, view)
+		}
+		c.JSON(http.StatusOK, views)
+	}
+}
+
+// 整理券詳細ハンドラ
+func getQueueTicketHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, ok := loadQueueTicket(c, db)
+		if !ok {
+			return
+		}
+		if err := expireQueueTickets(db, ticket.TouristSpotID, time.Now()); err == nil {
+			db.First(ticket, ticket.ID)
+		}
+		view, err := NewQueueTicketView(db, ticket, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "queue.ticket_fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+// 整理券取り消しハンドラ
+func cancelQueueTicketHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, ok := loadQueueTicket(c, db)
+		if !ok {
+			return
+		}
+		if err := CancelQueueTicket(db, ticket); err != nil {
+			respondQueueError(c, err, "queue.cancel_failed")
+			return
+		}
+		c.JSON(http.StatusOK, ticket)
+	}
+}
+
+// チェックインハンドラ（利用者が観光地で操作する）
+func checkInQueueTicketHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, ok := loadQueueTicket(c, db)
+		if !ok {
+			return
+		}
+		if err := CheckInQueueTicket(db, ticket); err != nil {
+			respondQueueError(c, err, "queue.check_in_failed")
+			return
+		}
+		c.JSON(http.StatusOK, ticket)
+	}
+}
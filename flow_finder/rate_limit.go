@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// レート制限の単位
+const (
+	RateLimitByIP       = "ip"       // クライアントIPごと
+	RateLimitByIdentity = "identity" // APIキー → ユーザー → IP の順で識別
+)
+
+// レート制限ポリシー（スライディングウィンドウ）
+type RateLimitPolicy struct {
+	Limit  int           // ウィンドウ内で許可するリクエスト数
+	Window time.Duration // ウィンドウの長さ
+	By     string        // RateLimitByIP | RateLimitByIdentity
+}
+
+// 名前付きのレート制限ポリシー
+var rateLimitPolicies = map[string]RateLimitPolicy{
+	"default":       {Limit: 300, Window: time.Minute, By: RateLimitByIdentity},
+	"login":         {Limit: 20, Window: 15 * time.Minute, By: RateLimitByIP},
+	"token_refresh": {Limit: 60, Window: 15 * time.Minute, By: RateLimitByIP},
+	"register":      {Limit: 10, Window: time.Hour, By: RateLimitByIP},
+	"guest_create":  {Limit: 20, Window: time.Hour, By: RateLimitByIP},
+	"log_ingest":    {Limit: 120, Window: time.Minute, By: RateLimitByIdentity},
+	"route_calc":    {Limit: 30, Window: time.Minute, By: RateLimitByIdentity},
+	"sensor_write":  {Limit: 600, Window: time.Minute, By: RateLimitByIdentity},
+	"search":        {Limit: 60, Window: time.Minute, By: RateLimitByIdentity},
+	"review_write":  {Limit: 10, Window: time.Hour, By: RateLimitByIdentity},
+	"auth_failure":  {Limit: 50, Window: 15 * time.Minute, By: RateLimitByIP},
+}
+
+// 無効なトークン・APIキーを数えるポリシー（認証の前に確認するため RoutePolicyMiddleware から使う）
+const authFailurePolicy = "auth_failure"
+
+// ルートごとに適用するポリシー名（記載のないルートは "default"）
+var routeRateLimits = map[string]string{
+	"POST /api/login":                        "login",
+	"GET /api/auth/oidc/login":               "login",
+	"GET /api/auth/oidc/callback":            "login",
+	"POST /api/token/refresh":                "token_refresh",
+	"POST /api/users":                        "register",
+	"POST /api/guests":                       "guest_create",
+	"POST /api/logs":                         "log_ingest",
+	"POST /api/dijkstra":                     "route_calc",
+	"POST /api/tourist-spots/route":          "route_calc",
+	"POST /api/debug/distance":               "route_calc",
+	"POST /api/tourist-spots/:id/visitors":   "sensor_write",
+	"POST /api/tourist-spots/:id/congestion": "sensor_write",
+	"POST /api/sensors/events":               "sensor_write",
+	"GET /api/search":                        "search",
+	"POST /api/tourist-spots/:id/reviews":    "review_write",
+	"PUT /api/reviews/:id":                   "review_write",
+	"POST /api/reviews/:id/flag":             "review_write",
+}
+
+const rateLimitOffenderTTL = 48 * time.Hour
+
+func rateLimitKey(policy, identity string) string {
+	return fmt.Sprintf("ratelimit:%s:%s", policy, identity)
+}
+
+// 拒否回数の集計キー（1時間単位）
+func rateLimitOffendersKey(t time.Time) string {
+	return "ratelimit:offenders:" + t.UTC().Format("2006010215")
+}
+
+// スライディングウィンドウ（リクエスト時刻のソート済みセット）を原子的に判定・記録する
+// 戻り値: {許可(1/0), ウィンドウ内の件数, 最古のリクエストが外れるまでのミリ秒}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// レート制限の判定結果
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration // ウィンドウに空きができるまでの時間
+}
+
+// 1リクエスト分を記録して許可されるか判定する
+func AllowRequest(ctx context.Context, client *redis.Client, name string, policy RateLimitPolicy, identity string) (*RateLimitResult, error) {
+	now := time.Now()
+	nonce, err := GenerateToken(4)
+	if err != nil {
+		return nil, err
+	}
+	member := strconv.FormatInt(now.UnixNano(), 10) + "-" + nonce
+
+	res, err := slidingWindowScript.Run(ctx, client, []string{rateLimitKey(name, identity)},
+		now.UnixMilli(), policy.Window.Milliseconds(), policy.Limit, member).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	result := &RateLimitResult{
+		Allowed: res[0] == 1,
+		Limit:   policy.Limit,
+		Reset:   time.Duration(res[2]) * time.Millisecond,
+	}
+	if remaining := policy.Limit - int(res[1]); remaining > 0 {
+		result.Remaining = remaining
+	}
+	return result, nil
+}
+
+// IPの認証失敗が上限に達しているか確認する（記録はしない）
+// 上限に達していない場合はnilを返す
+func CheckAuthFailureLimit(ctx context.Context, client *redis.Client, ip string) (*RateLimitResult, error) {
+	policy := rateLimitPolicies[authFailurePolicy]
+	key := rateLimitKey(authFailurePolicy, "ip:"+ip)
+	now := time.Now().UnixMilli()
+
+	pipe := client.Pipeline()
+	count := pipe.ZCount(ctx, key, "("+strconv.FormatInt(now-policy.Window.Milliseconds(), 10), "+inf")
+	oldest := pipe.ZRangeWithScores(ctx, key, 0, 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if int(count.Val()) < policy.Limit {
+		return nil, nil
+	}
+	reset := policy.Window
+	if entries := oldest.Val(); len(entries) > 0 {
+		reset = time.Duration(int64(entries[0].Score)+policy.Window.Milliseconds()-now) * time.Millisecond
+	}
+	return &RateLimitResult{Limit: policy.Limit, Reset: reset}, nil
+}
+
+// 認証失敗を1回記録する（上限を超えた分は違反として集計する）
+func RecordAuthFailure(ctx context.Context, client *redis.Client, ip string) {
+	result, err := AllowRequest(ctx, client, authFailurePolicy, rateLimitPolicies[authFailurePolicy], "ip:"+ip)
+	if err != nil {
+		fmt.Printf("⚠️ 認証失敗の記録に失敗: %v\n", err)
+		return
+	}
+	if !result.Allowed {
+		recordRateLimitOffender(client, authFailurePolicy, "ip:"+ip)
+	}
+}
+
+// レート制限の識別子を決める（APIキー・ユーザーは認証ミドルウェアの結果を利用）
+func rateLimitIdentity(c *gin.Context, by string) string {
+	if by == RateLimitByIdentity {
+		if p := principalFromContext(c); p != nil {
+			if p.IsAPIKey() {
+				return fmt.Sprintf("key:%d", p.APIKeyID)
+			}
+			return fmt.Sprintf("user:%d", p.UserID)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ルートに適用されるポリシー名
+func rateLimitPolicyName(method, path string) string {
+	if name, ok := routeRateLimits[routePolicyKey(method, path)]; ok {
+		return name
+	}
+	return "default"
+}
+
+// ポリシー表に従ってレート制限を行うミドルウェア
+// APIキー・ユーザー単位で数えるため RoutePolicyMiddleware の後に登録する
+// （認証に失敗したリクエストはここまで届かないため、RoutePolicyMiddleware が auth_failure ポリシーでIPごとに数える）
+func RateLimitMiddleware(redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" || redisClient == nil {
+			c.Next()
+			return
+		}
+
+		name := rateLimitPolicyName(c.Request.Method, path)
+		policy := rateLimitPolicies[name]
+		identity := rateLimitIdentity(c, policy.By)
+
+		result, err := AllowRequest(c.Request.Context(), redisClient, name, policy, identity)
+		if err != nil {
+			// Redis障害時はAPI全体を止めないよう制限なしで通す
+			fmt.Printf("⚠️ レート制限の判定に失敗: %v\n", err)
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, policy, result)
+		if !result.Allowed {
+			recordRateLimitOffender(redisClient, name, identity)
+			abortRateLimited(c, name, result)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimit-* ヘッダーを設定
+func setRateLimitHeaders(c *gin.Context, policy RateLimitPolicy, result *RateLimitResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+}
+
+// 429とRetry-Afterを返して処理を中断
+func abortRateLimited(c *gin.Context, name string, result *RateLimitResult) {
+	resetSeconds := int(math.Ceil(result.Reset.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(resetSeconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       T(c, "common.too_many_requests"),
+		"message":     T(c, "common.try_later"),
+		"policy":      name,
+		"retry_after": resetSeconds,
+	})
+}
+
+// 拒否されたリクエストを集計する（管理画面の上位違反者表示用）
+func recordRateLimitOffender(redisClient *redis.Client, policy, identity string) {
+	ctx := context.Background()
+	key := rateLimitOffendersKey(time.Now())
+	pipe := redisClient.Pipeline()
+	pipe.ZIncrBy(ctx, key, 1, policy+"|"+identity)
+	pipe.Expire(ctx, key, rateLimitOffenderTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		fmt.Printf("⚠️ レート制限違反の記録に失敗: %v\n", err)
+	}
+}
+
+// レート制限の違反者
+type RateLimitOffender struct {
+	Policy   string `json:"policy"`
+	Identity string `json:"identity"`
+	Rejected int64  `json:"rejected"`
+}
+
+// 直近の時間帯で拒否回数の多い識別子を取得
+func TopRateLimitOffenders(ctx context.Context, client *redis.Client, hours, limit int) ([]RateLimitOffender, error) {
+	totals := map[string]float64{}
+	now := time.Now()
+	for i := 0; i < hours; i++ {
+		entries, err := client.ZRangeWithScores(ctx, rateLimitOffendersKey(now.Add(-time.Duration(i)*time.Hour)), 0, -1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			totals[e.Member.(string)] += e.Score
+		}
+	}
+
+	offenders := make([]RateLimitOffender, 0, len(totals))
+	for member, score := range totals {
+		policy, identity, _ := strings.Cut(member, "|")
+		offenders = append(offenders, RateLimitOffender{Policy: policy, Identity: identity, Rejected: int64(score)})
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].Rejected != offenders[j].Rejected {
+			return offenders[i].Rejected > offenders[j].Rejected
+		}
+		return offenders[i].Identity < offenders[j].Identity
+	})
+	if len(offenders) > limit {
+		offenders = offenders[:limit]
+	}
+	return offenders, nil
+}
+
+// レート制限の設定を確認（ルート・ポリシー名の誤記を起動時に検出）
+func CheckRateLimitPolicies() error {
+	for _, name := range []string{"default", authFailurePolicy} {
+		if _, ok := rateLimitPolicies[name]; !ok {
+			return fmt.Errorf("%sのレート制限ポリシーがありません", name)
+		}
+	}
+	for route, name := range routeRateLimits {
+		if _, ok := rateLimitPolicies[name]; !ok {
+			return fmt.Errorf("未定義のレート制限ポリシーです: %s (%s)", name, route)
+		}
+		if _, ok := routePolicies[route]; !ok {
+			return fmt.Errorf("レート制限の対象ルートが存在しません: %s", route)
+		}
+	}
+	return nil
+}
+
+// レート制限の管理用ルートを登録
+func RegisterRateLimitRoutes(r *gin.Engine, redisClient *redis.Client) {
+	// ポリシー一覧
+	r.GET("/api/admin/rate-limits/policies", func(c *gin.Context) {
+		policies := gin.H{}
+		for name, p := range rateLimitPolicies {
+			policies[name] = gin.H{"limit": p.Limit, "window_seconds": int(p.Window.Seconds()), "by": p.By}
+		}
+		c.JSON(http.StatusOK, gin.H{"policies": policies, "routes": routeRateLimits})
+	})
+
+	// 拒否回数の多い識別子（?hours=24&limit=20）
+	r.GET("/api/admin/rate-limits/offenders", func(c *gin.Context) {
+		hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+		if err != nil || hours < 1 || hours > int(rateLimitOffenderTTL.Hours()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.hours_range", int(rateLimitOffenderTTL.Hours()))})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit < 1 {
+			limit = 20
+		}
+
+		offenders, err := TopRateLimitOffenders(c.Request.Context(), redisClient, hours, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"hours": hours, "offenders": offenders})
+	})
+}
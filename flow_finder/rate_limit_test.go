@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 無効なトークンを繰り返すIPは、認証の前に429で止める
+func TestAuthFailureLimit(t *testing.T) {
+	redisClient := openTestRedis(t)
+
+	ip := fmt.Sprintf("198.51.100.%d", time.Now().UnixNano()%250+1)
+	key := rateLimitKey(authFailurePolicy, "ip:"+ip)
+	redisClient.Del(context.Background(), key)
+	t.Cleanup(func() { redisClient.Del(context.Background(), key) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/required", Authenticate(nil, redisClient, AuthOptions{Required: true}), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/optional", Authenticate(nil, redisClient, AuthOptions{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":12345"
+		req.Header.Set("Authorization", "Bearer invalid-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	limit := rateLimitPolicies[authFailurePolicy].Limit
+	for i := 0; i < limit; i++ {
+		if w := get("/required"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	w := get("/required")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After がない")
+	}
+
+	// 任意認証のルートは未ログインとして通す
+	if w := get("/optional"); w.Code != http.StatusOK {
+		t.Errorf("optional status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
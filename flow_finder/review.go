@@ -0,0 +1,451 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 観光地のレビュー
+// ログインユーザー（ゲストを除く）は観光地ごとに1件、星1-5の評価と本文・写真（アップロード済みの画像）を投稿できる。
+// 公開中のレビューだけを集計して TouristSpot.Rating / ReviewCount に反映する。
+// スパムの疑いがあるレビューや一定数の通報を受けたレビューは確認待ちとなり、モデレーターが公開または非表示を決める
+const (
+	reviewMaxTextLength     = 2000 // 本文の最大文字数
+	reviewFlagThreshold     = 3    // 確認待ちにする未対応の通報数
+	reviewMaxLinks          = 1    // 本文に含められるURLの数
+	reviewBurstLimit        = 5    // reviewBurstWindow 内に投稿できるレビュー数
+	reviewBurstWindow       = 10 * time.Minute
+	reviewRepeatedRuneLimit = 10 // 同じ文字がこの数以上続く場合はスパムの疑い
+)
+
+// レビューの状態
+const (
+	ReviewPublished = "published" // 公開中（評価の集計対象）
+	ReviewPending   = "pending"   // 確認待ち（スパムの疑い・通報多数）
+	ReviewHidden    = "hidden"    // モデレーターが非表示にした
+)
+
+// スパム判定の理由
+const (
+	ReviewSpamLinks     = "links"     // URLが多い
+	ReviewSpamRepeated  = "repeated"  // 同じ文字の連続
+	ReviewSpamDuplicate = "duplicate" // 他の観光地と同じ本文
+	ReviewSpamBurst     = "burst"     // 短時間の連続投稿
+	ReviewSpamFlagged   = "flagged"   // 通報数が閾値に達した
+)
+
+// レビュー
+type Review struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	TouristSpotID  uint       `gorm:"not null;uniqueIndex:idx_review_spot_user,priority:1;index:idx_review_spot_status,priority:1" json:"tourist_spot_id"`
+	UserID         uint       `gorm:"not null;uniqueIndex:idx_review_spot_user,priority:2;index" json:"user_id"`
+	Rating         int        `gorm:"not null" json:"rating"` // 星の数（1-5）
+	Text           string     `gorm:"type:text" json:"text"`
+	ImageID        *uint      `gorm:"index" json:"image_id"` // 写真（/api/upload でアップロードした画像）
+	Status         string     `gorm:"not null;index:idx_review_spot_status,priority:2" json:"status"`
+	SpamReason     string     `json:"spam_reason,omitempty"`                // 確認待ちになった理由
+	FlagCount      int        `gorm:"not null;default:0" json:"flag_count"` // 未対応の通報数
+	ModerationNote string     `json:"moderation_note,omitempty"`
+	ModeratedBy    *uint      `json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	TouristSpot *TouristSpot `gorm:"foreignKey:TouristSpotID;constraint:OnDelete:CASCADE" json:"-"`
+	User        *User        `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Image       *Image       `gorm:"foreignKey:ImageID;constraint:OnDelete:SET NULL" json:"-"`
+	UserName    string       `gorm:"-" json:"user_name"`           // 投稿者の表示名
+	ImageURL    string       `gorm:"-" json:"image_url,omitempty"` // 写真のURL
+}
+
+// レビューへの通報
+type ReviewFlag struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ReviewID   uint       `gorm:"not null;uniqueIndex:idx_review_flag_user,priority:1" json:"review_id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_review_flag_user,priority:2" json:"user_id"`
+	Reason     string     `json:"reason"`
+	ResolvedAt *time.Time `json:"resolved_at"` // モデレーターが対応した日時
+	CreatedAt  time.Time  `json:"created_at"`
+
+	Review *Review `gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// 観光地の評価の集計（レスポンス用）
+type ReviewSummary struct {
+	TouristSpotID uint        `json:"tourist_spot_id"`
+	Rating        float32     `json:"rating"`
+	ReviewCount   int         `json:"review_count"`
+	Distribution  map[int]int `json:"distribution"` // 星の数ごとの件数
+}
+
+// レビュー操作のエラー（HTTPステータス付き）
+type ReviewError struct {
+	Status int
+	ID     string // メッセージID
+	Args   []interface{}
+}
+
+func newReviewError(status int, id string, args ...interface{}) *ReviewError {
+	return &ReviewError{Status: status, ID: id, Args: args}
+}
+
+func (e *ReviewError) Error() string {
+	return Message(defaultLocale, e.ID, e.Args...)
+}
+
+// 投稿・編集の内容
+type ReviewInput struct {
+	Rating  int
+	Text    string
+	ImageID *uint
+}
+
+var reviewURLPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// 入力内容を検証する
+func (in *ReviewInput) Validate(db *gorm.DB) error {
+	if in.Rating < 1 || in.Rating > 5 {
+		return newReviewError(400, "review.rating_invalid")
+	}
+	in.Text = strings.TrimSpace(in.Text)
+	if utf8.RuneCountInString(in.Text) > reviewMaxTextLength {
+		return newReviewError(400, "review.text_too_long", reviewMaxTextLength)
+	}
+	if in.ImageID != nil {
+		var count int64
+		if err := db.Model(&Image{}).Where("id = ?", *in.ImageID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return newReviewError(400, "review.image_not_found")
+		}
+	}
+	return nil
+}
+
+// 同じ文字が続く最大の長さ
+func longestRuneRun(s string) int {
+	longest, run := 0, 0
+	var prev rune
+	for i, r := range []rune(s) {
+		if i > 0 && r == prev {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// スパムの疑いがあれば理由を返す（疑いがなければ空文字）
+func detectReviewSpam(db *gorm.DB, review *Review) (string, error) {
+	if len(reviewURLPattern.FindAllStringIndex(review.Text, -1)) > reviewMaxLinks {
+		return ReviewSpamLinks, nil
+	}
+	if longestRuneRun(review.Text) >= reviewRepeatedRuneLimit {
+		return ReviewSpamRepeated, nil
+	}
+	if review.Text != "" {
+		var duplicates int64
+		if err := db.Model(&Review{}).
+			Where("user_id = ? AND tourist_spot_id <> ? AND text = ?", review.UserID, review.TouristSpotID, review.Text).
+			Count(&duplicates).Error; err != nil {
+			return "", err
+		}
+		if duplicates > 0 {
+			return ReviewSpamDuplicate, nil
+		}
+	}
+	var recent int64
+	if err := db.Model(&Review{}).
+		Where("user_id = ? AND id <> ? AND updated_at > ?", review.UserID, review.ID, time.Now().Add(-reviewBurstWindow)).
+		Count(&recent).Error; err != nil {
+		return "", err
+	}
+	if recent >= reviewBurstLimit {
+		return ReviewSpamBurst, nil
+	}
+	return "", nil
+}
+
+// 投稿・編集後の状態を決める（非表示・通報多数のレビューは編集しても状態を変えない）
+func (r *Review) applySpamCheck(db *gorm.DB) error {
+	if r.Status == ReviewHidden || r.SpamReason == ReviewSpamFlagged {
+		return nil
+	}
+	reason, err := detectReviewSpam(db, r)
+	if err != nil {
+		return err
+	}
+	r.SpamReason = reason
+	if reason != "" {
+		r.Status = ReviewPending
+	} else {
+		r.Status = ReviewPublished
+	}
+	return nil
+}
+
+// 投稿者名と写真のURLを設定する
+func fillReviewDetails(db *gorm.DB, reviews []Review) {
+	userIDs := make([]uint, 0, len(reviews))
+	imageIDs := make([]uint, 0, len(reviews))
+	for _, r := range reviews {
+		userIDs = append(userIDs, r.UserID)
+		if r.ImageID != nil {
+			imageIDs = append(imageIDs, *r.ImageID)
+		}
+	}
+	names := make(map[uint]string)
+	if len(userIDs) > 0 {
+		var users []User
+		db.Select("id", "name").Where("id IN ?", userIDs).Find(&users)
+		for _, u := range users {
+			names[u.ID] = u.Name
+		}
+	}
+	files := make(map[uint]string)
+	if len(imageIDs) > 0 {
+		var images []Image
+		db.Select("id", "file_name").Where("id IN ?", imageIDs).Find(&images)
+		for _, img := range images {
+			files[img.ID] = img.FileName
+		}
+	}
+	for i := range reviews {
+		reviews[i].UserName = names[reviews[i].UserID]
+		reviews[i].ImageURL = ""
+		if reviews[i].ImageID != nil {
+			if name, ok := files[*reviews[i].ImageID]; ok {
+				reviews[i].ImageURL = fmt.Sprintf("/uploads/%s", name)
+			}
+		}
+	}
+}
+
+// 公開中のレビューから観光地の評価とレビュー数を再計算する（呼び出し元のトランザクション内で実行）
+// 観光地の行をロックして、同じ観光地への同時更新でも集計がずれないようにする
+func refreshSpotRating(tx *gorm.DB, spotID uint) error {
+	var spot TouristSpot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&spot, spotID).Error; err != nil {
+		return err
+	}
+	var agg struct {
+		Count   int64
+		Average float64
+	}
+	if err := tx.Model(&Review{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(rating), 0) AS average").
+		Where("tourist_spot_id = ? AND status = ?", spotID, ReviewPublished).
+		Scan(&agg).Error; err != nil {
+		return err
+	}
+	return tx.Model(&TouristSpot{}).Where("id = ?", spotID).UpdateColumns(map[string]interface{}{
+		"rating":       math.Round(agg.Average*10) / 10,
+		"review_count": agg.Count,
+	}).Error
+}
+
+// レビューを投稿する
+func CreateReview(db *gorm.DB, spotID, userID uint, in ReviewInput) (*Review, error) {
+	if err := in.Validate(db); err != nil {
+		return nil, err
+	}
+	review := Review{TouristSpotID: spotID, UserID: userID, Rating: in.Rating, Text: in.Text, ImageID: in.ImageID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var spot TouristSpot
+		if err := tx.Select("id").First(&spot, spotID).Error; err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&Review{}).Where("tourist_spot_id = ? AND user_id = ?", spotID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return newReviewError(409, "review.already_posted")
+		}
+		if err := review.applySpamCheck(tx); err != nil {
+			return err
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return newReviewError(409, "review.already_posted")
+			}
+			return err
+		}
+		return refreshSpotRating(tx, spotID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// レビューを編集する（本人のみ）
+func UpdateReview(db *gorm.DB, reviewID, userID uint, in ReviewInput) (before, after *Review, err error) {
+	if err := in.Validate(db); err != nil {
+		return nil, nil, err
+	}
+	var review Review
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		if review.UserID != userID {
+			return newReviewError(404, "review.not_found")
+		}
+		old := review
+		before = &old
+		review.Rating = in.Rating
+		review.Text = in.Text
+		review.ImageID = in.ImageID
+		if err := review.applySpamCheck(tx); err != nil {
+			return err
+		}
+		if err := tx.Model(&review).Select("rating", "text", "image_id", "status", "spam_reason").Updates(&review).Error; err != nil {
+			return err
+		}
+		return refreshSpotRating(tx, review.TouristSpotID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, &review, nil
+}
+
+// レビューを削除する
+func DeleteReview(db *gorm.DB, review *Review) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Review{}, review.ID).Error; err != nil {
+			return err
+		}
+		return refreshSpotRating(tx, review.TouristSpotID)
+	})
+}
+
+// レビューを通報する（同じユーザーは1回まで。未対応の通報が閾値に達したら確認待ちにする）
+func FlagReview(db *gorm.DB, reviewID, userID uint, reason string) (*Review, error) {
+	var review Review
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		if review.Status != ReviewPublished && review.Status != ReviewPending {
+			return newReviewError(404, "review.not_found")
+		}
+		if review.UserID == userID {
+			return newReviewError(400, "review.cannot_flag_own")
+		}
+		var flagged int64
+		if err := tx.Model(&ReviewFlag{}).Where("review_id = ? AND user_id = ?", reviewID, userID).Count(&flagged).Error; err != nil {
+			return err
+		}
+		if flagged > 0 {
+			return newReviewError(409, "review.already_flagged")
+		}
+		if err := tx.Create(&ReviewFlag{ReviewID: reviewID, UserID: userID, Reason: strings.TrimSpace(reason)}).Error; err != nil {
+			return err
+		}
+		review.FlagCount++
+		updates := map[string]interface{}{"flag_count": review.FlagCount}
+		if review.Status == ReviewPublished && review.FlagCount >= reviewFlagThreshold {
+			review.Status = ReviewPending
+			review.SpamReason = ReviewSpamFlagged
+			updates["status"] = review.Status
+			updates["spam_reason"] = review.SpamReason
+		}
+		if err := tx.Model(&review).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if review.Status == ReviewPending {
+			return refreshSpotRating(tx, review.TouristSpotID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// モデレーターがレビューを公開または非表示にする（未対応の通報は対応済みにする）
+func ModerateReview(db *gorm.DB, reviewID, moderatorID uint, status, note string) (before, after *Review, err error) {
+	if status != ReviewPublished && status != ReviewHidden {
+		return nil, nil, newReviewError(400, "review.status_unknown")
+	}
+	var review Review
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		old := review
+		before = &old
+		now := time.Now()
+		review.Status = status
+		review.SpamReason = ""
+		review.FlagCount = 0
+		review.ModerationNote = note
+		review.ModeratedBy = &moderatorID
+		review.ModeratedAt = &now
+		if err := tx.Model(&ReviewFlag{}).Where("review_id = ? AND resolved_at IS NULL", reviewID).Update("resolved_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&review).Select("status", "spam_reason", "flag_count", "moderation_note", "moderated_by", "moderated_at").Updates(&review).Error; err != nil {
+			return err
+		}
+		return refreshSpotRating(tx, review.TouristSpotID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, &review, nil
+}
+
+// 公開中のレビューの星の数ごとの件数と平均
+func GetReviewSummary(db *gorm.DB, spot *TouristSpot) (*ReviewSummary, error) {
+	var rows []struct {
+		Rating int
+		Count  int
+	}
+	if err := db.Model(&Review{}).Select("rating, COUNT(*) AS count").
+		Where("tourist_spot_id = ? AND status = ?", spot.ID, ReviewPublished).
+		Group("rating").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	summary := &ReviewSummary{TouristSpotID: spot.ID, Rating: spot.Rating, ReviewCount: spot.ReviewCount, Distribution: map[int]int{}}
+	for star := 1; star <= 5; star++ {
+		summary.Distribution[star] = 0
+	}
+	for _, row := range rows {
+		summary.Distribution[row.Rating] = row.Count
+	}
+	return summary, nil
+}
+
+// 全観光地の評価を再計算する（レビュー導入前に手入力された値の置き換え用）
+func RebuildSpotRatings(db *gorm.DB) (int, error) {
+	var ids []uint
+	if err := db.Model(&TouristSpot{}).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return refreshSpotRating(tx, id)
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
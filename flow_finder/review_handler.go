@@ -0,0 +1,398 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// レビュー関連のルートを登録
+func RegisterReviewRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	spots := r.Group("/api/tourist-spots/:id/reviews")
+	{
+		// 公開中のレビュー一覧（?sort=newest|rating_high|rating_low&limit=20&offset=0）
+		spots.GET("", listSpotReviewsHandler(db))
+
+		// 評価の集計（星の数ごとの件数）
+		spots.GET("/summary", getReviewSummaryHandler(db))
+
+		// 自分のレビュー（確認待ち・非表示でも返す）
+		spots.GET("/me", getMyReviewHandler(db))
+
+		// レビューを投稿（ゲスト以外のログインユーザー、観光地ごとに1件）
+		spots.POST("", createReviewHandler(db))
+	}
+
+	reviews := r.Group("/api/reviews")
+	{
+		// レビューを編集（本人のみ）
+		reviews.PUT("/:id", updateReviewHandler(db))
+
+		// レビューを削除（本人または reviews:moderate）
+		reviews.DELETE("/:id", deleteReviewHandler(db))
+
+		// レビューを通報
+		reviews.POST("/:id/flag", flagReviewHandler(db))
+	}
+
+	admin := r.Group("/api/admin/reviews")
+	{
+		// モデレーション待ちの一覧（?status=pending|hidden|published&flagged=true）
+		admin.GET("", listModerationReviewsHandler(db))
+
+		// レビューの通報一覧
+		admin.GET("/:id/flags", listReviewFlagsHandler(db))
+
+		// 公開・非表示の切り替え（{"action": "hide", "note": "..."}）
+		admin.POST("/:id/moderate", moderateReviewHandler(db))
+
+		// 全観光地の評価を公開中のレビューから再計算
+		admin.POST("/recalculate", recalculateRatingsHandler(db))
+	}
+}
+
+// レビュー操作のエラーをレスポンスに変換
+func respondReviewError(c *gin.Context, err error, fallback string) {
+	var reviewErr *ReviewError
+	if errors.As(err, &reviewErr) {
+		c.JSON(reviewErr.Status, gin.H{"error": T(c, reviewErr.ID, reviewErr.Args...)})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": T(c, "review.or_spot_not_found")})
+		return
+	}
+	fmt.Printf("⚠️ %s: %v\n", Message(defaultLocale, fallback), err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, fallback)})
+}
+
+type reviewRequest struct {
+	Rating  int    `json:"rating"`
+	Text    string `json:"text"`
+	ImageID *uint  `json:"image_id"`
+}
+
+// レビューを書けるユーザーか確認する（ゲストは不可）
+func reviewAuthor(c *gin.Context, db *gorm.DB) (uint, bool) {
+	userID, exists := GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+		return 0, false
+	}
+	var user User
+	if err := db.Select("id", "is_guest").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+		return 0, false
+	}
+	if user.IsGuest {
+		c.JSON(http.StatusForbidden, gin.H{"error": T(c, "review.registration_required")})
+		return 0, false
+	}
+	return userID, true
+}
+
+// レビュー一覧ハンドラ
+func listSpotReviewsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.Select("id").First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		order := "created_at DESC, id DESC"
+		switch c.DefaultQuery("sort", "newest") {
+		case "newest":
+		case "rating_high":
+			order = "rating DESC, created_at DESC, id DESC"
+		case "rating_low":
+			order = "rating ASC, created_at DESC, id DESC"
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "review.sort_invalid")})
+			return
+		}
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if limit <= 0 || limit > 100 {
+			limit = 20
+		}
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if offset < 0 {
+			offset = 0
+		}
+
+		query := db.Model(&Review{}).Where("tourist_spot_id = ? AND status = ?", spot.ID, ReviewPublished)
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.fetch_failed")})
+			return
+		}
+		var reviews []Review
+		if err := query.Order(order).Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.fetch_failed")})
+			return
+		}
+		fillReviewDetails(db, reviews)
+		c.JSON(http.StatusOK, gin.H{"reviews": reviews, "total": total, "limit": limit, "offset": offset})
+	}
+}
+
+// 評価の集計ハンドラ
+func getReviewSummaryHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var spot TouristSpot
+		if err := db.First(&spot, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		summary, err := GetReviewSummary(db, &spot)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.summary_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, summary)
+	}
+}
+
+// 自分のレビュー取得ハンドラ
+func getMyReviewHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+			return
+		}
+		var review Review
+		if err := db.Where("tourist_spot_id = ? AND user_id = ?", c.Param("id"), userID).First(&review).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "review.not_found")})
+			return
+		}
+		reviews := []Review{review}
+		fillReviewDetails(db, reviews)
+		c.JSON(http.StatusOK, reviews[0])
+	}
+}
+
+// レビュー投稿ハンドラ
+func createReviewHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := reviewAuthor(c, db)
+		if !ok {
+			return
+		}
+		spotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot.invalid_id")})
+			return
+		}
+		var req reviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		review, err := CreateReview(db, uint(spotID), userID, ReviewInput{Rating: req.Rating, Text: req.Text, ImageID: req.ImageID})
+		if err != nil {
+			respondReviewError(c, err, "review.create_failed")
+			return
+		}
+		RecordChangeHistory(db, "reviews", strconv.Itoa(int(review.ID)), &userID, "create", nil, review)
+
+		reviews := []Review{*review}
+		fillReviewDetails(db, reviews)
+		c.JSON(http.StatusCreated, reviews[0])
+	}
+}
+
+// レビュー編集ハンドラ
+func updateReviewHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := reviewAuthor(c, db)
+		if !ok {
+			return
+		}
+		reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "review.invalid_id")})
+			return
+		}
+		var req reviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		before, review, err := UpdateReview(db, uint(reviewID), userID, ReviewInput{Rating: req.Rating, Text: req.Text, ImageID: req.ImageID})
+		if err != nil {
+			respondReviewError(c, err, "review.update_failed")
+			return
+		}
+		RecordChangeHistory(db, "reviews", strconv.Itoa(int(review.ID)), &userID, "update", before, review)
+
+		reviews := []Review{*review}
+		fillReviewDetails(db, reviews)
+		c.JSON(http.StatusOK, reviews[0])
+	}
+}
+
+// レビュー削除ハンドラ
+func deleteReviewHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+			return
+		}
+		var review Review
+		if err := db.First(&review, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "review.not_found")})
+			return
+		}
+		if review.UserID != userID {
+			principal := principalFromContext(c)
+			if principal == nil || !principal.HasPermission(PermReviewsModerate) {
+				c.JSON(http.StatusNotFound, gin.H{"error": T(c, "review.not_found")})
+				return
+			}
+		}
+		if err := DeleteReview(db, &review); err != nil {
+			respondReviewError(c, err, "review.delete_failed")
+			return
+		}
+		RecordChangeHistory(db, "reviews", strconv.Itoa(int(review.ID)), &userID, "delete", review, nil)
+		c.JSON(http.StatusOK, gin.H{"result": "deleted"})
+	}
+}
+
+// レビュー通報ハンドラ
+func flagReviewHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := reviewAuthor(c, db)
+		if !ok {
+			return
+		}
+		reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "review.invalid_id")})
+			return
+		}
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if _, err := FlagReview(db, uint(reviewID), userID, req.Reason); err != nil {
+			respondReviewError(c, err, "review.flag_failed")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": "flagged"})
+	}
+}
+
+// モデレーション用のレビュー一覧ハンドラ
+func listModerationReviewsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.DefaultQuery("status", ReviewPending)
+		if status != ReviewPending && status != ReviewHidden && status != ReviewPublished {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "review.status_invalid")})
+			return
+		}
+		query := db.Model(&Review{}).Where("status = ?", status)
+		if c.Query("flagged") == "true" {
+			query = query.Where("flag_count > 0")
+		}
+		if spotID := c.Query("tourist_spot_id"); spotID != "" {
+			query = query.Where("tourist_spot_id = ?", spotID)
+		}
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if limit <= 0 || limit > 200 {
+			limit = 50
+		}
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if offset < 0 {
+			offset = 0
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.fetch_failed")})
+			return
+		}
+		var reviews []Review
+		if err := query.Order("flag_count DESC, updated_at ASC, id ASC").Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.fetch_failed")})
+			return
+		}
+		fillReviewDetails(db, reviews)
+		c.JSON(http.StatusOK, gin.H{"reviews": reviews, "total": total, "limit": limit, "offset": offset})
+	}
+}
+
+// レビューの通報一覧ハンドラ
+func listReviewFlagsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var flags []ReviewFlag
+		if err := db.Where("review_id = ?", c.Param("id")).Order("created_at ASC").Find(&flags).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.flags_fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, flags)
+	}
+}
+
+// 公開・非表示の切り替えハンドラ
+func moderateReviewHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+			return
+		}
+		reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "review.invalid_id")})
+			return
+		}
+		var req struct {
+			Action string `json:"action"`
+			Note   string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		var status string
+		switch req.Action {
+		case "publish":
+			status = ReviewPublished
+		case "hide":
+			status = ReviewHidden
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "review.action_invalid")})
+			return
+		}
+		before, review, err := ModerateReview(db, uint(reviewID), userID, status, req.Note)
+		if err != nil {
+			respondReviewError(c, err, "review.update_failed")
+			return
+		}
+		RecordChangeHistory(db, "reviews", strconv.Itoa(int(review.ID)), &userID, req.Action, before, review)
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// 評価の再計算ハンドラ
+func recalculateRatingsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count, err := RebuildSpotRatings(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "review.recalculate_failed"), "details": err.Error()})
+			return
+		}
+		RecordChangeHistory(db, "tourist_spots", "ratings", currentUserIDPtr(c), "recalculate", nil, gin.H{"spots": count})
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "spots": count})
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 権限の定義
+const (
+	PermGraphEdit       = "graph:edit"       // フィールド・ノード・リンク・ノード画像・ピンの編集
+	PermSpotEdit        = "spot:edit"        // 観光地・カテゴリ・カテゴリグループの編集
+	PermCongestionWrite = "congestion:write" // 混雑記録・来場者数の更新
+	PermContentEdit     = "content:edit"     // チュートリアル・画像の管理
+	PermLogsRead        = "logs:read"        // 利用ログ・変更履歴の閲覧
+	PermSettingsWrite   = "settings:write"   // アプリ設定の更新
+	PermUsersManage     = "users:manage"     // ユーザー・セッション・パスワードの管理
+	PermRolesManage     = "roles:manage"     // ロールと権限の管理
+	PermAPIKeysManage   = "apikeys:manage"   // APIキーの発行・失効
+	PermAlertsManage    = "alerts:manage"    // 混雑アラートとWebhookの管理
+	PermSensorsManage   = "sensors:manage"   // センサー機器の登録と状態の確認
+	PermQueueManage     = "queue:manage"     // 整理券の設定・呼び出し・チェックイン
+	PermReviewsModerate = "reviews:moderate" // レビューの公開・非表示・削除
+
+	permWildcard = "*" // 全権限（管理者フラグを持つユーザー）
+)
+
+// 定義済みの全権限
+var AllPermissions = []string{
+	PermGraphEdit,
+	PermSpotEdit,
+	PermCongestionWrite,
+	PermContentEdit,
+	PermLogsRead,
+	PermSettingsWrite,
+	PermUsersManage,
+	PermRolesManage,
+	PermAPIKeysManage,
+	PermAlertsManage,
+	PermSensorsManage,
+	PermQueueManage,
+	PermReviewsModerate,
+}
+
+// 権限名が定義済みかどうか
+func IsKnownPermission(perm string) bool {
+	for _, p := range AllPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// ロールモデル
+type Role struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Name        string           `gorm:"not null;uniqueIndex" json:"name"`                       // ロール名
+	Description string           `json:"description"`                                            // 説明
+	IsSystem    bool             `gorm:"default:false" json:"is_system"`                         // 組み込みロール（削除不可）
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"-"` // 付与された権限
+	PermNames   []string         `gorm:"-" json:"permissions"`                                   // レスポンス用の権限名一覧
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// ロールに付与された権限
+type RolePermission struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	RoleID     uint   `gorm:"not null;uniqueIndex:idx_role_permission" json:"role_id"`
+	Permission string `gorm:"not null;uniqueIndex:idx_role_permission" json:"permission"`
+}
+
+// レスポンス用に権限名一覧を埋める
+func (r *Role) FillPermNames() {
+	r.PermNames = make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		r.PermNames = append(r.PermNames, p.Permission)
+	}
+}
+
+// 組み込みロールの定義
+var defaultRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleAdmin, "全ての権限を持つ管理者", AllPermissions},
+	{"staff", "混雑状況・来場者数を更新する現場スタッフ", []string{PermCongestionWrite, PermQueueManage}},
+	{"editor", "マップと観光地情報を編集するスタッフ", []string{PermGraphEdit, PermSpotEdit, PermContentEdit, PermReviewsModerate}},
+}
+
+// 組み込みロールを作成（存在しない場合のみ）
+func SeedDefaultRoles(db *gorm.DB) error {
+	for _, def := range defaultRoles {
+		var role Role
+		err := db.Where("name = ?", def.Name).First(&role).Error
+		if err == nil {
+			continue
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		role = Role{Name: def.Name, Description: def.Description, IsSystem: true}
+		for _, p := range def.Permissions {
+			role.Permissions = append(role.Permissions, RolePermission{Permission: p})
+		}
+		if err := db.Create(&role).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ロール名から権限を引くためのキャッシュ（JWTのロールクレーム用）
+type rolePermissionCache struct {
+	mu       sync.RWMutex
+	perms    map[string][]string
+	loadedAt time.Time
+}
+
+const rolePermissionCacheTTL = 30 * time.Second
+
+var roleCache = &rolePermissionCache{}
+
+// キャッシュを破棄（ロール編集時に呼び出す）
+func InvalidateRoleCache() {
+	roleCache.mu.Lock()
+	roleCache.perms = nil
+	roleCache.mu.Unlock()
+}
+
+// ロール名に対応する権限一覧を取得
+func (rc *rolePermissionCache) lookup(db *gorm.DB, roleNames []string) (map[string]bool, error) {
+	rc.mu.RLock()
+	fresh := rc.perms != nil && time.Since(rc.loadedAt) < rolePermissionCacheTTL
+	rc.mu.RUnlock()
+
+	if !fresh {
+		var roles []Role
+		if err := db.Preload("Permissions").Find(&roles).Error; err != nil {
+			return nil, err
+		}
+		perms := make(map[string][]string, len(roles))
+		for _, r := range roles {
+			for _, p := range r.Permissions {
+				perms[r.Name] = append(perms[r.Name], p.Permission)
+			}
+		}
+		rc.mu.Lock()
+		rc.perms = perms
+		rc.loadedAt = time.Now()
+		rc.mu.Unlock()
+	}
+
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	result := make(map[string]bool)
+	for _, name := range roleNames {
+		for _, p := range rc.perms[name] {
+			result[p] = true
+		}
+	}
+	return result, nil
+}
+
+// ユーザーの権限一覧を取得（管理者フラグを持つユーザーは全権限）
+func LoadUserPermissions(db *gorm.DB, userID uint) (map[string]bool, *User, error) {
+	var user User
+	if err := db.Preload("Roles.Permissions").First(&user, userID).Error; err != nil {
+		return nil, nil, err
+	}
+	perms := make(map[string]bool)
+	if user.IsAdmin {
+		perms[permWildcard] = true
+	}
+	for _, r := range user.Roles {
+		// adminロールは後から追加された権限も含めて全権限を持つ
+		if r.Name == RoleAdmin {
+			perms[permWildcard] = true
+		}
+		for _, p := range r.Permissions {
+			perms[p.Permission] = true
+		}
+	}
+	return perms, &user, nil
+}
+
+// 権限セットを一覧形式に変換（全権限の場合は定義済みの全権限を返す）
+func permissionList(perms map[string]bool) []string {
+	list := []string{}
+	for _, p := range AllPermissions {
+		if perms[permWildcard] || perms[p] {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+var errUnknownPermission = errors.New("unknown permission")
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ロール・権限関連のルートを登録
+func RegisterRoleRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	roles := r.Group("/api/roles")
+	{
+		// ロール一覧取得
+		roles.GET("", listRolesHandler(db))
+
+		// ロール作成
+		roles.POST("", createRoleHandler(db))
+
+		// ロール更新
+		roles.PUT("/:id", updateRoleHandler(db))
+
+		// ロール削除
+		roles.DELETE("/:id", deleteRoleHandler(db))
+	}
+
+	// 定義済み権限一覧
+	r.GET("/api/permissions", func(c *gin.Context) {
+		c.JSON(http.StatusOK, AllPermissions)
+	})
+
+	// ユーザーへのロール割り当て
+	r.PUT("/api/users/:id/roles", assignUserRolesHandler(db))
+
+	// ログイン中ユーザーの権限一覧
+	r.GET("/api/me/permissions", myPermissionsHandler(db))
+}
+
+// 権限名の一覧を検証してRolePermissionに変換
+func buildRolePermissions(perms []string) ([]RolePermission, bool) {
+	seen := make(map[string]bool)
+	var result []RolePermission
+	for _, p := range perms {
+		if !IsKnownPermission(p) {
+			return nil, false
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, RolePermission{Permission: p})
+	}
+	return result, true
+}
+
+// ロール一覧取得ハンドラ
+func listRolesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var roles []Role
+		if err := db.Preload("Permissions").Order("id ASC").Find(&roles).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "role.fetch_failed")})
+			return
+		}
+		for i := range roles {
+			roles[i].FillPermNames()
+		}
+		c.JSON(http.StatusOK, roles)
+	}
+}
+
+// ロール作成ハンドラ
+func createRoleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name        string   `json:"name" binding:"required"`
+			Description string   `json:"description"`
+			Permissions []string `json:"permissions"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		perms, ok := buildRolePermissions(req.Permissions)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "role.unknown_permission"), "permissions": AllPermissions})
+			return
+		}
+
+		var existing Role
+		if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": T(c, "role.name_taken")})
+			return
+		}
+
+		role := Role{Name: req.Name, Description: req.Description, Permissions: perms}
+		if err := db.Create(&role).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "role.create_failed")})
+			return
+		}
+		InvalidateRoleCache()
+		role.FillPermNames()
+
+		var userID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			userID = &uid
+		}
+		RecordChangeHistory(db, "roles", strconv.Itoa(int(role.ID)), userID, "create", nil, role)
+
+		c.JSON(http.StatusCreated, role)
+	}
+}
+
+// ロール更新ハンドラ
+func updateRoleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var role Role
+		if err := db.Preload("Permissions").First(&role, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "role.not_found")})
+			return
+		}
+		role.FillPermNames()
+		beforeRole := role
+
+		var req struct {
+			Name        *string   `json:"name"`
+			Description *string   `json:"description"`
+			Permissions *[]string `json:"permissions"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		// 組み込みロールは名前を変更できない。adminロールは常に全権限を持つ
+		if role.IsSystem && req.Name != nil && *req.Name != role.Name {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "role.system_rename")})
+			return
+		}
+		if role.Name == RoleAdmin && req.Permissions != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "role.admin_immutable")})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			updates := map[string]interface{}{}
+			if req.Name != nil {
+				updates["name"] = *req.Name
+			}
+			if req.Description != nil {
+				updates["description"] = *req.Description
+			}
+			if len(updates) > 0 {
+				if err := tx.Model(&role).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+			if req.Permissions != nil {
+				perms, ok := buildRolePermissions(*req.Permissions)
+				if !ok {
+					return errUnknownPermission
+				}
+				if err := tx.Where("role_id = ?", role.ID).Delete(&RolePermission{}).Error; err != nil {
+					return err
+				}
+				for i := range perms {
+					perms[i].RoleID = role.ID
+				}
+				if len(perms) > 0 {
+					if err := tx.Create(&perms).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err == errUnknownPermission {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "role.unknown_permission"), "permissions": AllPermissions})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "role.update_failed")})
+			return
+		}
+		InvalidateRoleCache()
+
+		db.Preload("Permissions").First(&role, role.ID)
+		role.FillPermNames()
+
+		var userID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			userID = &uid
+		}
+		RecordChangeHistory(db, "roles", id, userID, "update", beforeRole, role)
+
+		c.JSON(http.StatusOK, role)
+	}
+}
+
+// ロール削除ハンドラ
+func deleteRoleHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var role Role
+		if err := db.Preload("Permissions").First(&role, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "role.not_found")})
+			return
+		}
+		if role.IsSystem {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "role.system_delete")})
+			return
+		}
+		role.FillPermNames()
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&role).Association("Permissions").Unscoped().Clear(); err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", role.ID).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&role).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "role.delete_failed")})
+			return
+		}
+		InvalidateRoleCache()
+
+		var userID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			userID = &uid
+		}
+		RecordChangeHistory(db, "roles", id, userID, "delete", role, nil)
+
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "message": T(c, "role.deleted")})
+	}
+}
+
+// ユーザーへのロール割り当てハンドラ（指定したロールで置き換える）
+func assignUserRolesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var user User
+		if err := db.Preload("Roles").First(&user, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "user.not_found")})
+			return
+		}
+		beforeRoles := user.RoleNames()
+
+		var req struct {
+			RoleIDs []uint `json:"role_ids"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
+			return
+		}
+
+		// 同じIDが重複して指定されても1つとして扱う
+		roleIDs := uniqueIDs(req.RoleIDs)
+		var roles []Role
+		if len(roleIDs) > 0 {
+			if err := db.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "role.fetch_failed")})
+				return
+			}
+			if len(roles) != len(roleIDs) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "role.unknown_role")})
+				return
+			}
+		}
+
+		if err := db.Model(&user).Association("Roles").Replace(roles); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "role.assign_failed")})
+			return
+		}
+		user.Roles = roles
+
+		var operatorID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			operatorID = &uid
+		}
+		RecordChangeHistory(db, "user_roles", id, operatorID, "update",
+			gin.H{"roles": beforeRoles}, gin.H{"roles": user.RoleNames()})
+
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "user_id": user.ID, "roles": roles})
+	}
+}
+
+// ログイン中ユーザーの権限一覧ハンドラ
+func myPermissionsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := GetUserIDFromContext(c)
+		perms, user, err := LoadUserPermissions(db, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "user.not_found")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":     user.ID,
+			"is_admin":    user.IsAdmin,
+			"roles":       user.RoleNames(),
+			"permissions": permissionList(perms),
+		})
+	}
+}
+
+// IDの重複を取り除く（指定順は保つ）
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
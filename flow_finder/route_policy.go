@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ルートに要求する認証レベル
+type PolicyLevel string
+
+const (
+	PolicyPublic        PolicyLevel = "public"        // 認証不要（トークンがあれば検証してユーザーを設定）
+	PolicyAuthenticated PolicyLevel = "authenticated" // ログインが必要
+	PolicyPermission    PolicyLevel = "permission"    // 指定した権限が必要
+)
+
+// ルートごとの認証ポリシー
+type RoutePolicy struct {
+	Level      PolicyLevel
+	Permission string // Level が PolicyPermission の場合に要求する権限
+	SpotParam  string // 観光地IDのパスパラメータ名（観光地を限定したAPIキーで利用可能なルート）
+}
+
+var (
+	publicRoute        = RoutePolicy{Level: PolicyPublic}
+	authenticatedRoute = RoutePolicy{Level: PolicyAuthenticated}
+)
+
+func permissionRoute(permission string) RoutePolicy {
+	return RoutePolicy{Level: PolicyPermission, Permission: permission}
+}
+
+// 観光地単位の操作（観光地を限定したAPIキーでも対象の観光地なら利用できる）
+func spotPermissionRoute(permission, spotParam string) RoutePolicy {
+	return RoutePolicy{Level: PolicyPermission, Permission: permission, SpotParam: spotParam}
+}
+
+// 観光地をリクエストボディで指定する操作（観光地の制限はハンドラーで確認する）
+func bodySpotPermissionRoute(permission string) RoutePolicy {
+	return RoutePolicy{Level: PolicyPermission, Permission: permission, SpotParam: spotCheckedInHandler}
+}
+
+// 全エンドポイントの認証ポリシー表（キーは "METHOD パス"、パスはginのルート定義そのまま）
+// ルートを追加した場合はここにも追加すること。未登録のルートがあると起動時に失敗する
+var routePolicies = map[string]RoutePolicy{
+	// ヘルスチェック・認証
+	"GET /api/health":             publicRoute,
+	"POST /api/login":             publicRoute,
+	"POST /api/token/refresh":     publicRoute,
+	"POST /api/guests":            publicRoute,
+	"GET /api/auth/oidc/login":    publicRoute,
+	"GET /api/auth/oidc/callback": publicRoute,
+	"POST /api/logout":            authenticatedRoute,
+	"GET /api/sessions":           authenticatedRoute,
+	"DELETE /api/sessions/:id":    authenticatedRoute,
+
+	// ユーザー・ロール
+	"POST /api/users":                        publicRoute, // ユーザー登録
+	"GET /api/users":                         permissionRoute(PermUsersManage),
+	"GET /api/users/me":                      authenticatedRoute,
+	"PUT /api/users/me/password":             authenticatedRoute,
+	"POST /api/users/:id/reset-password":     permissionRoute(PermUsersManage),
+	"DELETE /api/users/:id/sessions":         permissionRoute(PermUsersManage),
+	"PUT /api/users/:id/roles":               permissionRoute(PermRolesManage),
+	"GET /api/me/permissions":                authenticatedRoute,
+	"GET /api/permissions":                   permissionRoute(PermRolesManage),
+	"GET /api/roles":                         permissionRoute(PermRolesManage),
+	"POST /api/roles":                        permissionRoute(PermRolesManage),
+	"PUT /api/roles/:id":                     permissionRoute(PermRolesManage),
+	"DELETE /api/roles/:id":                  permissionRoute(PermRolesManage),
+	"GET /api/api-keys":                      permissionRoute(PermAPIKeysManage),
+	"POST /api/api-keys":                     permissionRoute(PermAPIKeysManage),
+	"POST /api/api-keys/:id/rotate":          permissionRoute(PermAPIKeysManage),
+	"DELETE /api/api-keys/:id":               permissionRoute(PermAPIKeysManage),
+	"GET /api/webhooks":                      permissionRoute(PermAlertsManage),
+	"POST /api/webhooks":                     permissionRoute(PermAlertsManage),
+	"PUT /api/webhooks/:id":                  permissionRoute(PermAlertsManage),
+	"DELETE /api/webhooks/:id":               permissionRoute(PermAlertsManage),
+	"POST /api/webhooks/:id/test":            permissionRoute(PermAlertsManage),
+	"GET /api/webhooks/:id/deliveries":       permissionRoute(PermAlertsManage),
+	"POST /api/webhook-deliveries/:id/retry": permissionRoute(PermAlertsManage),
+	"GET /api/alert-rules":                   permissionRoute(PermAlertsManage),
+	"POST /api/alert-rules":                  permissionRoute(PermAlertsManage),
+	"PUT /api/alert-rules/:id":               permissionRoute(PermAlertsManage),
+	"DELETE /api/alert-rules/:id":            permissionRoute(PermAlertsManage),
+	"GET /api/alert-rules/:id/states":        permissionRoute(PermAlertsManage),
+	"GET /api/sensors":                       permissionRoute(PermSensorsManage),
+	"POST /api/sensors":                      permissionRoute(PermSensorsManage),
+	"PUT /api/sensors/:id":                   permissionRoute(PermSensorsManage),
+	"DELETE /api/sensors/:id":                permissionRoute(PermSensorsManage),
+	"GET /api/sensors/:id/events":            permissionRoute(PermSensorsManage),
+	"POST /api/sensors/events":               bodySpotPermissionRoute(PermCongestionWrite),
+
+	// フィールド
+	"GET /api/fields":               publicRoute,
+	"GET /api/fields/active":        publicRoute,
+	"GET /api/fields/:id":           publicRoute,
+	"POST /api/fields":              permissionRoute(PermGraphEdit),
+	"PUT /api/fields/:id":           permissionRoute(PermGraphEdit),
+	"POST /api/fields/:id/activate": permissionRoute(PermGraphEdit),
+	"DELETE /api/fields/:id":        permissionRoute(PermGraphEdit),
+
+	// ノード・リンク・ノード画像・ピン
+	"GET /api/nodes":                     publicRoute,
+	"GET /api/nodes/:id":                 publicRoute,
+	"POST /api/nodes":                    permissionRoute(PermGraphEdit),
+	"PUT /api/nodes/:id":                 permissionRoute(PermGraphEdit),
+	"DELETE /api/nodes/:id":              permissionRoute(PermGraphEdit),
+	"GET /api/nodes/:id/images":          publicRoute,
+	"POST /api/nodes/:id/images":         permissionRoute(PermGraphEdit),
+	"DELETE /api/node-images/:id":        permissionRoute(PermGraphEdit),
+	"GET /api/node-images/:id/pins":      publicRoute,
+	"POST /api/node-images/:id/pins":     permissionRoute(PermGraphEdit),
+	"PUT /api/image-pins/:id":            permissionRoute(PermGraphEdit),
+	"DELETE /api/image-pins/:id":         permissionRoute(PermGraphEdit),
+	"GET /api/links":                     publicRoute,
+	"GET /api/links/:id":                 publicRoute,
+	"POST /api/links":                    permissionRoute(PermGraphEdit),
+	"PUT /api/links/:id":                 permissionRoute(PermGraphEdit),
+	"DELETE /api/links/:id":              permissionRoute(PermGraphEdit),
+	"GET /api/nodes/:id/available-links": publicRoute,
+
+	// 経路探索・デバッグ
+	"POST /api/dijkstra":            publicRoute,
+	"POST /api/tourist-spots/route": publicRoute,
+	"GET /api/debug/graph":          permissionRoute(PermGraphEdit),
+	"POST /api/debug/distance":      permissionRoute(PermGraphEdit),
+
+	// 観光地・カテゴリ
+	"GET /api/tourist-spots":                                  publicRoute,
+	"GET /api/tourist-spots/:id":                              publicRoute,
+	"POST /api/tourist-spots":                                 permissionRoute(PermSpotEdit),
+	"PUT /api/tourist-spots/:id":                              permissionRoute(PermSpotEdit),
+	"DELETE /api/tourist-spots/:id":                           permissionRoute(PermSpotEdit),
+	"POST /api/tourist-spots/:id/visitors":                    spotPermissionRoute(PermCongestionWrite, "id"),
+	"GET /api/tourist-spots/:id/congestion":                   publicRoute,
+	"GET /api/tourist-spots/:id/congestion/history":           publicRoute,
+	"GET /api/tourist-spot-categories/:id/congestion":         publicRoute,
+	"GET /api/tourist-spot-categories/:id/congestion/history": publicRoute,
+	"GET /api/tourist-spots/:id/forecast":                     publicRoute,
+	"GET /api/congestion/stream":                              publicRoute,
+	"GET /api/congestion/ws":                                  publicRoute,
+	"POST /api/tourist-spots/:id/congestion":                  spotPermissionRoute(PermCongestionWrite, "id"),
+	"GET /api/tourist-spot-categories":                        publicRoute,
+	"GET /api/tourist-spot-categories/:id":                    publicRoute,
+	"POST /api/tourist-spot-categories":                       permissionRoute(PermSpotEdit),
+	"PUT /api/tourist-spot-categories/:id":                    permissionRoute(PermSpotEdit),
+	"DELETE /api/tourist-spot-categories/:id":                 permissionRoute(PermSpotEdit),
+	"GET /api/category-groups":                                publicRoute,
+	"POST /api/category-groups":                               permissionRoute(PermSpotEdit),
+	"PUT /api/category-groups/:id":                            permissionRoute(PermSpotEdit),
+	"DELETE /api/category-groups/:id":                         permissionRoute(PermSpotEdit),
+
+	// 営業時間
+	"GET /api/tourist-spots/:id/schedule":                             publicRoute,
+	"PUT /api/tourist-spots/:id/schedule":                             permissionRoute(PermSpotEdit),
+	"POST /api/tourist-spots/:id/schedule/exceptions":                 permissionRoute(PermSpotEdit),
+	"DELETE /api/tourist-spots/:id/schedule/exceptions/:exception_id": permissionRoute(PermSpotEdit),
+
+	"POST /api/admin/tourist-spots/nearest-nodes": permissionRoute(PermGraphEdit),
+
+	// 検索
+	"GET /api/search":                publicRoute,
+	"POST /api/admin/search/reindex": permissionRoute(PermSpotEdit),
+
+	// レビュー
+	"GET /api/tourist-spots/:id/reviews":         publicRoute,
+	"GET /api/tourist-spots/:id/reviews/summary": publicRoute,
+	"GET /api/tourist-spots/:id/reviews/me":      authenticatedRoute,
+	"POST /api/tourist-spots/:id/reviews":        authenticatedRoute,
+	"PUT /api/reviews/:id":                       authenticatedRoute,
+	"DELETE /api/reviews/:id":                    authenticatedRoute,
+	"POST /api/reviews/:id/flag":                 authenticatedRoute,
+	"GET /api/admin/reviews":                     permissionRoute(PermReviewsModerate),
+	"GET /api/admin/reviews/:id/flags":           permissionRoute(PermReviewsModerate),
+	"POST /api/admin/reviews/:id/moderate":       permissionRoute(PermReviewsModerate),
+	"POST /api/admin/reviews/recalculate":        permissionRoute(PermReviewsModerate),
+
+	// 観光地の一括取り込み・書き出し
+	"POST /api/admin/tourist-spots/import": permissionRoute(PermSpotEdit),
+	"GET /api/admin/tourist-spots/export":  permissionRoute(PermSpotEdit),
+
+	// 多言語対応
+	"GET /api/locales":                    publicRoute,
+	"GET /api/admin/translations":         permissionRoute(PermContentEdit),
+	"GET /api/admin/translations/missing": permissionRoute(PermContentEdit),
+	"PUT /api/admin/translations":         permissionRoute(PermContentEdit),
+	"DELETE /api/admin/translations/:id":  permissionRoute(PermContentEdit),
+
+	// 入場制限
+	"GET /api/tourist-spots/:id/capacity":             publicRoute,
+	"GET /api/tourist-spots/:id/capacity/policy":      permissionRoute(PermSpotEdit),
+	"PUT /api/tourist-spots/:id/capacity/policy":      permissionRoute(PermSpotEdit),
+	"GET /api/tourist-spots/:id/capacity/transitions": spotPermissionRoute(PermCongestionWrite, "id"),
+	"GET /api/tourist-spots/:id/alternatives":         publicRoute,
+
+	// 整理券
+	"GET /api/tourist-spots/:id/queue":            publicRoute,
+	"PUT /api/tourist-spots/:id/queue":            spotPermissionRoute(PermQueueManage, "id"),
+	"POST /api/tourist-spots/:id/queue/tickets":   authenticatedRoute,
+	"GET /api/tourist-spots/:id/queue/tickets":    spotPermissionRoute(PermQueueManage, "id"),
+	"POST /api/tourist-spots/:id/queue/call-next": spotPermissionRoute(PermQueueManage, "id"),
+	"POST /api/tourist-spots/:id/queue/check-in":  spotPermissionRoute(PermQueueManage, "id"),
+	"GET /api/queue/tickets/me":                   authenticatedRoute,
+	"GET /api/queue/tickets/:id":                  authenticatedRoute,
+	"DELETE /api/queue/tickets/:id":               authenticatedRoute,
+	"POST /api/queue/tickets/:id/check-in":        authenticatedRoute,
+
+	// お気に入り
+	"GET /api/favorites/tourist-spots":                   authenticatedRoute,
+	"POST /api/favorites/tourist-spots":                  authenticatedRoute,
+	"PUT /api/favorites/tourist-spots/:id":               authenticatedRoute,
+	"DELETE /api/favorites/tourist-spots/:id":            authenticatedRoute,
+	"GET /api/favorites/tourist-spots/:id/check":         authenticatedRoute,
+	"GET /api/favorites/stats":                           authenticatedRoute,
+	"POST /api/favorites/categories/:categoryId/add-all": authenticatedRoute,
+
+	// 画像・チュートリアル・アップロード
+	"GET /api/images":                       publicRoute,
+	"DELETE /api/images/:id":                permissionRoute(PermContentEdit),
+	"POST /api/upload":                      permissionRoute(PermContentEdit),
+	"GET /uploads/*filepath":                publicRoute,
+	"HEAD /uploads/*filepath":               publicRoute,
+	"GET /api/tutorials":                    publicRoute,
+	"GET /api/tutorials/category/:category": publicRoute,
+	"POST /api/tutorials/upload":            permissionRoute(PermContentEdit),
+	"PUT /api/tutorials/:id":                permissionRoute(PermContentEdit),
+	"DELETE /api/tutorials/:id":             permissionRoute(PermContentEdit),
+	"PUT /api/tutorials/:id/order":          permissionRoute(PermContentEdit),
+
+	// アプリ設定
+	"GET /api/settings/:key": publicRoute,
+	"PUT /api/settings/:key": permissionRoute(PermSettingsWrite),
+
+	// ログ・変更履歴
+	"POST /api/logs":                 publicRoute, // フロントエンドからのログ送信
+	"GET /api/logs":                  permissionRoute(PermLogsRead),
+	"GET /api/logs/stats":            permissionRoute(PermLogsRead),
+	"GET /api/logs/popular-pages":    permissionRoute(PermLogsRead),
+	"GET /api/logs/export":           permissionRoute(PermLogsRead),
+	"GET /api/logs/timeline":         permissionRoute(PermLogsRead),
+	"GET /api/change-history":        permissionRoute(PermLogsRead),
+	"GET /api/change-history/export": permissionRoute(PermLogsRead),
+	"GET /api/change-history/stats":  permissionRoute(PermLogsRead),
+
+	// レート制限の管理
+	"GET /api/admin/rate-limits/policies":  permissionRoute(PermLogsRead),
+	"GET /api/admin/rate-limits/offenders": permissionRoute(PermLogsRead),
+	"GET /api/admin/mqtt/status":           permissionRoute(PermSensorsManage),
+}
+
+func routePolicyKey(method, path string) string {
+	return method + " " + path
+}
+
+// ルートの認証ポリシーを取得
+func LookupRoutePolicy(method, path string) (RoutePolicy, bool) {
+	policy, ok := routePolicies[routePolicyKey(method, path)]
+	return policy, ok
+}
+
+// ポリシーに対応する認証ミドルウェアの設定
+func (p RoutePolicy) authOptions() AuthOptions {
+	switch p.Level {
+	case PolicyPublic:
+		return AuthOptions{}
+	case PolicyAuthenticated:
+		return AuthOptions{Required: true}
+	default:
+		return AuthOptions{Required: true, Permission: p.Permission, SpotParam: p.SpotParam}
+	}
+}
+
+// ポリシー表に従って認証・認可を行うミドルウェア
+// ルート登録より前に r.Use で登録し、全ルートに適用する
+func RoutePolicyMiddleware(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	handlers := make(map[string]gin.HandlerFunc, len(routePolicies))
+	for key, policy := range routePolicies {
+		handlers[key] = Authenticate(db, redisClient, policy.authOptions())
+	}
+
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			// 一致するルートがない（404）
+			c.Next()
+			return
+		}
+
+		handler, ok := handlers[routePolicyKey(c.Request.Method, path)]
+		if !ok {
+			// 起動時チェックで検出されるはずだが、念のため拒否する
+			fmt.Printf("❌ 認証ポリシー未定義のルート: %s %s\n", c.Request.Method, path)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": T(c, "common.access_denied")})
+			return
+		}
+		handler(c)
+	}
+}
+
+// 登録済みの全ルートに認証ポリシーが定義されているか（ポリシーだけが残っていないか）確認
+func CheckRoutePolicies(routes gin.RoutesInfo) error {
+	var missing []string
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		key := routePolicyKey(route.Method, route.Path)
+		registered[key] = true
+		if _, ok := routePolicies[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("認証ポリシーが未定義のルートがあります: %s", strings.Join(missing, ", "))
+	}
+
+	// ルートの削除・パス変更で取り残されたポリシーを検出する
+	var stale []string
+	for key := range routePolicies {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("登録されていないルートの認証ポリシーがあります: %s", strings.Join(stale, ", "))
+	}
+
+	for key, policy := range routePolicies {
+		if policy.Level == PolicyPermission && !IsKnownPermission(policy.Permission) {
+			return fmt.Errorf("未定義の権限がポリシーに指定されています: %s (%s)", key, policy.Permission)
+		}
+	}
+	return nil
+}
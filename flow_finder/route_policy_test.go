@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// main と同じ手順でルートを登録したエンジンを作る（DBには接続しない）
+func newRoutePolicyTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	r := gin.New()
+	SetupRoutes(r, db, nil)
+	RegisterLogRoutes(r, db)
+	RegisterChangeHistoryRoutes(r, db)
+	return r
+}
+
+func TestCheckRoutePolicies(t *testing.T) {
+	r := newRoutePolicyTestEngine(t)
+	if err := CheckRoutePolicies(r.Routes()); err != nil {
+		t.Fatalf("CheckRoutePolicies: %v", err)
+	}
+}
+
+func TestCheckRoutePoliciesRejectsUnregisteredRoute(t *testing.T) {
+	r := newRoutePolicyTestEngine(t)
+	r.GET("/api/route-policy-test", func(c *gin.Context) {})
+
+	err := CheckRoutePolicies(r.Routes())
+	if err == nil {
+		t.Fatal("ポリシー未定義のルートが検出されなかった")
+	}
+	if !strings.Contains(err.Error(), "GET /api/route-policy-test") {
+		t.Errorf("エラーにルートが含まれていない: %v", err)
+	}
+}
+
+func TestCheckRoutePoliciesRejectsStalePolicy(t *testing.T) {
+	r := newRoutePolicyTestEngine(t)
+	key := routePolicyKey("GET", "/api/route-policy-test")
+	routePolicies[key] = publicRoute
+	defer delete(routePolicies, key)
+
+	err := CheckRoutePolicies(r.Routes())
+	if err == nil {
+		t.Fatal("ルートのないポリシーが検出されなかった")
+	}
+	if !strings.Contains(err.Error(), key) {
+		t.Errorf("エラーにポリシーが含まれていない: %v", err)
+	}
+}
+
+// Require はトークンのないリクエストをハンドラーに渡さない
+func TestRequireRejectsMissingToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/require-test", Require(nil, nil, PermContentEdit), func(c *gin.Context) {
+		t.Error("ハンドラーが呼ばれた")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/require-test", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 横断検索
+// 観光地・ノード・カテゴリ・カテゴリグループを search_documents に正規化した形で保持し、
+// 全文検索（to_tsvector）・トライグラムの類似度（pg_trgm）・部分一致を組み合わせて順位付けする。
+// 元データの変更時は RefreshSearchDocument で更新し、起動時と管理者の操作で全件を作り直す
+
+// 検索対象の種類
+const (
+	SearchTypeSpot     = "spot"
+	SearchTypeNode     = "node"
+	SearchTypeCategory = "category"
+	SearchTypeGroup    = "group"
+)
+
+var searchTypes = []string{SearchTypeSpot, SearchTypeNode, SearchTypeCategory, SearchTypeGroup}
+
+const (
+	searchCandidateLimit = 500 // 順位付け・ファセット集計の対象にする最大件数
+	searchSnippetRunes   = 80  // 説明文の抜粋の長さ
+	searchMaxQueryRunes  = 100
+)
+
+// pg_trgm を利用できるかどうか（拡張を作成できなかった場合は部分一致と全文検索のみ）
+var searchTrigramEnabled = true
+
+// 検索用の文書
+type SearchDocument struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	EntityType   string    `gorm:"not null;uniqueIndex:idx_search_document_entity,priority:1" json:"entity_type"`
+	EntityID     uint      `gorm:"not null;uniqueIndex:idx_search_document_entity,priority:2" json:"entity_id"`
+	Title        string    `gorm:"not null" json:"title"` // 元の名称
+	Body         string    `json:"body"`                  // 元の説明文
+	CategoryID   *uint     `gorm:"index" json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	SearchTitle  string    `gorm:"not null" json:"-"` // 正規化した名称（読み仮名を含む）
+	SearchText   string    `gorm:"not null" json:"-"` // 正規化した名称と説明文
+	Romaji       string    `gorm:"not null" json:"-"` // 名称のローマ字（比較用の形）
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// 観光地の検索用文書
+func spotSearchDocument(spot *TouristSpot) SearchDocument {
+	doc := SearchDocument{
+		EntityType: SearchTypeSpot,
+		EntityID:   spot.ID,
+		Title:      spot.Name,
+		Body:       spot.Description,
+		CategoryID: spot.CategoryID,
+	}
+	if spot.TouristCategory != nil {
+		doc.CategoryName = spot.TouristCategory.Name
+	}
+	doc.fill(spot.NameKana)
+	return doc
+}
+
+// 正規化した列を設定
+func (d *SearchDocument) fill(reading string) {
+	d.SearchTitle = strings.TrimSpace(normalizeSearchText(d.Title) + " " + normalizeSearchText(reading))
+	d.SearchText = strings.TrimSpace(d.SearchTitle + " " + normalizeSearchText(d.Body) + " " + normalizeSearchText(d.CategoryName))
+	d.Romaji = canonicalRomaji(d.SearchTitle)
+}
+
+// 元データから検索用文書を作る（対象外・存在しない場合は nil）
+func buildSearchDocument(db *gorm.DB, entityType string, id uint) (*SearchDocument, error) {
+	var doc SearchDocument
+	switch entityType {
+	case SearchTypeSpot:
+		var spot TouristSpot
+		if err := db.Preload("TouristCategory").Limit(1).Find(&spot, id).Error; err != nil || spot.ID == 0 {
+			return nil, err
+		}
+		doc = spotSearchDocument(&spot)
+	case SearchTypeNode:
+		var node Node
+		if err := db.Limit(1).Find(&node, id).Error; err != nil || node.ID == 0 || strings.TrimSpace(node.Name) == "" {
+			return nil, err
+		}
+		doc = SearchDocument{EntityType: entityType, EntityID: node.ID, Title: node.Name}
+		doc.fill("")
+	case SearchTypeCategory:
+		var category TouristSpotCategory
+		if err := db.Preload("Group").Limit(1).Find(&category, id).Error; err != nil || category.ID == 0 || !category.IsActive {
+			return nil, err
+		}
+		doc = SearchDocument{EntityType: entityType, EntityID: category.ID, Title: category.Name, Body: category.Description, CategoryID: &category.ID, CategoryName: category.Name}
+		doc.fill("")
+	case SearchTypeGroup:
+		var group CategoryGroup
+		if err := db.Limit(1).Find(&group, id).Error; err != nil || group.ID == 0 || !group.IsActive {
+			return nil, err
+		}
+		doc = SearchDocument{EntityType: entityType, EntityID: group.ID, Title: group.Name}
+		doc.fill("")
+	default:
+		return nil, fmt.Errorf("不明な検索対象です: %s", entityType)
+	}
+	return &doc, nil
+}
+
+// 検索用文書を更新する（元データが削除・無効化されていれば文書も削除）
+func RefreshSearchDocument(db *gorm.DB, entityType string, id uint) error {
+	doc, err := buildSearchDocument(db, entityType, id)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return db.Where("entity_type = ? AND entity_id = ?", entityType, id).Delete(&SearchDocument{}).Error
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "body", "category_id", "category_name", "search_title", "search_text", "romaji", "updated_at"}),
+	}).Create(doc).Error; err != nil {
+		return err
+	}
+
+	// カテゴリ名は観光地の文書にも含めるため、カテゴリの変更時は所属する観光地も更新する
+	if entityType == SearchTypeCategory {
+		var spotIDs []uint
+		db.Model(&TouristSpot{}).Where("category_id = ?", id).Pluck("id", &spotIDs)
+		for _, spotID := range spotIDs {
+			if err := RefreshSearchDocument(db, SearchTypeSpot, spotID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ハンドラから呼び出す検索用文書の更新（失敗しても元の操作は成功させる）
+func refreshSearchIndex(db *gorm.DB, entityType string, id uint) {
+	if err := RefreshSearchDocument(db, entityType, id); err != nil {
+		fmt.Printf("⚠️ 検索インデックスの更新に失敗 - %s:%d, Error: %v\n", entityType, id, err)
+	}
+}
+
+// 検索用文書を全件作り直す
+func RebuildSearchIndex(db *gorm.DB) (int, error) {
+	started := time.Now()
+	count := 0
+	sources := map[string]interface{}{
+		SearchTypeSpot:     &TouristSpot{},
+		SearchTypeNode:     &Node{},
+		SearchTypeCategory: &TouristSpotCategory{},
+		SearchTypeGroup:    &CategoryGroup{},
+	}
+	for _, entityType := range searchTypes {
+		var ids []uint
+		if err := db.Model(sources[entityType]).Order("id ASC").Pluck("id", &ids).Error; err != nil {
+			return count, err
+		}
+		for _, id := range ids {
+			if err := RefreshSearchDocument(db, entityType, id); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	// 今回更新されなかった文書は元データが削除されている
+	if err := db.Where("updated_at < ?", started).Delete(&SearchDocument{}).Error; err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// 検索用テーブルのマイグレーション（pg_trgm の拡張とインデックス）
+func MigrateSearchDocument(db *gorm.DB) error {
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_search_document_tsv ON search_documents USING GIN (to_tsvector('simple', search_text))`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		// 拡張を作成する権限がない場合も検索自体は使えるようにする
+		fmt.Printf("⚠️ pg_trgm拡張を作成できないため、あいまい検索を無効にします: %v\n", err)
+		searchTrigramEnabled = false
+		return nil
+	}
+	for _, column := range []string{"search_title", "search_text", "romaji"} {
+		sql := fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_search_document_%s_trgm ON search_documents USING GIN (%s gin_trgm_ops)`, column, column)
+		if err := db.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 検索条件
+type SearchQuery struct {
+	Query      string
+	Types      []string // 空の場合は全種類
+	CategoryID *uint    // 観光地をカテゴリで絞り込む
+	Limit      int
+	Offset     int
+}
+
+// 検索結果
+type SearchResult struct {
+	Type      string          `json:"type"`
+	ID        uint            `json:"id"`
+	Title     string          `json:"title"`
+	Snippet   string          `json:"snippet"`
+	Highlight SearchHighlight `json:"highlight"` // 一致箇所を <mark> で囲んだHTML
+	Score     float64         `json:"score"`
+	Category  *SearchFacet    `json:"category,omitempty"`
+}
+
+type SearchHighlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// ファセット（カテゴリごとの件数）
+type SearchFacet struct {
+	ID    *uint  `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty"`
+}
+
+type SearchResponse struct {
+	Query    string         `json:"query"`
+	Total    int            `json:"total"`
+	Results  []SearchResult `json:"results"`
+	Facets   SearchFacets   `json:"facets"`
+	Fuzzy    bool           `json:"fuzzy"`    // トライグラムによるあいまい検索を使ったかどうか
+	Capped   bool           `json:"capped"`   // 候補が上限に達し、件数・ファセットが概数の場合
+	Variants []string       `json:"variants"` // 実際に検索した表記
+}
+
+type SearchFacets struct {
+	Types      map[string]int `json:"types"`
+	Categories []SearchFacet  `json:"categories"` // 観光地のカテゴリごとの件数
+}
+
+type scoredSearchDocument struct {
+	SearchDocument
+	Score float64
+}
+
+// 検索を実行する
+func Search(db *gorm.DB, q SearchQuery) (*SearchResponse, error) {
+	text := normalizeSearchText(q.Query)
+	if runes := []rune(text); len(runes) > searchMaxQueryRunes {
+		text = string(runes[:searchMaxQueryRunes])
+	}
+	romaji := canonicalRomaji(text)
+	response := &SearchResponse{
+		Query:    q.Query,
+		Results:  []SearchResult{},
+		Facets:   SearchFacets{Types: map[string]int{}, Categories: []SearchFacet{}},
+		Fuzzy:    searchTrigramEnabled,
+		Variants: []string{text},
+	}
+	if romaji != text {
+		response.Variants = append(response.Variants, romaji)
+	}
+	if text == "" {
+		return response, nil
+	}
+
+	like := "%" + escapeLike(text) + "%"
+	romajiLike := "%" + escapeLike(romaji) + "%"
+	// 名称の部分一致 > 名称の類似 > ローマ字読みの一致・類似 > 説明文の全文検索・類似 の順に重み付けする
+	score := `(CASE WHEN search_title LIKE @like THEN 3 ELSE 0 END)
+		+ (CASE WHEN romaji LIKE @romaji_like THEN 2 ELSE 0 END)
+		+ (CASE WHEN search_text LIKE @like THEN 0.5 ELSE 0 END)
+		+ ts_rank(to_tsvector('simple', search_text), plainto_tsquery('simple', @text))`
+	where := `search_title LIKE @like OR search_text LIKE @like OR romaji LIKE @romaji_like
+		OR to_tsvector('simple', search_text) @@ plainto_tsquery('simple', @text)`
+	if searchTrigramEnabled {
+		score += `
+		+ 2 * word_similarity(@text, search_title)
+		+ 1.5 * word_similarity(@romaji, romaji)
+		+ word_similarity(@text, search_text)`
+		where += ` OR @text <% search_title OR @romaji <% romaji OR @text <% search_text`
+	}
+	args := map[string]interface{}{"like": like, "romaji_like": romajiLike, "text": text, "romaji": romaji}
+
+	var candidates []scoredSearchDocument
+	query := db.Model(&SearchDocument{}).
+		Select("search_documents.*, ("+score+") AS score", args).
+		Where("("+where+")", args)
+	if len(q.Types) > 0 {
+		query = query.Where("entity_type IN ?", q.Types)
+	}
+	if err := query.Order("score DESC, entity_type ASC, entity_id ASC").Limit(searchCandidateLimit).Scan(&candidates).Error; err != nil {
+		return nil, err
+	}
+	response.Capped = len(candidates) == searchCandidateLimit
+
+	// ファセットはカテゴリの絞り込み前の候補で集計する
+	categoryCounts := map[string]*SearchFacet{}
+	var filtered []scoredSearchDocument
+	for _, doc := range candidates {
+		response.Facets.Types[doc.EntityType]++
+		if doc.EntityType == SearchTypeSpot {
+			key := "none"
+			if doc.CategoryID != nil {
+				key = fmt.Sprint(*doc.CategoryID)
+			}
+			if categoryCounts[key] == nil {
+				categoryCounts[key] = &SearchFacet{ID: doc.CategoryID, Name: doc.CategoryName}
+			}
+			categoryCounts[key].Count++
+		}
+		if q.CategoryID != nil && (doc.EntityType != SearchTypeSpot || doc.CategoryID == nil || *doc.CategoryID != *q.CategoryID) {
+			continue
+		}
+		filtered = append(filtered, doc)
+	}
+	for _, facet := range categoryCounts {
+		response.Facets.Categories = append(response.Facets.Categories, *facet)
+	}
+	sort.Slice(response.Facets.Categories, func(i, j int) bool {
+		a, b := response.Facets.Categories[i], response.Facets.Categories[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+
+	response.Total = len(filtered)
+	if q.Offset >= len(filtered) {
+		return response, nil
+	}
+	end := q.Offset + q.Limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	for _, doc := range filtered[q.Offset:end] {
+		result := SearchResult{
+			Type:  doc.EntityType,
+			ID:    doc.EntityID,
+			Title: doc.Title,
+			Score: doc.Score,
+			Highlight: SearchHighlight{
+				Title: highlightSearchText(doc.Title, text),
+			},
+		}
+		result.Snippet, result.Highlight.Snippet = searchSnippet(doc.Body, text)
+		if doc.EntityType == SearchTypeSpot && doc.CategoryID != nil {
+			result.Category = &SearchFacet{ID: doc.CategoryID, Name: doc.CategoryName}
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// LIKE の特殊文字をエスケープ
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// 正規化した検索語（空白区切りの各語）に一致する箇所（元の文字列の範囲、開始位置順）
+func searchMatchRanges(original, text string) [][2]int {
+	normalized, index := normalizeSearchRunes(original)
+	var ranges [][2]int
+	for _, word := range strings.Fields(text) {
+		needle := []rune(word)
+		for i := 0; i+len(needle) <= len(normalized); {
+			if string(normalized[i:i+len(needle)]) != word {
+				i++
+				continue
+			}
+			ranges = append(ranges, [2]int{index[i], index[i+len(needle)-1] + 1})
+			i += len(needle)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	return ranges
+}
+
+// 一致箇所を <mark> で囲んだHTML（それ以外はエスケープする）
+func highlightRunes(runes []rune, ranges [][2]int) string {
+	var b strings.Builder
+	pos := 0
+	for _, r := range ranges {
+		if r[0] < pos {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:r[0]])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[r[0]:r[1]])))
+		b.WriteString("</mark>")
+		pos = r[1]
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:])))
+	return b.String()
+}
+
+func highlightSearchText(original, text string) string {
+	return highlightRunes([]rune(original), searchMatchRanges(original, text))
+}
+
+// 説明文の抜粋（最初の一致箇所の前後）とそのハイライト
+func searchSnippet(body, text string) (string, string) {
+	runes := []rune(body)
+	if len(runes) == 0 {
+		return "", ""
+	}
+	ranges := searchMatchRanges(body, text)
+	start := 0
+	if len(ranges) > 0 {
+		start = ranges[0][0] - searchSnippetRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + searchSnippetRunes
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var shifted [][2]int
+	for _, r := range ranges {
+		if r[0] >= start && r[1] <= end {
+			shifted = append(shifted, [2]int{r[0] - start, r[1] - start})
+		}
+	}
+	snippet := string(runes[start:end])
+	highlighted := highlightRunes(runes[start:end], shifted)
+	if start > 0 {
+		snippet, highlighted = "…"+snippet, "…"+highlighted
+	}
+	if end < len(runes) {
+		snippet, highlighted = snippet+"…", highlighted+"…"
+	}
+	return snippet, highlighted
+}
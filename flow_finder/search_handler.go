@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 検索関連のルートを登録
+func RegisterSearchRoutes(r *gin.Engine, db *gorm.DB) {
+	// 横断検索（?q=&types=spot,node&category_id=&limit=20&offset=0）
+	r.GET("/api/search", searchHandler(db))
+
+	// 検索インデックスの再作成（spot:edit）
+	r.POST("/api/admin/search/reindex", func(c *gin.Context) {
+		count, err := RebuildSearchIndex(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "search.reindex_failed"), "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "documents": count})
+	})
+}
+
+// 横断検索ハンドラ
+func searchHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := SearchQuery{Query: strings.TrimSpace(c.Query("q"))}
+		if q.Query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "search.query_required")})
+			return
+		}
+		if types := c.Query("types"); types != "" {
+			for _, t := range strings.Split(types, ",") {
+				t = strings.TrimSpace(t)
+				known := false
+				for _, st := range searchTypes {
+					known = known || st == t
+				}
+				if !known {
+					c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "search.types_invalid")})
+					return
+				}
+				q.Types = append(q.Types, t)
+			}
+		}
+		if categoryID := c.Query("category_id"); categoryID != "" {
+			id, err := strconv.ParseUint(categoryID, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "category.invalid_id")})
+				return
+			}
+			cid := uint(id)
+			q.CategoryID = &cid
+		}
+		q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if q.Limit <= 0 || q.Limit > 50 {
+			q.Limit = 20
+		}
+		q.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if q.Offset < 0 {
+			q.Offset = 0
+		}
+
+		result, err := Search(db, q)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "search.failed")})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 検索用の文字列正規化
+// 全角・半角の統一（NFKC）、小文字化、カタカナのひらがな化を行い、表記ゆれを吸収する。
+// ローマ字は訓令式に寄せて長音を縮めた形（例: とうきょう・Tokyo → tokyo、しんばし・Shimbashi → sinbasi）に揃え、
+// かなとローマ字のどちらで検索しても一致するようにする
+
+// 検索用に正規化する
+func normalizeSearchText(s string) string {
+	s = strings.ToLower(norm.NFKC.String(s))
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			if !space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			space = true
+			continue
+		case r >= 'ァ' && r <= 'ヶ':
+			r -= 'ァ' - 'ぁ'
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// 1文字ずつ正規化し、正規化後の各文字が元の何文字目に当たるかを返す（ハイライト用）
+func normalizeSearchRunes(s string) ([]rune, []int) {
+	var out []rune
+	var index []int
+	for i, r := range []rune(s) {
+		if unicode.IsSpace(r) {
+			out = append(out, ' ')
+			index = append(index, i)
+			continue
+		}
+		for _, n := range normalizeSearchText(string(r)) {
+			out = append(out, n)
+			index = append(index, i)
+		}
+	}
+	return out, index
+}
+
+var kanaDigraphs = map[string]string{
+	"きゃ": "kya", "きゅ": "kyu", "きょ": "kyo", "しゃ": "sha", "しゅ": "shu", "しょ": "sho", "しぇ": "she",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho", "ちぇ": "che", "にゃ": "nya", "にゅ": "nyu", "にょ": "nyo",
+	"ひゃ": "hya", "ひゅ": "hyu", "ひょ": "hyo", "みゃ": "mya", "みゅ": "myu", "みょ": "myo",
+	"りゃ": "rya", "りゅ": "ryu", "りょ": "ryo", "ぎゃ": "gya", "ぎゅ": "gyu", "ぎょ": "gyo",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo", "じぇ": "je", "ぢゃ": "ja", "ぢゅ": "ju", "ぢょ": "jo",
+	"びゃ": "bya", "びゅ": "byu", "びょ": "byo", "ぴゃ": "pya", "ぴゅ": "pyu", "ぴょ": "pyo",
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo", "てぃ": "ti", "でぃ": "di", "うぃ": "wi", "うぇ": "we", "ゔぁ": "ba",
+}
+
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ゔ': "bu", 'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// ひらがなをヘボン式ローマ字にする（かな以外の文字はそのまま）
+func kanaToRomaji(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	sokuon := false
+	for i := 0; i < len(runes); i++ {
+		var romaji string
+		if i+1 < len(runes) {
+			if d, ok := kanaDigraphs[string(runes[i:i+2])]; ok {
+				romaji = d
+				i++
+			}
+		}
+		if romaji == "" {
+			switch r := runes[i]; r {
+			case 'っ':
+				sokuon = true
+				continue
+			case 'ー':
+				// 長音は直前の母音を繰り返す（後で縮める）
+				if str := b.String(); len(str) > 0 && strings.ContainsRune("aiueo", rune(str[len(str)-1])) {
+					romaji = str[len(str)-1:]
+				}
+			default:
+				if k, ok := kanaRomaji[r]; ok {
+					romaji = k
+				} else {
+					romaji = string(r)
+				}
+			}
+		}
+		if sokuon && romaji != "" && !strings.ContainsRune("aiueon", rune(romaji[0])) {
+			if strings.HasPrefix(romaji, "ch") {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte(romaji[0])
+			}
+		}
+		sokuon = false
+		b.WriteString(romaji)
+	}
+	return b.String()
+}
+
+// ヘボン式・訓令式の違いと長音の書き方を吸収した形
+var romajiCanonical = strings.NewReplacer(
+	"sha", "sya", "shu", "syu", "sho", "syo", "she", "sye", "shi", "si",
+	"cha", "tya", "chu", "tyu", "cho", "tyo", "che", "tye", "chi", "ti", "tchi", "tti",
+	"tsu", "tu", "ja", "zya", "ju", "zyu", "jo", "zyo", "je", "zye", "ji", "zi",
+	"fu", "hu", "mb", "nb", "mp", "np",
+)
+
+var romajiLongVowels = strings.NewReplacer(
+	"ou", "o", "oo", "o", "uu", "u", "aa", "a", "ii", "i", "ee", "e",
+)
+
+// ローマ字（かなを含む場合は変換後）を比較用の形にそろえる
+func canonicalRomaji(s string) string {
+	s = kanaToRomaji(s)
+	s = romajiCanonical.Replace(s)
+	// 長音の縮約は置換後に再度縮まる場合があるため2回行う（"ooo" など）
+	s = romajiLongVowels.Replace(romajiLongVowels.Replace(s))
+	return s
+}
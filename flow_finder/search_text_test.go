@@ -0,0 +1,100 @@
+package main
+
+import "testing"
+
+func TestNormalizeSearchText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "カタカナはひらがなにする", in: "トウキョウ", want: "とうきょう"},
+		{name: "ひらがなはそのまま", in: "とうきょう", want: "とうきょう"},
+		{name: "半角カタカナ", in: "ﾄｳｷｮｳ", want: "とうきょう"},
+		{name: "半角カタカナの濁点", in: "ｶﾞｲﾄﾞ", want: "がいど"},
+		{name: "ヴ", in: "ヴィーナス", want: "ゔぃーなす"},
+		{name: "全角英数字", in: "ＴＯＫＹＯ２０２５", want: "tokyo2025"},
+		{name: "大文字", in: "Tokyo Tower", want: "tokyo tower"},
+		{name: "連続する空白と全角空白", in: "  東京　　タワー ", want: "東京 たわー"},
+		{name: "空文字列", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeSearchText(tt.in); got != tt.want {
+				t.Errorf("normalizeSearchText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanaToRomaji(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "とうきょう", want: "toukyou"},
+		{in: "しんばし", want: "shinbashi"},
+		{in: "がっこう", want: "gakkou"},
+		{in: "まっちゃ", want: "matcha"},
+		{in: "らーめん", want: "raamen"},
+		{in: "ふじさん", want: "fujisan"},
+		{in: "東京たわー", want: "東京tawaa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := kanaToRomaji(tt.in); got != tt.want {
+				t.Errorf("kanaToRomaji(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// かな・ローマ字の表記ゆれが同じ比較用の形になる
+func TestCanonicalRomaji(t *testing.T) {
+	canonical := func(s string) string { return canonicalRomaji(normalizeSearchText(s)) }
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "とうきょう", b: "トウキョウ", want: true},
+		{a: "とうきょう", b: "ﾄｳｷｮｳ", want: true},
+		{a: "とうきょう", b: "tokyo", want: true},
+		{a: "toukyou", b: "tokyo", want: true},
+		{a: "Tokyo", b: "ＴＯＫＹＯ", want: true},
+		{a: "しんばし", b: "Shimbashi", want: true},
+		{a: "shinbashi", b: "Shimbashi", want: true},
+		{a: "ふじさん", b: "fujisan", want: true},
+		{a: "ふじさん", b: "huzisan", want: true},
+		{a: "まっちゃ", b: "matcha", want: true},
+		{a: "ラーメン", b: "ramen", want: true},
+		{a: "がっこう", b: "gakko", want: true},
+		{a: "きょうと", b: "tokyo", want: false},
+		{a: "とうきょう", b: "tokio", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			ca, cb := canonical(tt.a), canonical(tt.b)
+			if (ca == cb) != tt.want {
+				t.Errorf("canonical(%q) = %q, canonical(%q) = %q, want equal=%v", tt.a, ca, tt.b, cb, tt.want)
+			}
+		})
+	}
+}
+
+// ハイライト用に、正規化後の各文字から元の位置をたどれる
+func TestNormalizeSearchRunes(t *testing.T) {
+	runes, index := normalizeSearchRunes("Ｔｏ　キョウ")
+	if got, want := string(runes), "to きょう"; got != want {
+		t.Fatalf("runes = %q, want %q", got, want)
+	}
+	want := []int{0, 1, 2, 3, 4, 5}
+	if len(index) != len(want) {
+		t.Fatalf("index = %v, want %v", index, want)
+	}
+	for i := range want {
+		if index[i] != want[i] {
+			t.Errorf("index = %v, want %v", index, want)
+			break
+		}
+	}
+}
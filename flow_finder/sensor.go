@@ -0,0 +1,349 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 入退場カウンター（センサー）
+// 機器はシーケンス番号付きのイベント（期間内の入場数・退場数）をまとめて送信する。
+// 同じシーケンス番号は一度だけ反映し、遅れて届いたイベントや順不同のイベントもそのまま加算する
+// （入退場数の差分は順序に依存しない）。ただし来場者数は0未満にしないため、退場が入場より先に
+// 届いて0を下回る分は切り捨てる。この場合は到着順で人数が変わるため、切り捨てた人数を機器ごとに記録する
+const (
+	sensorMaxBatchEvents = 1000               // 1回の送信で受け付ける最大イベント数
+	sensorMaxEventAge    = 7 * 24 * time.Hour // これより古いイベントは拒否
+	sensorMaxFutureSkew  = 5 * time.Minute    // 時計補正後にこれより未来のイベントは拒否
+	sensorStaleAfter     = 10 * time.Minute   // この間受信がない機器は stale
+	sensorMaxClockDrift  = 60 * time.Second   // 時計のずれがこれを超えた機器は drifting
+)
+
+// 機器の状態
+const (
+	SensorHealthOK       = "ok"
+	SensorHealthStale    = "stale"      // 最近受信がない
+	SensorHealthDrifting = "drifting"   // 時計が大きくずれている
+	SensorHealthNever    = "never_seen" // 一度も受信していない
+	SensorHealthDisabled = "disabled"
+)
+
+// イベントを拒否した理由
+const (
+	SensorRejectUnknownDevice = "unknown_device"
+	SensorRejectDisabled      = "device_disabled"
+	SensorRejectForbidden     = "forbidden" // APIキーの観光地制限の対象外
+	SensorRejectInvalid       = "invalid_event"
+	SensorRejectOutOfRange    = "timestamp_out_of_range"
+)
+
+// 登録済みの機器（観光地に紐付ける）
+type SensorDevice struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	DeviceID       string     `gorm:"not null;uniqueIndex" json:"device_id"` // 機器が送信する識別子
+	Name           string     `json:"name"`                                  // 設置場所など（例: 北門 入口）
+	TouristSpotID  uint       `gorm:"not null;index" json:"tourist_spot_id"`
+	Enabled        bool       `json:"enabled"`                          // 作成時に明示的に設定する
+	LastSeq        int64      `gorm:"default:0" json:"last_seq"`        // 受信済みの最大シーケンス番号
+	LastEventAt    *time.Time `json:"last_event_at"`                    // 受信済みイベントの最新時刻（補正後）
+	LastSeenAt     *time.Time `json:"last_seen_at"`                     // 最後に受信した日時
+	ClockDrift     *float64   `json:"clock_drift_seconds"`              // 機器の時計のずれ（秒、進んでいれば正）。sent_at がある場合のみ
+	AcceptedCount  int64      `gorm:"default:0" json:"accepted_count"`  // 反映したイベント数
+	DuplicateCount int64      `gorm:"default:0" json:"duplicate_count"` // 重複として無視したイベント数
+	LateCount      int64      `gorm:"default:0" json:"late_count"`      // 順不同・遅れて届いたイベント数（反映済み）
+	RejectedCount  int64      `gorm:"default:0" json:"rejected_count"`  // 拒否したイベント数
+	ClampedCount   int64      `gorm:"default:0" json:"clamped_count"`   // 来場者数が0未満にならないよう切り捨てた人数
+	Health         string     `gorm:"-" json:"health"`                  // レスポンス用
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// 受信したイベント（重複排除と混雑履歴に使う）
+type SensorEvent struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	SensorDeviceID uint      `gorm:"not null;uniqueIndex:idx_sensor_event_seq,priority:1" json:"sensor_device_id"`
+	Seq            int64     `gorm:"not null;uniqueIndex:idx_sensor_event_seq,priority:2" json:"seq"`
+	TouristSpotID  uint      `gorm:"not null;index:idx_sensor_event_spot_time,priority:1" json:"tourist_spot_id"`
+	OccurredAt     time.Time `gorm:"not null;index:idx_sensor_event_spot_time,priority:2" json:"occurred_at"` // サーバーの時計に補正した時刻
+	DeviceTime     time.Time `json:"device_time"`                                                             // 機器が送信した時刻
+	InCount        int       `gorm:"not null" json:"in"`
+	OutCount       int       `gorm:"not null" json:"out"`
+	Late           bool      `gorm:"default:false" json:"late"`
+	ReceivedAt     time.Time `gorm:"not null" json:"received_at"`
+}
+
+// 機器の状態を判定
+func (d *SensorDevice) HealthAt(now time.Time) string {
+	switch {
+	case !d.Enabled:
+		return SensorHealthDisabled
+	case d.LastSeenAt == nil:
+		return SensorHealthNever
+	case now.Sub(*d.LastSeenAt) > sensorStaleAfter:
+		return SensorHealthStale
+	case d.ClockDrift != nil && math.Abs(*d.ClockDrift) > sensorMaxClockDrift.Seconds():
+		return SensorHealthDrifting
+	}
+	return SensorHealthOK
+}
+
+// 送信されるイベントのまとまり
+type SensorBatch struct {
+	SentAt *time.Time         `json:"sent_at"` // 機器（またはゲートウェイ）の送信時刻。時計のずれの補正に使う
+	Events []SensorEventInput `json:"events"`
+}
+
+type SensorEventInput struct {
+	DeviceID  string    `json:"device_id"`
+	Seq       int64     `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	In        int       `json:"in"`
+	Out       int       `json:"out"`
+}
+
+// 取り込み結果
+type SensorIngestResult struct {
+	Accepted  int                `json:"accepted"`
+	Duplicate int                `json:"duplicate"`
+	Late      int                `json:"late"`
+	Rejected  int                `json:"rejected"`
+	Clamped   int                `json:"clamped"` // 来場者数が0未満にならないよう切り捨てた人数
+	Errors    []SensorEventError `json:"errors,omitempty"`
+	Spots     []SensorSpotResult `json:"tourist_spots"`
+}
+
+type SensorEventError struct {
+	DeviceID string `json:"device_id"`
+	Seq      int64  `json:"seq"`
+	Reason   string `json:"reason"`
+}
+
+// 観光地ごとの反映結果
+type SensorSpotResult struct {
+	TouristSpotID uint             `json:"tourist_spot_id"`
+	Delta         int              `json:"delta"` // 今回反映した入場数 - 退場数
+	CurrentCount  int              `json:"current_count"`
+	Congestion    CongestionStatus `json:"congestion"`
+}
+
+// 送信内容そのものが不正な場合のエラー
+type SensorBatchError struct {
+	ID   string // メッセージID
+	Args []interface{}
+}
+
+func (e *SensorBatchError) Error() string {
+	return Message(defaultLocale, e.ID, e.Args...)
+}
+
+func (r *SensorIngestResult) reject(ev SensorEventInput, reason string) {
+	r.Rejected++
+	r.Errors = append(r.Errors, SensorEventError{DeviceID: ev.DeviceID, Seq: ev.Seq, Reason: reason})
+}
+
+// イベントを取り込み、観光地の来場者数に反映する
+// canAccessSpot は送信元が機器の観光地を更新できるか（APIキーの観光地制限）を判定する
+func IngestSensorBatch(db *gorm.DB, redisClient *redis.Client, batch SensorBatch, canAccessSpot func(uint) bool) (*SensorIngestResult, error) {
+	if len(batch.Events) == 0 {
+		return nil, &SensorBatchError{ID: "sensor.events_required"}
+	}
+	if len(batch.Events) > sensorMaxBatchEvents {
+		return nil, &SensorBatchError{ID: "sensor.batch_too_large", Args: []interface{}{sensorMaxBatchEvents}}
+	}
+
+	receivedAt := time.Now()
+	var drift *float64
+	if batch.SentAt != nil {
+		d := batch.SentAt.Sub(receivedAt).Seconds()
+		drift = &d
+	}
+
+	// 機器ごとにまとめる（送信順を保つ）
+	var deviceIDs []string
+	byDevice := map[string][]SensorEventInput{}
+	for _, ev := range batch.Events {
+		if _, ok := byDevice[ev.DeviceID]; !ok {
+			deviceIDs = append(deviceIDs, ev.DeviceID)
+		}
+		byDevice[ev.DeviceID] = append(byDevice[ev.DeviceID], ev)
+	}
+
+	var devices []SensorDevice
+	if err := db.Where("device_id IN ?", deviceIDs).Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]SensorDevice, len(devices))
+	for _, d := range devices {
+		known[d.DeviceID] = d
+	}
+
+	result := &SensorIngestResult{Spots: []SensorSpotResult{}}
+	updated := map[uint]*TouristSpot{}
+	deltas := map[uint]int{}
+	var spotOrder []uint
+	var ingestErr error
+	for _, deviceID := range deviceIDs {
+		events := byDevice[deviceID]
+		device, ok := known[deviceID]
+		reason := ""
+		switch {
+		case !ok:
+			reason = SensorRejectUnknownDevice
+		case !device.Enabled:
+			reason = SensorRejectDisabled
+		case canAccessSpot != nil && !canAccessSpot(device.TouristSpotID):
+			reason = SensorRejectForbidden
+		}
+		if reason != "" {
+			for _, ev := range events {
+				result.reject(ev, reason)
+			}
+			if ok {
+				db.Model(&SensorDevice{}).Where("id = ?", device.ID).
+					UpdateColumn("rejected_count", gorm.Expr("rejected_count + ?", len(events)))
+			}
+			continue
+		}
+
+		spot, delta, err := ingestDeviceEvents(db, device.ID, events, drift, receivedAt, result)
+		if err != nil {
+			// 反映済みの機器の分は通知してから失敗を返す（再送された分は重複として無視される）
+			ingestErr = err
+			break
+		}
+		if spot != nil {
+			if _, seen := updated[spot.ID]; !seen {
+				spotOrder = append(spotOrder, spot.ID)
+			}
+			updated[spot.ID] = spot
+			deltas[spot.ID] += delta
+		}
+	}
+
+	service := NewCongestionService(db)
+	for _, spotID := range spotOrder {
+		spot := updated[spotID]
+		result.Spots = append(result.Spots, SensorSpotResult{
+			TouristSpotID: spot.ID,
+			Delta:         deltas[spot.ID],
+			CurrentCount:  spot.CurrentCount,
+			Congestion:    service.SpotStatus(spot),
+		})
+		evaluateCapacityState(db, redisClient, spot, "sensor")
+		notifyCongestionChange(db, redisClient, spot, "sensor", nil)
+		go EvaluateCongestionAlerts(db, spot.ID)
+	}
+	if ingestErr != nil {
+		return nil, ingestErr
+	}
+	return result, nil
+}
+
+// 1台分のイベントを取り込む（機器の行をロックし、同じ機器からの同時送信を直列化する）
+// 来場者数を更新した場合は更新後の観光地と反映した差分を返す
+func ingestDeviceEvents(db *gorm.DB, deviceID uint, events []SensorEventInput, drift *float64, receivedAt time.Time, result *SensorIngestResult) (*TouristSpot, int, error) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+
+	var spot *TouristSpot
+	var delta int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var device SensorDevice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&device, deviceID).Error; err != nil {
+			return err
+		}
+
+		accepted, duplicate, late, rejected := 0, 0, 0, 0
+		lastSeq, lastEventAt := device.LastSeq, device.LastEventAt
+		for _, ev := range events {
+			if ev.Seq < 0 || ev.In < 0 || ev.Out < 0 || ev.Timestamp.IsZero() {
+				result.reject(ev, SensorRejectInvalid)
+				rejected++
+				continue
+			}
+			occurredAt := ev.Timestamp
+			if drift != nil {
+				occurredAt = occurredAt.Add(-time.Duration(*drift * float64(time.Second)))
+			}
+			if occurredAt.Before(receivedAt.Add(-sensorMaxEventAge)) || occurredAt.After(receivedAt.Add(sensorMaxFutureSkew)) {
+				result.reject(ev, SensorRejectOutOfRange)
+				rejected++
+				continue
+			}
+
+			// 既に受信したものより前のシーケンス・時刻のイベントは遅延として記録する（反映はする）
+			isLate := ev.Seq < lastSeq || (lastEventAt != nil && occurredAt.Before(*lastEventAt))
+			record := SensorEvent{
+				SensorDeviceID: device.ID,
+				Seq:            ev.Seq,
+				TouristSpotID:  device.TouristSpotID,
+				OccurredAt:     occurredAt,
+				DeviceTime:     ev.Timestamp,
+				InCount:        ev.In,
+				OutCount:       ev.Out,
+				Late:           isLate,
+				ReceivedAt:     receivedAt,
+			}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				duplicate++
+				continue
+			}
+
+			accepted++
+			delta += ev.In - ev.Out
+			if isLate {
+				late++
+			}
+			if ev.Seq > lastSeq {
+				lastSeq = ev.Seq
+			}
+			if lastEventAt == nil || occurredAt.After(*lastEventAt) {
+				t := occurredAt
+				lastEventAt = &t
+			}
+		}
+
+		updates := map[string]interface{}{
+			"last_seq":        lastSeq,
+			"last_event_at":   lastEventAt,
+			"last_seen_at":    receivedAt,
+			"accepted_count":  gorm.Expr("accepted_count + ?", accepted),
+			"duplicate_count": gorm.Expr("duplicate_count + ?", duplicate),
+			"late_count":      gorm.Expr("late_count + ?", late),
+			"rejected_count":  gorm.Expr("rejected_count + ?", rejected),
+		}
+		if drift != nil {
+			updates["clock_drift"] = *drift
+		}
+		if err := tx.Model(&device).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		result.Accepted += accepted
+		result.Duplicate += duplicate
+		result.Late += late
+
+		if accepted == 0 {
+			return nil
+		}
+		var err error
+		var clamped int
+		spot, clamped, err = ApplySensorVisitorDelta(tx, device.TouristSpotID, delta)
+		if err != nil || clamped == 0 {
+			return err
+		}
+		result.Clamped += clamped
+		return tx.Model(&device).UpdateColumn("clamped_count", gorm.Expr("clamped_count + ?", clamped)).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return spot, delta, nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// センサー関連のルートを登録
+func RegisterSensorRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	sensors := r.Group("/api/sensors")
+	{
+		// 機器一覧と状態（sensors:manage）
+		sensors.GET("", listSensorDevicesHandler(db))
+
+		// 機器の登録
+		sensors.POST("", createSensorDeviceHandler(db))
+
+		// 機器の更新（紐付ける観光地の変更・無効化）
+		sensors.PUT("/:id", updateSensorDeviceHandler(db))
+
+		// 機器の削除（受信済みイベントも削除）
+		sensors.DELETE("/:id", deleteSensorDeviceHandler(db))
+
+		// 受信したイベント（?limit=100）
+		sensors.GET("/:id/events", listSensorEventsHandler(db))
+
+		// イベントの一括送信（congestion:write、APIキーの観光地制限は機器ごとに確認）
+		sensors.POST("/events", ingestSensorEventsHandler(db, redisClient))
+	}
+
+	// MQTTブリッジの接続状態と処理件数
+	r.GET("/api/admin/mqtt/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, mqttMetrics.Snapshot())
+	})
+}
+
+// 機器一覧取得ハンドラ（?tourist_spot_id=&health=stale）
+func listSensorDevicesHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Order("id ASC")
+		if spotID := c.Query("tourist_spot_id"); spotID != "" {
+			query = query.Where("tourist_spot_id = ?", spotID)
+		}
+		var devices []SensorDevice
+		if err := query.Find(&devices).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "sensor.fetch_failed")})
+			return
+		}
+
+		now := time.Now()
+		health := c.Query("health")
+		summary := map[string]int{}
+		result := make([]SensorDevice, 0, len(devices))
+		for _, d := range devices {
+			d.Health = d.HealthAt(now)
+			summary[d.Health]++
+			if health == "" || d.Health == health {
+				result = append(result, d)
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"devices": result, "summary": summary})
+	}
+}
+
+type sensorDeviceRequest struct {
+	DeviceID      *string `json:"device_id"`
+	Name          *string `json:"name"`
+	TouristSpotID *uint   `json:"tourist_spot_id"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+// リクエストの内容を機器に反映（観光地の存在も確認し、不正な場合はメッセージIDを返す）
+func (req *sensorDeviceRequest) apply(db *gorm.DB, device *SensorDevice) string {
+	if req.DeviceID != nil {
+		device.DeviceID = strings.TrimSpace(*req.DeviceID)
+	}
+	if req.Name != nil {
+		device.Name = *req.Name
+	}
+	if req.TouristSpotID != nil {
+		device.TouristSpotID = *req.TouristSpotID
+	}
+	if req.Enabled != nil {
+		device.Enabled = *req.Enabled
+	}
+	if device.DeviceID == "" {
+		return "sensor.device_id_required"
+	}
+	var spot TouristSpot
+	if err := db.Select("id").First(&spot, device.TouristSpotID).Error; err != nil {
+		return "spot.not_found"
+	}
+	return ""
+}
+
+// 機器登録ハンドラ
+func createSensorDeviceHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req sensorDeviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		device := SensorDevice{Enabled: true}
+		if id := req.apply(db, &device); id != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, id)})
+			return
+		}
+
+		var count int64
+		db.Model(&SensorDevice{}).Where("device_id = ?", device.DeviceID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": T(c, "sensor.device_id_taken")})
+			return
+		}
+		if err := db.Create(&device).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "sensor.create_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "sensor_devices", strconv.Itoa(int(device.ID)), currentUserIDPtr(c), "create", nil, device)
+		device.Health = device.HealthAt(time.Now())
+		c.JSON(http.StatusCreated, device)
+	}
+}
+
+// 機器更新ハンドラ
+func updateSensorDeviceHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var device SensorDevice
+		if err := db.First(&device, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "sensor.not_found")})
+			return
+		}
+		before := device
+
+		var req sensorDeviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if id := req.apply(db, &device); id != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, id)})
+			return
+		}
+		if device.DeviceID != before.DeviceID {
+			var count int64
+			db.Model(&SensorDevice{}).Where("device_id = ? AND id <> ?", device.DeviceID, device.ID).Count(&count)
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": T(c, "sensor.device_id_taken")})
+				return
+			}
+		}
+
+		if err := db.Model(&device).Select("device_id", "name", "tourist_spot_id", "enabled").Updates(&device).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "sensor.update_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "sensor_devices", strconv.Itoa(int(device.ID)), currentUserIDPtr(c), "update", before, device)
+		device.Health = device.HealthAt(time.Now())
+		c.JSON(http.StatusOK, device)
+	}
+}
+
+// 機器削除ハンドラ
+func deleteSensorDeviceHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var device SensorDevice
+		if err := db.First(&device, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "sensor.not_found")})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("sensor_device_id = ?", device.ID).Delete(&SensorEvent{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&device).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "sensor.delete_failed")})
+			return
+		}
+
+		RecordChangeHistory(db, "sensor_devices", strconv.Itoa(int(device.ID)), currentUserIDPtr(c), "delete", device, nil)
+		c.JSON(http.StatusOK, gin.H{"message": "センサーを削除しました"})
+	}
+}
+
+// 受信イベント一覧ハンドラ（新しい順）
+func listSensorEventsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var device SensorDevice
+		if err := db.First(&device, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "sensor.not_found")})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil || limit <= 0 || limit > 1000 {
+			limit = 100
+		}
+
+		var events []SensorEvent
+		if err := db.Where("sensor_device_id = ?", device.ID).Order("received_at DESC, seq DESC").Limit(limit).Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "sensor.events_fetch_failed")})
+			return
+		}
+		device.Health = device.HealthAt(time.Now())
+		c.JSON(http.StatusOK, gin.H{"device": device, "events": events})
+	}
+}
+
+// イベント一括送信ハンドラ
+// 同じ (device_id, seq) は一度だけ反映されるため、失敗時は同じ内容をそのまま再送してよい
+func ingestSensorEventsHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var batch SensorBatch
+		if err := c.ShouldBindJSON(&batch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+
+		principal := principalFromContext(c)
+		canAccessSpot := func(spotID uint) bool {
+			return principal == nil || principal.CanAccessSpot(spotID)
+		}
+		result, err := IngestSensorBatch(db, redisClient, batch, canAccessSpot)
+		if err != nil {
+			var batchErr *SensorBatchError
+			if errors.As(err, &batchErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, batchErr.ID, batchErr.Args...)})
+				return
+			}
+			fmt.Printf("⚠️ センサーイベントの取り込みに失敗: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "sensor.ingest_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// トークンの有効期限
+const (
+	AccessTokenTTL  = time.Hour
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ログインセッション（Redisに保存）
+type AuthSession struct {
+	ID         string    `json:"id"`
+	UserID     uint      `json:"user_id"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+
+	tokenType      string
+	tokenID        string // opaqueはトークン文字列、JWTはjti
+	tokenExpiresAt time.Time
+	refreshToken   string
+}
+
+// 発行したトークン一式
+type IssuedTokens struct {
+	SessionID    string
+	Token        string
+	TokenType    string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+func sessionKey(sessionID string) string    { return "auth_session:" + sessionID }
+func userSessionsKey(userID uint) string    { return fmt.Sprintf("user_sessions:%d", userID) }
+func tokenSessionKey(tokenID string) string { return "auth_token_sid:" + tokenID }
+func refreshTokenKey(refresh string) string { return "refresh_token:" + refresh }
+func accessTokenKey(token string) string    { return "auth_token:" + token }
+func jwtDenylistKey(jti string) string      { return "jwt_denylist:" + jti }
+
+// パスワードの変更が必要なユーザー（管理者によるリセット後など）
+func mustChangePasswordKey(userID uint) string { return fmt.Sprintf("must_change_password:%d", userID) }
+
+// 新しいセッションを作成し、アクセストークンとリフレッシュトークンを発行する
+func CreateSession(ctx context.Context, client *redis.Client, user *User, ip, userAgent string) (*IssuedTokens, error) {
+	sessionID, err := GenerateToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &AuthSession{
+		ID:         sessionID,
+		UserID:     user.ID,
+		IPAddress:  ip,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	return issueSessionTokens(ctx, client, session, user)
+}
+
+// 設定されたモードでアクセストークンを発行する
+func issueAccessToken(ctx context.Context, client *redis.Client, session *AuthSession, user *User) (string, error) {
+	session.tokenExpiresAt = time.Now().Add(AccessTokenTTL)
+
+	if authConfig.Mode == AuthModeJWT {
+		jti, err := GenerateToken(16)
+		if err != nil {
+			return "", err
+		}
+		token, err := authConfig.JWTKeys.Sign(&JWTClaims{
+			ID:        jti,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Issuer:    authConfig.JWTIssuer,
+			UserID:    user.ID,
+			Roles:     user.RoleNames(),
+			SessionID: session.ID,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: session.tokenExpiresAt.Unix(),
+		})
+		if err != nil {
+			return "", err
+		}
+		session.tokenType = AuthModeJWT
+		session.tokenID = jti
+		return token, nil
+	}
+
+	token, err := GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	// アクセストークンは従来通りauth_token:<token>にユーザーIDを保存
+	if err := SaveTokenToRedis(ctx, client, user.ID, token, AccessTokenTTL); err != nil {
+		return "", err
+	}
+	session.tokenType = AuthModeOpaque
+	session.tokenID = token
+	return token, nil
+}
+
+// セッションに新しいトークンを割り当てて保存する
+func issueSessionTokens(ctx context.Context, client *redis.Client, session *AuthSession, user *User) (*IssuedTokens, error) {
+	token, err := issueAccessToken(ctx, client, session, user)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+	session.refreshToken = refresh
+	session.ExpiresAt = time.Now().Add(RefreshTokenTTL)
+
+	pipe := client.TxPipeline()
+	pipe.Set(ctx, tokenSessionKey(session.tokenID), session.ID, AccessTokenTTL)
+	pipe.Set(ctx, refreshTokenKey(refresh), session.ID, RefreshTokenTTL)
+	pipe.HSet(ctx, sessionKey(session.ID), map[string]interface{}{
+		"user_id":          session.UserID,
+		"token_type":       session.tokenType,
+		"token":            session.tokenID,
+		"token_expires_at": session.tokenExpiresAt.Unix(),
+		"refresh_token":    refresh,
+		"ip_address":       session.IPAddress,
+		"user_agent":       session.UserAgent,
+		"created_at":       session.CreatedAt.Unix(),
+		"last_used_at":     session.LastUsedAt.Unix(),
+		"expires_at":       session.ExpiresAt.Unix(),
+	})
+	pipe.Expire(ctx, sessionKey(session.ID), RefreshTokenTTL)
+	pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+	pipe.Expire(ctx, userSessionsKey(session.UserID), RefreshTokenTTL)
+	// トークンを発行するたびにDBの変更要求フラグを反映する（認証ミドルウェアで変更以外のAPIを拒否する）
+	if user.MustChangePassword {
+		pipe.Set(ctx, mustChangePasswordKey(user.ID), "1", RefreshTokenTTL)
+	} else {
+		pipe.Del(ctx, mustChangePasswordKey(user.ID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return &IssuedTokens{
+		SessionID:    session.ID,
+		Token:        token,
+		TokenType:    session.tokenType,
+		RefreshToken: refresh,
+		ExpiresIn:    AccessTokenTTL,
+	}, nil
+}
+
+// アクセストークンを無効化するコマンドをパイプラインに追加
+// JWTは失効リストに登録し、元の有効期限まで保持する
+func revokeAccessToken(ctx context.Context, pipe redis.Pipeliner, session *AuthSession) {
+	if session.tokenType == AuthModeJWT {
+		if remaining := time.Until(session.tokenExpiresAt); remaining > 0 {
+			pipe.Set(ctx, jwtDenylistKey(session.tokenID), 1, remaining)
+		}
+	} else {
+		pipe.Del(ctx, accessTokenKey(session.tokenID))
+	}
+	pipe.Del(ctx, tokenSessionKey(session.tokenID))
+}
+
+// セッション情報を取得
+func GetSession(ctx context.Context, client *redis.Client, sessionID string) (*AuthSession, error) {
+	values, err := client.HGetAll(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	userID, _ := strconv.ParseUint(values["user_id"], 10, 32)
+	parseUnix := func(s string) time.Time {
+		n, _ := strconv.ParseInt(s, 10, 64)
+		return time.Unix(n, 0)
+	}
+	tokenType := values["token_type"]
+	if tokenType == "" {
+		tokenType = AuthModeOpaque
+	}
+	return &AuthSession{
+		ID:             sessionID,
+		UserID:         uint(userID),
+		IPAddress:      values["ip_address"],
+		UserAgent:      values["user_agent"],
+		CreatedAt:      parseUnix(values["created_at"]),
+		LastUsedAt:     parseUnix(values["last_used_at"]),
+		ExpiresAt:      parseUnix(values["expires_at"]),
+		tokenType:      tokenType,
+		tokenID:        values["token"],
+		tokenExpiresAt: parseUnix(values["token_expires_at"]),
+		refreshToken:   values["refresh_token"],
+	}, nil
+}
+
+// アクセストークン（JWTの場合はjti）からセッションIDを取得
+func SessionIDFromToken(ctx context.Context, client *redis.Client, tokenID string) (string, error) {
+	sessionID, err := client.Get(ctx, tokenSessionKey(tokenID)).Result()
+	if err == redis.Nil {
+		return "", ErrSessionNotFound
+	}
+	return sessionID, err
+}
+
+// リフレッシュトークンを使ってトークンを再発行する（リフレッシュトークンもローテーション）
+func RefreshSession(ctx context.Context, db *gorm.DB, client *redis.Client, refreshToken string) (*IssuedTokens, error) {
+	sessionID, err := client.GetDel(ctx, refreshTokenKey(refreshToken)).Result()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := GetSession(ctx, client, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// 使用済みトークンの再利用を防ぐため一致を確認
+	if session.refreshToken != refreshToken {
+		return nil, ErrSessionNotFound
+	}
+
+	// ロールの変更を反映するためユーザーを再取得（削除済みならセッションを破棄）
+	var user User
+	if err := db.Preload("Roles").First(&user, session.UserID).Error; err != nil {
+		RevokeSession(ctx, client, sessionID)
+		return nil, ErrSessionNotFound
+	}
+
+	// 古いアクセストークンを無効化
+	pipe := client.TxPipeline()
+	revokeAccessToken(ctx, pipe, session)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	session.LastUsedAt = time.Now()
+	return issueSessionTokens(ctx, client, session, &user)
+}
+
+// 指定ユーザーのセッション一覧を取得（期限切れのものは索引から削除）
+func ListUserSessions(ctx context.Context, client *redis.Client, userID uint) ([]AuthSession, error) {
+	ids, err := client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]AuthSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := GetSession(ctx, client, id)
+		if err == ErrSessionNotFound {
+			client.SRem(ctx, userSessionsKey(userID), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// セッションを失効させる（関連するトークンを全て無効化）
+func RevokeSession(ctx context.Context, client *redis.Client, sessionID string) error {
+	session, err := GetSession(ctx, client, sessionID)
+	if err != nil {
+		return err
+	}
+	pipe := client.TxPipeline()
+	revokeAccessToken(ctx, pipe, session)
+	pipe.Del(ctx, refreshTokenKey(session.refreshToken), sessionKey(sessionID))
+	pipe.SRem(ctx, userSessionsKey(session.UserID), sessionID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ユーザーの全セッションを失効させる（exceptで指定したセッションは残す）
+func RevokeUserSessions(ctx context.Context, client *redis.Client, userID uint, except string) (int, error) {
+	ids, err := client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, id := range ids {
+		if id == except {
+			continue
+		}
+		if err := RevokeSession(ctx, client, id); err != nil {
+			if err == ErrSessionNotFound {
+				client.SRem(ctx, userSessionsKey(userID), id)
+				continue
+			}
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// セッション管理関連のルートを登録
+func RegisterSessionRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	// ログアウト（現在のトークンを削除）
+	r.POST("/api/logout", logoutHandler(db, redisClient))
+
+	// リフレッシュトークンでトークンを再発行
+	r.POST("/api/token/refresh", refreshTokenHandler(db, redisClient))
+
+	// 自分のセッション一覧
+	r.GET("/api/sessions", listSessionsHandler(redisClient))
+
+	// 自分のセッションを失効
+	r.DELETE("/api/sessions/:id", revokeSessionHandler(redisClient))
+
+	// 指定ユーザーの全セッションを失効（管理者専用）
+	r.DELETE("/api/users/:id/sessions", revokeUserSessionsHandler(redisClient))
+}
+
+// 現在のリクエストに対応するセッションIDを取得
+func currentSessionID(c *gin.Context, redisClient *redis.Client) string {
+	// JWTはクレームにセッションIDを含む
+	if p := principalFromContext(c); p != nil && p.SessionID != "" {
+		return p.SessionID
+	}
+	token, ok := GetAuthTokenFromContext(c)
+	if !ok {
+		return ""
+	}
+	sessionID, err := SessionIDFromToken(context.Background(), redisClient, token)
+	if err != nil {
+		return ""
+	}
+	return sessionID
+}
+
+// ログアウトハンドラ
+func logoutHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.Background()
+		principal := principalFromContext(c)
+		userID := principal.UserID
+
+		err := ErrSessionNotFound
+		if sessionID := currentSessionID(c, redisClient); sessionID != "" {
+			err = RevokeSession(ctx, redisClient, sessionID)
+		}
+		switch {
+		case err == ErrSessionNotFound:
+			// セッション情報がないトークンは単体で無効化
+			pipe := redisClient.TxPipeline()
+			revokeAccessToken(ctx, pipe, &AuthSession{
+				tokenType:      principal.TokenType,
+				tokenID:        principal.TokenID,
+				tokenExpiresAt: principal.ExpiresAt,
+			})
+			if _, err := pipe.Exec(ctx); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+				return
+			}
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+
+		sessionID := c.GetHeader("X-Session-Id")
+		if sessionID == "" {
+			sessionID = generateHandlerSessionID()
+		}
+		LogUserActivity(db, UserLog{
+			UserID:    &userID,
+			SessionID: sessionID,
+			LogType:   LogTypeAction,
+			Category:  CategoryAuth,
+			Action:    ActionLogout,
+			Path:      "/logout",
+			UserAgent: c.Request.UserAgent(),
+			IPAddress: c.ClientIP(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"result": "ok"})
+	}
+}
+
+// トークン再発行ハンドラ
+func refreshTokenHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			RefreshToken string `json:"refresh_token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
+			return
+		}
+
+		tokens, err := RefreshSession(context.Background(), db, redisClient, req.RefreshToken)
+		if err == ErrSessionNotFound {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.refresh_token_invalid")})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+
+		session, err := GetSession(context.Background(), redisClient, tokens.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"token":           tokens.Token,
+			"token_type":      tokens.TokenType,
+			"refresh_token":   tokens.RefreshToken,
+			"expires_in":      int(tokens.ExpiresIn.Seconds()),
+			"auth_session_id": tokens.SessionID,
+			"user_id":         session.UserID,
+		})
+	}
+}
+
+// セッション一覧ハンドラ
+func listSessionsHandler(redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := GetUserIDFromContext(c)
+		sessions, err := ListUserSessions(context.Background(), redisClient, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "session.fetch_failed")})
+			return
+		}
+
+		current := currentSessionID(c, redisClient)
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID == current
+		}
+		c.JSON(http.StatusOK, sessions)
+	}
+}
+
+// 自分のセッション失効ハンドラ
+func revokeSessionHandler(redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.Background()
+		userID, _ := GetUserIDFromContext(c)
+		sessionID := c.Param("id")
+
+		session, err := GetSession(ctx, redisClient, sessionID)
+		// 他人のセッションは存在しないものとして扱う
+		if err == ErrSessionNotFound || (err == nil && session.UserID != userID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "session.not_found")})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "common.redis_error")})
+			return
+		}
+
+		if err := RevokeSession(ctx, redisClient, sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "session.revoke_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": "ok"})
+	}
+}
+
+// 指定ユーザーの全セッション失効ハンドラ
+func revokeUserSessionsHandler(redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "user.invalid_id")})
+			return
+		}
+
+		revoked, err := RevokeUserSessions(context.Background(), redisClient, uint(userID), "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "session.revoke_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "revoked": revoked})
+	}
+}
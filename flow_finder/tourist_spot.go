@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"math"
 	"time"
 
@@ -12,6 +11,8 @@ import (
 type TouristSpot struct {
 	ID              uint                 `gorm:"primaryKey" json:"id"`
 	Name            string               `gorm:"not null" json:"name"`                                                        // 観光地名
+	ExternalKey     *string              `gorm:"uniqueIndex" json:"external_key,omitempty"`                                   // 外部キー（一括取り込みで照合に使う）
+	NameKana        string               `json:"name_kana"`                                                                   // 読み仮名（検索用）
 	Description     string               `json:"description"`                                                                 // 説明
 	Category        string               `json:"category"`                                                                    // 旧カテゴリ（後方互換性のため残す）
 	CategoryID      *uint                `gorm:"index;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"category_id"`     // カテゴリID（外部キー）
@@ -25,8 +26,9 @@ type TouristSpot struct {
 	CurrentCount    int                  `gorm:"default:0" json:"current_count"`                                              // 現在の人数
 	WaitTime        int                  `gorm:"default:0" json:"wait_time"`                                                  // 待ち時間（分）
 	IsOpen          bool                 `gorm:"default:true" json:"is_open"`                                                 // 営業中かどうか
-	OpeningTime     string               `json:"opening_time"`                                                                // 開場時間 (例: "09:00")
+	OpeningTime     string               `json:"opening_time"`                                                                // 開場時間 (例: "09:00")。曜日ごとの営業時間がない場合に毎日の営業時間として使う
 	ClosingTime     string               `json:"closing_time"`                                                                // 閉場時間 (例: "18:00")
+	TimeZone        string               `json:"time_zone"`                                                                   // 営業時間のタイムゾーン（空の場合は Asia/Tokyo）
 	EntryFee        int                  `json:"entry_fee"`                                                                   // 入場料（円）
 	Website         string               `json:"website"`                                                                     // 公式サイト
 	PhoneNumber     string               `json:"phone_number"`                                                                // 電話番号
@@ -37,30 +39,19 @@ type TouristSpot struct {
 	LastUpdated     time.Time            `gorm:"autoUpdateTime" json:"last_updated"`                                          // 最終更新日時
 	CreatedAt       time.Time            `json:"created_at"`
 	UpdatedAt       time.Time            `json:"updated_at"`
+	Congestion      *CongestionStatus    `gorm:"-" json:"congestion,omitempty"` // 混雑状況（CongestionServiceで設定）
+
+	OpeningHours       []SpotOpeningHours      `gorm:"foreignKey:TouristSpotID;constraint:OnDelete:CASCADE" json:"opening_hours,omitempty"`       // 曜日ごとの営業時間
+	ScheduleExceptions []SpotScheduleException `gorm:"foreignKey:TouristSpotID;constraint:OnDelete:CASCADE" json:"schedule_exceptions,omitempty"` // 日付指定の例外（終了済みのものは読み込まない）
+	OpenStatus         *OpenStatus             `gorm:"-" json:"open_status,omitempty"`                                                            // 現在の営業状況
+	Distance           *float64                `gorm:"-" json:"distance,omitempty"`                                                               // near_node からの距離（一覧で指定した場合）
+	Capacity           *CapacityStatus         `gorm:"-" json:"capacity,omitempty"`                                                               // 入場状況（詳細で設定）
+	Alternatives       []TouristSpot           `gorm:"-" json:"alternatives,omitempty"`                                                           // 混雑・満員の場合の近くの代替観光地
 }
 
-// 混雑状況を計算するメソッド
+// 混雑状況の表示名（判定は CongestionFromCounts に集約）
 func (ts *TouristSpot) GetCongestionLevel() string {
-	if ts.MaxCapacity == 0 {
-		return "不明"
-	}
-
-	ratio := float64(ts.CurrentCount) / float64(ts.MaxCapacity)
-
-	switch {
-	case ratio >= 1.0:
-		return "満員"
-	case ratio >= 0.8:
-		return "非常に混雑"
-	case ratio >= 0.6:
-		return "混雑"
-	case ratio >= 0.4:
-		return "普通"
-	case ratio >= 0.2:
-		return "少し空いている"
-	default:
-		return "空いている"
-	}
+	return CongestionFromCounts(ts.CurrentCount, ts.MaxCapacity).Label
 }
 
 // 混雑率を取得するメソッド（パーセンテージ）
@@ -71,55 +62,9 @@ func (ts *TouristSpot) GetCongestionRatio() float64 {
 	return float64(ts.CurrentCount) / float64(ts.MaxCapacity) * 100
 }
 
-// 営業中かどうかを確認するメソッド
+// 営業中かどうかを確認するメソッド（営業時間スケジュールを観光地のタイムゾーンで評価する）
 func (ts *TouristSpot) IsCurrentlyOpen() bool {
-	if !ts.IsOpen {
-		return false
-	}
-
-	if ts.OpeningTime == "" || ts.ClosingTime == "" {
-		return ts.IsOpen
-	}
-
-	now := time.Now()
-	currentTime := now.Format("15:04")
-
-	return currentTime >= ts.OpeningTime && currentTime <= ts.ClosingTime
-}
-
-// 人数を増加させるメソッド
-func (ts *TouristSpot) AddVisitors(count int) bool {
-	if ts.CurrentCount+count > ts.MaxCapacity {
-		return false // 許容人数を超える場合は追加不可
-	}
-	ts.CurrentCount += count
-	return true
-}
-
-// 人数を減少させるメソッド
-func (ts *TouristSpot) RemoveVisitors(count int) {
-	ts.CurrentCount -= count
-	if ts.CurrentCount < 0 {
-		ts.CurrentCount = 0
-	}
-}
-
-// 来場者数を増加させる
-func (ts *TouristSpot) IncrementVisitors(count int) error {
-	if ts.CurrentCount+count > ts.MaxCapacity {
-		return fmt.Errorf("許容人数を超過します（現在: %d, 増加: %d, 最大: %d）", ts.CurrentCount, count, ts.MaxCapacity)
-	}
-	ts.CurrentCount += count
-	return nil
-}
-
-// 来場者数を減少させる
-func (ts *TouristSpot) DecrementVisitors(count int) error {
-	if ts.CurrentCount-count < 0 {
-		return fmt.Errorf("現在の人数を下回ることはできません（現在: %d, 減少: %d）", ts.CurrentCount, count)
-	}
-	ts.CurrentCount -= count
-	return nil
+	return ts.OpenStatusAt(time.Now()).IsOpen
 }
 
 // データベースマイグレーション
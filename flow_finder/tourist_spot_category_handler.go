@@ -37,10 +37,11 @@ func getTouristSpotCategoriesHandler(db *gorm.DB) gin.HandlerFunc {
 		if err := db.Preload("Group").Where("is_active = ?", true).
 			Order("display_order ASC, created_at ASC").
 			Find(&categories).Error; err != nil {
-			c.JSON(500, gin.H{"error": "カテゴリの取得に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "category.fetch_failed")})
 			return
 		}
 
+		TranslateCategories(db, RequestLocale(c), categories)
 		c.JSON(200, categories)
 	}
 }
@@ -53,14 +54,16 @@ func getTouristSpotCategoryHandler(db *gorm.DB) gin.HandlerFunc {
 
 		if err := db.First(&category, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(404, gin.H{"error": "カテゴリが見つかりません"})
+				c.JSON(404, gin.H{"error": T(c, "category.not_found")})
 			} else {
-				c.JSON(500, gin.H{"error": "カテゴリの取得に失敗しました"})
+				c.JSON(500, gin.H{"error": T(c, "category.fetch_failed")})
 			}
 			return
 		}
 
-		c.JSON(200, category)
+		categories := []TouristSpotCategory{category}
+		TranslateCategories(db, RequestLocale(c), categories)
+		c.JSON(200, categories[0])
 	}
 }
 
@@ -78,7 +81,7 @@ func touristSpotCategoryCreateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
@@ -97,13 +100,14 @@ func touristSpotCategoryCreateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := db.Create(&category).Error; err != nil {
-			c.JSON(500, gin.H{"error": "カテゴリの作成に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "category.create_failed"), "details": err.Error()})
 			return
 		}
 
 		// 変更履歴を記録
 		var userID *uint = nil
 		RecordChangeHistory(db, "tourist_spot_categories", strconv.Itoa(int(category.ID)), userID, "create", nil, category)
+		refreshSearchIndex(db, SearchTypeCategory, category.ID)
 
 		c.JSON(201, gin.H{"result": "ok", "category": category})
 	}
@@ -116,7 +120,7 @@ func touristSpotCategoryUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 		var category TouristSpotCategory
 
 		if err := db.First(&category, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "カテゴリが見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "category.not_found")})
 			return
 		}
 
@@ -134,7 +138,7 @@ func touristSpotCategoryUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
@@ -166,13 +170,14 @@ func touristSpotCategoryUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := db.Save(&category).Error; err != nil {
-			c.JSON(500, gin.H{"error": "カテゴリの更新に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "category.update_failed"), "details": err.Error()})
 			return
 		}
 
 		// 変更履歴を記録
 		var userID *uint = nil
 		RecordChangeHistory(db, "tourist_spot_categories", id, userID, "update", beforeCategory, category)
+		refreshSearchIndex(db, SearchTypeCategory, category.ID)
 
 		c.JSON(200, gin.H{"result": "ok", "category": category})
 	}
@@ -184,7 +189,7 @@ func touristSpotCategoryDeleteHandler(db *gorm.DB) gin.HandlerFunc {
 		id := c.Param("id")
 		categoryID, err := strconv.ParseUint(id, 10, 32)
 		if err != nil {
-			c.JSON(400, gin.H{"error": "無効なIDです"})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_id")})
 			return
 		}
 
@@ -194,7 +199,7 @@ func touristSpotCategoryDeleteHandler(db *gorm.DB) gin.HandlerFunc {
 
 		if touristSpotCount > 0 {
 			c.JSON(400, gin.H{
-				"error": "このカテゴリを使用している観光地が存在するため削除できません",
+				"error": T(c, "category.in_use"),
 				"count": touristSpotCount,
 			})
 			return
@@ -206,13 +211,15 @@ func touristSpotCategoryDeleteHandler(db *gorm.DB) gin.HandlerFunc {
 
 		// カテゴリを削除
 		if err := db.Delete(&TouristSpotCategory{}, categoryID).Error; err != nil {
-			c.JSON(500, gin.H{"error": "カテゴリの削除に失敗しました", "details": err.Error()})
+			c.JSON(500, gin.H{"error": T(c, "category.delete_failed"), "details": err.Error()})
 			return
 		}
 
 		// 変更履歴を記録
 		var userID *uint = nil
 		RecordChangeHistory(db, "tourist_spot_categories", id, userID, "delete", category, nil)
+		refreshSearchIndex(db, SearchTypeCategory, uint(categoryID))
+		DeleteTranslations(db, TranslationTypeCategory, uint(categoryID))
 
 		c.JSON(200, gin.H{"result": "ok", "message": "カテゴリを削除しました"})
 	}
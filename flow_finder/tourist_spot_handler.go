@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
@@ -13,66 +14,91 @@ import (
 func RegisterTouristSpotRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	// 観光地一覧取得
 	r.GET("/api/tourist-spots", func(c *gin.Context) {
-		var spots []TouristSpot
-		query := db.Model(&TouristSpot{}).Preload("TouristCategory") // カテゴリ情報をプリロード
-
-		// カテゴリフィルタ
-		if category := c.Query("category"); category != "" {
-			query = query.Where("category = ?", category)
-		}
-
-		// 営業中のみフィルタ
-		if open := c.Query("open"); open == "true" {
-			query = query.Where("is_open = ?", true)
+		// 絞り込み・並べ替え・ページングの条件（tourist_spot_query.go）
+		listQuery, err := ParseTouristSpotListQuery(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(400, gin.H{"error": errorText(c, err)})
+			return
 		}
 
-		if err := query.Find(&spots).Error; err != nil {
-			c.JSON(500, gin.H{"error": "データ取得エラー"})
+		// 営業状況・混雑度・距離による絞り込みと並べ替え（営業時間は観光地ごとのタイムゾーンで判定する）
+		// 名前の並べ替えは翻訳後の名前で行う
+		spots, nextCursor, err := listQuery.Find(db, RequestLocale(c), time.Now())
+		if err != nil {
+			var localized *LocalizedError
+			if errors.As(err, &localized) {
+				c.JSON(400, gin.H{"error": errorText(c, err)})
+				return
+			}
+			c.JSON(500, gin.H{"error": T(c, "common.fetch_failed")})
 			return
 		}
+		if nextCursor != "" {
+			c.Header(spotCursorHeader, nextCursor)
+		}
 
-		// 最寄りノードが設定されていない観光地を自動設定
-		for i := range spots {
-			if spots[i].NodeID == nil {
-				nearestNodeID, err := spots[i].FindNearestNode(db)
-				if err == nil && nearestNodeID > 0 {
-					spots[i].NodeID = &nearestNodeID
-					// データベースを更新
-					db.Model(&spots[i]).Update("node_id", nearestNodeID)
-				}
+		if len(listQuery.Fields) > 0 {
+			items, err := listQuery.Project(spots)
+			if err != nil {
+				c.JSON(500, gin.H{"error": T(c, "common.fetch_failed")})
+				return
 			}
+			c.JSON(200, items)
+			return
 		}
-
 		c.JSON(200, spots)
 	})
 
+	// 最寄りノードの一括設定（?all=true で設定済みの観光地も再計算）
+	r.POST("/api/admin/tourist-spots/nearest-nodes", func(c *gin.Context) {
+		updated, err := BackfillNearestNodes(db, c.Query("all") == "true")
+		if err != nil {
+			c.JSON(500, gin.H{"error": T(c, "spot.nearest_nodes_failed")})
+			return
+		}
+		RecordChangeHistory(db, "tourist_spots", "nearest_nodes", currentUserIDPtr(c), "backfill", nil, gin.H{"updated": updated})
+		c.JSON(200, gin.H{"result": "ok", "updated": updated})
+	})
+
 	// 観光地詳細取得
 	r.GET("/api/tourist-spots/:id", func(c *gin.Context) {
 		id := c.Param("id")
 		var spot TouristSpot
-		if err := db.Preload("Node").Preload("TouristCategory").First(&spot, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "観光地が見つかりません"})
+		if err := preloadSpotSchedule(db).Preload("Node").Preload("TouristCategory").First(&spot, id).Error; err != nil {
+			c.JSON(404, gin.H{"error": T(c, "spot.not_found")})
+			return
+		}
+		status := NewCongestionService(db).SpotStatus(&spot)
+		spot.Congestion = &status
+		openStatus := spot.OpenStatusAt(time.Now())
+		spot.OpenStatus = &openStatus
+		// 混雑・満員の場合は近くの代替観光地も返す
+		if err := FillSpotCapacity(db, &spot); err != nil {
+			c.JSON(500, gin.H{"error": T(c, "capacity.fetch_failed")})
 			return
 		}
-		c.JSON(200, spot)
+		spots := []TouristSpot{spot}
+		TranslateSpots(db, RequestLocale(c), spots)
+		TranslateSpots(db, RequestLocale(c), spots[0].Alternatives)
+		c.JSON(200, spots[0])
 	})
 
 	// 観光地作成（管理者専用）
-	r.POST("/api/tourist-spots", AdminRequired(db, redisClient), touristSpotCreateHandler(db))
+	r.POST("/api/tourist-spots", touristSpotCreateHandler(db))
 
 	// 観光地更新（管理者専用）
-	r.PUT("/api/tourist-spots/:id", AdminRequired(db, redisClient), touristSpotUpdateHandler(db))
+	r.PUT("/api/tourist-spots/:id", touristSpotUpdateHandler(db, redisClient))
 
 	// 観光地削除（管理者専用）
-	r.DELETE("/api/tourist-spots/:id", AdminRequired(db, redisClient), touristSpotDeleteHandler(db))
+	r.DELETE("/api/tourist-spots/:id", touristSpotDeleteHandler(db))
 
 	// 観光地の来場者数管理
-	r.POST("/api/tourist-spots/:id/visitors", touristSpotVisitorHandler(db))
+	r.POST("/api/tourist-spots/:id/visitors", touristSpotVisitorHandler(db, redisClient))
 
 	// 観光地の混雑状況取得
 	r.GET("/api/tourist-spots/:id/congestion", touristSpotCongestionHandler(db))
 	// 管理者が混雑レベルを記録する（時刻付き保存）
-	r.POST("/api/tourist-spots/:id/congestion", AdminRequired(db, redisClient), touristSpotSetCongestionHandler(db))
+	r.POST("/api/tourist-spots/:id/congestion", touristSpotSetCongestionHandler(db, redisClient))
 }
 
 // 観光地作成ハンドラ
@@ -80,6 +106,7 @@ func touristSpotCreateHandler(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req struct {
 			Name         string  `json:"name" binding:"required"`
+			NameKana     string  `json:"name_kana"`
 			Description  string  `json:"description"`
 			Category     string  `json:"category"`
 			CategoryID   *uint   `json:"category_id"`
@@ -91,6 +118,7 @@ func touristSpotCreateHandler(db *gorm.DB) gin.HandlerFunc {
 			IsOpen       bool    `json:"is_open"`
 			OpeningTime  string  `json:"opening_time"`
 			ClosingTime  string  `json:"closing_time"`
+			TimeZone     string  `json:"time_zone"`
 			EntryFee     int     `json:"entry_fee"`
 			Website      string  `json:"website"`
 			PhoneNumber  string  `json:"phone_number"`
@@ -99,12 +127,17 @@ func touristSpotCreateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if _, err := loadSpotLocation(req.TimeZone); err != nil {
+			c.JSON(400, gin.H{"error": errorText(c, err)})
 			return
 		}
 
 		spot := TouristSpot{
 			Name:         req.Name,
+			NameKana:     req.NameKana,
 			Description:  req.Description,
 			Category:     req.Category,
 			CategoryID:   req.CategoryID,
@@ -115,6 +148,7 @@ func touristSpotCreateHandler(db *gorm.DB) gin.HandlerFunc {
 			IsOpen:       req.IsOpen,
 			OpeningTime:  req.OpeningTime,
 			ClosingTime:  req.ClosingTime,
+			TimeZone:     req.TimeZone,
 			EntryFee:     req.EntryFee,
 			Website:      req.Website,
 			PhoneNumber:  req.PhoneNumber,
@@ -140,7 +174,7 @@ func touristSpotCreateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := db.Create(&spot).Error; err != nil {
-			c.JSON(500, gin.H{"error": "観光地作成に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "spot.create_failed")})
 			return
 		}
 
@@ -154,18 +188,19 @@ func touristSpotCreateHandler(db *gorm.DB) gin.HandlerFunc {
 
 		// 変更履歴を記録
 		RecordChangeHistory(db, "tourist_spots", strconv.Itoa(int(spot.ID)), userID, "create", nil, spot)
+		refreshSearchIndex(db, SearchTypeSpot, spot.ID)
 
 		c.JSON(201, gin.H{"result": "ok", "id": spot.ID, "spot": spot})
 	}
 }
 
 // 観光地更新ハンドラ
-func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
+func touristSpotUpdateHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		var spot TouristSpot
 		if err := db.First(&spot, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "観光地が見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "spot.not_found")})
 			return
 		}
 
@@ -174,6 +209,7 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 
 		var req struct {
 			Name         *string  `json:"name"`
+			NameKana     *string  `json:"name_kana"`
 			Description  *string  `json:"description"`
 			Category     *string  `json:"category"`
 			CategoryID   **uint   `json:"category_id"`
@@ -185,6 +221,7 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 			IsOpen       *bool    `json:"is_open"`
 			OpeningTime  *string  `json:"opening_time"`
 			ClosingTime  *string  `json:"closing_time"`
+			TimeZone     *string  `json:"time_zone"`
 			EntryFee     *int     `json:"entry_fee"`
 			Website      *string  `json:"website"`
 			PhoneNumber  *string  `json:"phone_number"`
@@ -193,7 +230,7 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です"})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 
@@ -201,6 +238,9 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 		if req.Name != nil {
 			spot.Name = *req.Name
 		}
+		if req.NameKana != nil {
+			spot.NameKana = *req.NameKana
+		}
 		if req.Description != nil {
 			spot.Description = *req.Description
 		}
@@ -234,6 +274,13 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 		if req.ClosingTime != nil {
 			spot.ClosingTime = *req.ClosingTime
 		}
+		if req.TimeZone != nil {
+			if _, err := loadSpotLocation(*req.TimeZone); err != nil {
+				c.JSON(400, gin.H{"error": errorText(c, err)})
+				return
+			}
+			spot.TimeZone = *req.TimeZone
+		}
 		if req.EntryFee != nil {
 			spot.EntryFee = *req.EntryFee
 		}
@@ -258,8 +305,9 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
-		if err := db.Save(&spot).Error; err != nil {
-			c.JSON(500, gin.H{"error": "観光地更新に失敗しました"})
+		// 評価・レビュー数はレビューの集計で更新するため上書きしない
+		if err := db.Omit("rating", "review_count").Save(&spot).Error; err != nil {
+			c.JSON(500, gin.H{"error": T(c, "spot.update_failed")})
 			return
 		}
 
@@ -273,6 +321,12 @@ func touristSpotUpdateHandler(db *gorm.DB) gin.HandlerFunc {
 
 		// 変更履歴を記録
 		RecordChangeHistory(db, "tourist_spots", id, userID, "update", beforeSpot, spot)
+		refreshSearchIndex(db, SearchTypeSpot, spot.ID)
+
+		// 来場者数・許容人数の変更で満員・再開の閾値をまたぐ場合がある
+		if spot.CurrentCount != beforeSpot.CurrentCount || spot.MaxCapacity != beforeSpot.MaxCapacity {
+			evaluateCapacityState(db, redisClient, &spot, "spot")
+		}
 
 		c.JSON(200, gin.H{"result": "ok", "spot": spot})
 	}
@@ -288,7 +342,7 @@ func touristSpotDeleteHandler(db *gorm.DB) gin.HandlerFunc {
 		db.First(&spot, id)
 
 		if err := db.Delete(&TouristSpot{}, id).Error; err != nil {
-			c.JSON(500, gin.H{"error": "観光地削除に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "spot.delete_failed")})
 			return
 		}
 
@@ -302,83 +356,70 @@ func touristSpotDeleteHandler(db *gorm.DB) gin.HandlerFunc {
 
 		// 変更履歴を記録
 		RecordChangeHistory(db, "tourist_spots", id, userID, "delete", spot, nil)
+		refreshSearchIndex(db, SearchTypeSpot, spot.ID)
+		DeleteTranslations(db, TranslationTypeSpot, spot.ID)
+		db.Where("tourist_spot_id = ?", spot.ID).Delete(&SpotCapacityPolicy{})
 
 		c.JSON(200, gin.H{"result": "ok", "message": "観光地が削除されました"})
 	}
 }
 
 // 観光地の来場者数管理ハンドラ
-func touristSpotVisitorHandler(db *gorm.DB) gin.HandlerFunc {
+func touristSpotVisitorHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		var spot TouristSpot
-		if err := db.First(&spot, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "観光地が見つかりません"})
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(404, gin.H{"error": T(c, "spot.not_found")})
 			return
 		}
 
-		// 変更前の状態を保存
-		beforeSpot := spot
-
-		var req struct {
-			Action       string `json:"action"`        // "increment" or "decrement" (optional)
-			Count        int    `json:"count"`         // for increment/decrement
-			CurrentCount *int   `json:"current_count"` // for direct set
-		}
-
+		var req VisitorUpdate
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "リクエストが無効です"})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 
-		// 直接来場者数を設定する場合
-		if req.CurrentCount != nil {
-			if *req.CurrentCount < 0 {
-				c.JSON(400, gin.H{"error": "来場者数は0以上である必要があります"})
-				return
-			}
-			spot.CurrentCount = *req.CurrentCount
-		} else {
-			// incrementまたはdecrementの場合
-			if req.Count == 0 {
-				req.Count = 1
-			}
-
-			switch req.Action {
-			case "increment":
-				if err := spot.IncrementVisitors(req.Count); err != nil {
-					c.JSON(400, gin.H{"error": err.Error()})
-					return
-				}
-			case "decrement":
-				if err := spot.DecrementVisitors(req.Count); err != nil {
-					c.JSON(400, gin.H{"error": err.Error()})
-					return
-				}
-			default:
-				c.JSON(400, gin.H{"error": "無効なアクションです。'increment' または 'decrement' を指定するか、'current_count' を指定してください"})
-				return
+		spot, err := ApplyVisitorUpdate(db, redisClient, uint(id), req, currentUserIDPtr(c), "visitors")
+		var countErr *VisitorCountError
+		switch {
+		case errors.As(err, &countErr) && countErr.IsFull():
+			// 満員の場合は近くの代替観光地を案内する
+			response := gin.H{"error": T(c, countErr.ID, countErr.Args...)}
+			var full TouristSpot
+			if err := db.First(&full, id).Error; err == nil && FillSpotCapacity(db, &full) == nil {
+				TranslateSpots(db, RequestLocale(c), full.Alternatives)
+				response["capacity"] = full.Capacity
+				response["alternatives"] = full.Alternatives
 			}
-		}
-
-		if err := db.Save(&spot).Error; err != nil {
-			c.JSON(500, gin.H{"error": "来場者数の更新に失敗しました"})
+			c.JSON(409, response)
+			return
+		case errors.As(err, &countErr):
+			c.JSON(400, gin.H{"error": T(c, countErr.ID, countErr.Args...)})
+			return
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(404, gin.H{"error": T(c, "spot.not_found")})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": T(c, "spot.visitors_failed")})
 			return
 		}
 
-		// 変更履歴を記録
-		var userID *uint = nil
-		if uid, exists := GetUserIDFromContext(c); exists {
-			userID = &uid
-		}
-		RecordChangeHistory(db, "tourist_spots", strconv.Itoa(int(spot.ID)), userID, "update", beforeSpot, spot)
-
-		c.JSON(200, gin.H{
+		response := gin.H{
 			"result":        "ok",
 			"current_count": spot.CurrentCount,
 			"max_capacity":  spot.MaxCapacity,
-			"congestion":    spot.GetCongestionLevel(),
-		})
+			"congestion":    NewCongestionService(db).SpotStatus(spot),
+		}
+		// ソフト上限を超えた場合は警告として代替観光地を返す
+		if err := FillSpotCapacity(db, spot); err == nil {
+			TranslateSpots(db, RequestLocale(c), spot.Alternatives)
+			response["capacity"] = spot.Capacity
+			if warningID := capacityWarningID(spot.Capacity); warningID != "" {
+				response["warning"] = T(c, warningID)
+				response["alternatives"] = spot.Alternatives
+			}
+		}
+		c.JSON(200, response)
 	}
 }
 
@@ -388,7 +429,7 @@ func touristSpotCongestionHandler(db *gorm.DB) gin.HandlerFunc {
 		id := c.Param("id")
 		var spot TouristSpot
 		if err := db.First(&spot, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "観光地が見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "spot.not_found")})
 			return
 		}
 
@@ -398,13 +439,15 @@ func touristSpotCongestionHandler(db *gorm.DB) gin.HandlerFunc {
 			records = recs
 		}
 
+		status := NewCongestionService(db).SpotStatus(&spot)
 		c.JSON(200, gin.H{
 			"id":               spot.ID,
 			"name":             spot.Name,
 			"current_count":    spot.CurrentCount,
 			"max_capacity":     spot.MaxCapacity,
-			"congestion_level": spot.GetCongestionLevel(),
-			"congestion_ratio": spot.GetCongestionRatio(),
+			"congestion":       status,
+			"congestion_level": status.Label, // 後方互換
+			"congestion_ratio": status.Ratio, // 後方互換
 			"is_open":          spot.IsOpen,
 			"records":          records,
 		})
@@ -412,29 +455,38 @@ func touristSpotCongestionHandler(db *gorm.DB) gin.HandlerFunc {
 }
 
 // 管理者が混雑レベルを記録するハンドラ
-func touristSpotSetCongestionHandler(db *gorm.DB) gin.HandlerFunc {
+func touristSpotSetCongestionHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		// 観光地が存在するかチェック
 		var spot TouristSpot
 		if err := db.First(&spot, id).Error; err != nil {
-			c.JSON(404, gin.H{"error": "観光地が見つかりません"})
+			c.JSON(404, gin.H{"error": T(c, "spot.not_found")})
 			return
 		}
 
 		var req struct {
-			Level      *int   `json:"level" binding:"required"`
+			Level      *int   `json:"level"`       // 混雑度（0-5）
+			Label      string `json:"label"`       // 表示名（"混雑" など）でも指定可
 			RecordedAt string `json:"recorded_at"` // RFC3339 optional
 			Note       string `json:"note"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "無効なリクエストです", "details": err.Error()})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
 			return
 		}
 
-		if req.Level == nil || *req.Level < 0 || *req.Level > 3 {
-			c.JSON(400, gin.H{"error": "level は0から3の範囲で指定してください"})
+		level := CongestionUnknown
+		if req.Label != "" {
+			if l, ok := CongestionLevelFromLabel(req.Label); ok {
+				level = l
+			}
+		} else if req.Level != nil {
+			level = CongestionLevel(*req.Level)
+		}
+		if !level.Valid() {
+			c.JSON(400, gin.H{"error": T(c, "congestion.level_range", CongestionMaxLevel)})
 			return
 		}
 
@@ -446,12 +498,16 @@ func touristSpotSetCongestionHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// DBに記録
-		rec, err := AddCongestionRecord(db, spot.ID, *req.Level, recordedAt, req.Note)
+		rec, err := AddCongestionRecord(db, spot.ID, level, recordedAt, req.Note)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "混雑記録の保存に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "congestion.record_failed")})
 			return
 		}
 
+		// 購読中のクライアントへ配信
+		recordedLevel := int(level)
+		notifyCongestionChange(db, redisClient, &spot, "congestion", &recordedLevel)
+
 		c.JSON(201, gin.H{"result": "ok", "record": rec})
 	}
 }
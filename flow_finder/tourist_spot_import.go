@@ -0,0 +1,619 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 観光地の一括取り込み・書き出し（CSV / XLSX）
+// 列は観光地作成APIのリクエストと同じ名前で、カテゴリ・最寄りノードはIDの代わりに名前で指定する。
+// 行は external_key で既存の観光地と照合し、あれば更新、なければ作成する
+const (
+	spotImportMaxRows   = 5000
+	spotImportMaxBytes  = 10 << 20
+	spotImportSheetName = "tourist_spots"
+)
+
+const (
+	SpotImportCreate    = "create"
+	SpotImportUpdate    = "update"
+	SpotImportUnchanged = "unchanged"
+	SpotImportError     = "error"
+)
+
+// 取り込み・書き出しの列（書き出しはこの順に出力する）
+var spotImportColumns = []string{
+	"external_key", "id", "name", "name_kana", "description", "category", "category_name", "nearest_node_name",
+	"x", "y", "max_capacity", "current_count", "is_open", "opening_time", "closing_time", "time_zone",
+	"entry_fee", "website", "phone_number", "image_url", "reward_url",
+}
+
+// 更新時に書き込むDBの列（来場者数・待ち時間・評価など、運用中に変わる値は取り込みで上書きしない）
+var spotImportUpdateFields = []string{
+	"external_key", "name", "name_kana", "description", "category", "category_id", "node_id", "distance_to_node",
+	"x", "y", "max_capacity", "is_open", "opening_time", "closing_time", "time_zone",
+	"entry_fee", "website", "phone_number", "image_url", "reward_url", "updated_at",
+}
+
+// 行の問題（Column が空の場合は行全体）
+type SpotImportIssue struct {
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+	err     error
+}
+
+// 行ごとの取り込み結果
+type SpotImportRow struct {
+	Row         int               `json:"row"` // ファイル上の行番号（ヘッダーが1行目）
+	ExternalKey string            `json:"external_key"`
+	Action      string            `json:"action"` // create / update / unchanged / error
+	SpotID      uint              `json:"tourist_spot_id,omitempty"`
+	Changes     []string          `json:"changes,omitempty"` // 変更される列
+	Errors      []SpotImportIssue `json:"errors,omitempty"`
+
+	spot   TouristSpot
+	before *TouristSpot
+}
+
+// 取り込み結果
+type SpotImportResult struct {
+	DryRun    bool            `json:"dry_run"`
+	Applied   bool            `json:"applied"`
+	Created   int             `json:"created"`
+	Updated   int             `json:"updated"`
+	Unchanged int             `json:"unchanged"`
+	Failed    int             `json:"failed"`
+	Rows      []SpotImportRow `json:"rows"`
+}
+
+func (r *SpotImportRow) addIssue(column string, err error) {
+	r.Errors = append(r.Errors, SpotImportIssue{Column: column, err: err})
+}
+
+func (r *SpotImportRow) hasIssue(column string) bool {
+	for _, issue := range r.Errors {
+		if issue.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// 取り込み時に名前・IDから引くための参照データ
+type spotImportLookup struct {
+	categoryIDs   map[string]uint
+	categoryNames map[uint]string
+	nodesByName   map[string][]Node
+	nodeNames     map[uint]string
+	nodes         []Node
+}
+
+func loadSpotImportLookup(db *gorm.DB) (*spotImportLookup, error) {
+	var categories []TouristSpotCategory
+	if err := db.Select("id", "name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	var nodes []Node
+	if err := db.Select("id", "name", "x", "y").Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	lookup := &spotImportLookup{
+		categoryIDs:   make(map[string]uint),
+		categoryNames: make(map[uint]string),
+		nodesByName:   make(map[string][]Node),
+		nodeNames:     make(map[uint]string),
+		nodes:         nodes,
+	}
+	for _, category := range categories {
+		lookup.categoryIDs[category.Name] = category.ID
+		lookup.categoryNames[category.ID] = category.Name
+	}
+	for _, node := range nodes {
+		if node.Name != "" {
+			lookup.nodesByName[node.Name] = append(lookup.nodesByName[node.Name], node)
+		}
+		lookup.nodeNames[node.ID] = node.Name
+	}
+	return lookup, nil
+}
+
+// 座標から最寄りノードと距離を求める（ノードがない場合は nil）
+func (l *spotImportLookup) nearestNode(x, y float64) (*Node, float64) {
+	var nearest *Node
+	var distance float64
+	for i := range l.nodes {
+		if d := calculateDistance(x, y, l.nodes[i].X, l.nodes[i].Y); nearest == nil || d < distance {
+			nearest, distance = &l.nodes[i], d
+		}
+	}
+	return nearest, distance
+}
+
+// 観光地を書き出し用の1行にする（列は spotImportColumns の順）
+func (l *spotImportLookup) record(spot *TouristSpot) []string {
+	externalKey := ""
+	if spot.ExternalKey != nil {
+		externalKey = *spot.ExternalKey
+	}
+	categoryName := ""
+	if spot.CategoryID != nil {
+		categoryName = l.categoryNames[*spot.CategoryID]
+	}
+	nodeName := ""
+	if spot.NodeID != nil {
+		nodeName = l.nodeNames[*spot.NodeID]
+	}
+	id := ""
+	if spot.ID != 0 {
+		id = strconv.Itoa(int(spot.ID))
+	}
+	return []string{
+		externalKey, id, spot.Name, spot.NameKana, spot.Description, spot.Category, categoryName, nodeName,
+		strconv.FormatFloat(spot.X, 'f', -1, 64), strconv.FormatFloat(spot.Y, 'f', -1, 64),
+		strconv.Itoa(spot.MaxCapacity), strconv.Itoa(spot.CurrentCount), strconv.FormatBool(spot.IsOpen),
+		spot.OpeningTime, spot.ClosingTime, spot.TimeZone, strconv.Itoa(spot.EntryFee),
+		spot.Website, spot.PhoneNumber, spot.ImageURL, spot.RewardURL,
+	}
+}
+
+// CSV / XLSX を行の配列として読み込む
+func readSpotTable(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case "csv":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))) // Excelが付けるBOMを除く
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, newLocalizedError("spot_import.parse_failed")
+		}
+		return records, nil
+	case "xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, newLocalizedError("spot_import.parse_failed")
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, newLocalizedError("spot_import.empty")
+		}
+		rows, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, newLocalizedError("spot_import.parse_failed")
+		}
+		return rows, nil
+	}
+	return nil, newLocalizedError("spot_import.format_invalid")
+}
+
+// ヘッダーを列番号の表にする
+func parseSpotImportHeader(header []string) (map[string]int, error) {
+	known := make(map[string]bool, len(spotImportColumns))
+	for _, column := range spotImportColumns {
+		known[column] = true
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, newLocalizedError("spot_import.unknown_column", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, newLocalizedError("spot_import.duplicate_column", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["external_key"]; !ok {
+		return nil, newLocalizedError("spot_import.key_column_required")
+	}
+	return columns, nil
+}
+
+// 1行分のセル（列がない・空欄のセルは ok=false）
+type spotImportCells struct {
+	columns map[string]int
+	record  []string
+}
+
+func (c spotImportCells) get(column string) (string, bool) {
+	i, ok := c.columns[column]
+	if !ok || i >= len(c.record) {
+		return "", false
+	}
+	value := strings.TrimSpace(c.record[i])
+	return value, value != ""
+}
+
+func (c spotImportCells) blank() bool {
+	for _, value := range c.record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// 取り込む行を検証し、行ごとの結果を作る（DBは変更しない）
+func PlanSpotImport(db *gorm.DB, records [][]string) (*SpotImportResult, error) {
+	if len(records) < 2 {
+		return nil, newLocalizedError("spot_import.empty")
+	}
+	if len(records)-1 > spotImportMaxRows {
+		return nil, newLocalizedError("spot_import.too_many_rows", spotImportMaxRows)
+	}
+	columns, err := parseSpotImportHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := loadSpotImportLookup(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// 照合に使う既存の観光地をまとめて読み込む
+	var keys []string
+	var ids []uint
+	for _, record := range records[1:] {
+		cells := spotImportCells{columns, record}
+		if key, ok := cells.get("external_key"); ok {
+			keys = append(keys, key)
+		}
+		if id, ok := cells.get("id"); ok {
+			if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+				ids = append(ids, uint(n))
+			}
+		}
+	}
+	byKey := make(map[string]TouristSpot)
+	byID := make(map[uint]TouristSpot)
+	var existing []TouristSpot
+	if len(keys) > 0 || len(ids) > 0 {
+		if err := db.Where("external_key IN ? OR id IN ?", append(keys, ""), append(ids, 0)).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, spot := range existing {
+		if spot.ExternalKey != nil {
+			byKey[*spot.ExternalKey] = spot
+		}
+		byID[spot.ID] = spot
+	}
+
+	result := &SpotImportResult{Rows: []SpotImportRow{}}
+	seen := make(map[string]int)  // external_key → 最初の行
+	matched := make(map[uint]int) // 観光地ID → 照合した行
+	for i, record := range records[1:] {
+		cells := spotImportCells{columns, record}
+		if cells.blank() {
+			continue
+		}
+		row := SpotImportRow{Row: i + 2}
+		row.ExternalKey, _ = cells.get("external_key")
+		if row.ExternalKey == "" {
+			row.addIssue("external_key", newLocalizedError("spot_import.key_required"))
+		} else if first, ok := seen[row.ExternalKey]; ok {
+			row.addIssue("external_key", newLocalizedError("spot_import.key_duplicate", first))
+		} else {
+			seen[row.ExternalKey] = row.Row
+		}
+
+		// 照合（external_key → 未設定の場合は id で既存の観光地に external_key を付ける）
+		if spot, ok := byKey[row.ExternalKey]; ok && row.ExternalKey != "" {
+			before := spot
+			row.before = &before
+		} else if idText, ok := cells.get("id"); ok {
+			id, err := strconv.ParseUint(idText, 10, 64)
+			spot, found := byID[uint(id)]
+			switch {
+			case err != nil || !found:
+				row.addIssue("id", newLocalizedError("spot_import.id_not_found"))
+			case spot.ExternalKey != nil && *spot.ExternalKey != row.ExternalKey:
+				row.addIssue("id", newLocalizedError("spot_import.id_key_conflict"))
+			default:
+				before := spot
+				row.before = &before
+			}
+		}
+		if row.before != nil {
+			if first, ok := matched[row.before.ID]; ok {
+				row.addIssue("id", newLocalizedError("spot_import.spot_duplicate", first))
+			}
+			matched[row.before.ID] = row.Row
+			row.spot = *row.before
+			row.SpotID = row.spot.ID
+		} else {
+			row.spot = TouristSpot{IsOpen: true}
+		}
+		if row.ExternalKey != "" {
+			key := row.ExternalKey
+			row.spot.ExternalKey = &key
+		}
+
+		applySpotImportCells(&row, cells, lookup)
+		finishSpotImportRow(&row, lookup)
+		switch row.Action {
+		case SpotImportCreate:
+			result.Created++
+		case SpotImportUpdate:
+			result.Updated++
+		case SpotImportUnchanged:
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if len(result.Rows) == 0 {
+		return nil, newLocalizedError("spot_import.empty")
+	}
+	return result, nil
+}
+
+// セルの値を観光地に反映する（空欄のセルは変更しない）
+func applySpotImportCells(row *SpotImportRow, cells spotImportCells, lookup *spotImportLookup) {
+	spot := &row.spot
+	texts := []struct {
+		column string
+		target *string
+	}{
+		{"name", &spot.Name}, {"name_kana", &spot.NameKana}, {"description", &spot.Description}, {"category", &spot.Category},
+		{"website", &spot.Website}, {"phone_number", &spot.PhoneNumber}, {"image_url", &spot.ImageURL}, {"reward_url", &spot.RewardURL},
+	}
+	for _, text := range texts {
+		if value, ok := cells.get(text.column); ok {
+			*text.target = value
+		}
+	}
+
+	for _, column := range []string{"x", "y"} {
+		if value, ok := cells.get(column); ok {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				row.addIssue(column, newLocalizedError("spot_import.number_invalid"))
+			} else if column == "x" {
+				spot.X = f
+			} else {
+				spot.Y = f
+			}
+		}
+	}
+	ints := []struct {
+		column string
+		target *int
+	}{
+		{"max_capacity", &spot.MaxCapacity}, {"entry_fee", &spot.EntryFee},
+	}
+	if row.before == nil {
+		// 来場者数は作成時のみ（更新時は現在の値を保つ）
+		ints = append(ints, struct {
+			column string
+			target *int
+		}{"current_count", &spot.CurrentCount})
+	}
+	for _, field := range ints {
+		if value, ok := cells.get(field.column); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				row.addIssue(field.column, newLocalizedError("spot_import.number_invalid"))
+			} else if n < 0 {
+				row.addIssue(field.column, newLocalizedError("spot_import.negative"))
+			} else {
+				*field.target = n
+			}
+		}
+	}
+	if value, ok := cells.get("is_open"); ok {
+		switch strings.ToLower(value) {
+		case "true", "1", "yes":
+			spot.IsOpen = true
+		case "false", "0", "no":
+			spot.IsOpen = false
+		default:
+			row.addIssue("is_open", newLocalizedError("spot_import.bool_invalid"))
+		}
+	}
+	if value, ok := cells.get("opening_time"); ok {
+		if _, err := parseClockMinutes(value); err != nil {
+			row.addIssue("opening_time", err)
+		} else {
+			spot.OpeningTime = value
+		}
+	}
+	if value, ok := cells.get("closing_time"); ok {
+		if _, err := parseClockMinutes(value); err != nil {
+			row.addIssue("closing_time", err)
+		} else {
+			spot.ClosingTime = value
+		}
+	}
+	if value, ok := cells.get("time_zone"); ok {
+		if _, err := loadSpotLocation(value); err != nil {
+			row.addIssue("time_zone", err)
+		} else {
+			spot.TimeZone = value
+		}
+	}
+	if value, ok := cells.get("category_name"); ok {
+		if id, found := lookup.categoryIDs[value]; found {
+			spot.CategoryID = &id
+		} else {
+			row.addIssue("category_name", newLocalizedError("spot_import.category_not_found", value))
+		}
+	}
+
+	// 最寄りノード（指定がなく座標が変わった場合は座標から求める）
+	if value, ok := cells.get("nearest_node_name"); ok {
+		switch nodes := lookup.nodesByName[value]; len(nodes) {
+		case 0:
+			row.addIssue("nearest_node_name", newLocalizedError("spot_import.node_not_found", value))
+		case 1:
+			id := nodes[0].ID
+			spot.NodeID = &id
+			spot.DistanceToNode = calculateDistance(spot.X, spot.Y, nodes[0].X, nodes[0].Y)
+		default:
+			row.addIssue("nearest_node_name", newLocalizedError("spot_import.node_ambiguous", value))
+		}
+	} else if row.before == nil || spot.X != row.before.X || spot.Y != row.before.Y {
+		if spot.X != 0 && spot.Y != 0 {
+			if node, distance := lookup.nearestNode(spot.X, spot.Y); node != nil {
+				id := node.ID
+				spot.NodeID = &id
+				spot.DistanceToNode = distance
+			}
+		}
+	}
+}
+
+// 必須項目を確認し、作成・更新・変更なしを決める
+func finishSpotImportRow(row *SpotImportRow, lookup *spotImportLookup) {
+	if row.spot.Name == "" {
+		row.addIssue("name", newLocalizedError("spot_import.name_required"))
+	}
+	if row.spot.MaxCapacity < 1 && !row.hasIssue("max_capacity") {
+		row.addIssue("max_capacity", newLocalizedError("spot_import.capacity_invalid"))
+	}
+	if len(row.Errors) > 0 {
+		row.Action = SpotImportError
+		return
+	}
+	if row.before == nil {
+		row.Action = SpotImportCreate
+		return
+	}
+	before, after := lookup.record(row.before), lookup.record(&row.spot)
+	for i, column := range spotImportColumns {
+		if before[i] != after[i] {
+			row.Changes = append(row.Changes, column)
+		}
+	}
+	if len(row.Changes) > 0 {
+		row.Action = SpotImportUpdate
+	} else {
+		row.Action = SpotImportUnchanged
+	}
+}
+
+// 取り込みを反映する（1行でもエラーがあれば何も変更しない）
+// 行ごとに変更履歴を記録し、作成した観光地のIDを結果に設定する
+func ApplySpotImport(db *gorm.DB, result *SpotImportResult, userID *uint) error {
+	if result.Failed > 0 {
+		return errSpotImportHasErrors
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range result.Rows {
+			row := &result.Rows[i]
+			switch row.Action {
+			case SpotImportCreate:
+				if err := tx.Create(&row.spot).Error; err != nil {
+					return err
+				}
+				// is_open は既定値が true のため、false は作成後に設定する
+				if !row.spot.IsOpen {
+					if err := tx.Model(&row.spot).Update("is_open", false).Error; err != nil {
+						return err
+					}
+				}
+				row.SpotID = row.spot.ID
+				if err := RecordChangeHistoryTx(tx, "tourist_spots", strconv.Itoa(int(row.spot.ID)), userID, "import_create", nil, row.spot); err != nil {
+					return err
+				}
+			case SpotImportUpdate:
+				// 取り込みの列だけを更新し、検証後に変わった来場者数などは更新後の値を読み直す
+				// （last_updated は来場者数の更新日時のため、自動更新の対象から外す）
+				if err := tx.Model(&row.spot).Clauses(clause.Returning{}).Select(spotImportUpdateFields).Omit("last_updated").Updates(&row.spot).Error; err != nil {
+					return err
+				}
+				if err := RecordChangeHistoryTx(tx, "tourist_spots", strconv.Itoa(int(row.spot.ID)), userID, "import_update", *row.before, row.spot); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	result.Applied = true
+	for _, row := range result.Rows {
+		if row.Action == SpotImportCreate || row.Action == SpotImportUpdate {
+			refreshSearchIndex(db, SearchTypeSpot, row.SpotID)
+		}
+		if row.Action == SpotImportUpdate {
+			// 許容人数の変更で満員・再開の閾値をまたぐ場合がある
+			evaluateCapacityState(db, nil, &row.spot, "import")
+		}
+	}
+	return nil
+}
+
+var errSpotImportHasErrors = errors.New("spot import has invalid rows")
+
+// 全観光地を書き出し用の行にする（1行目はヘッダー）
+func ExportSpotRecords(db *gorm.DB) ([][]string, error) {
+	lookup, err := loadSpotImportLookup(db)
+	if err != nil {
+		return nil, err
+	}
+	var spots []TouristSpot
+	if err := db.Order("id").Find(&spots).Error; err != nil {
+		return nil, err
+	}
+	records := make([][]string, 0, len(spots)+1)
+	records = append(records, spotImportColumns)
+	for i := range spots {
+		records = append(records, lookup.record(&spots[i]))
+	}
+	return records, nil
+}
+
+// 行を CSV / XLSX で書き出す
+func writeSpotTable(w io.Writer, format string, records [][]string) error {
+	switch format {
+	case "csv":
+		// Excelで文字化けしないようBOMを付ける
+		if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+			return err
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(records); err != nil {
+			return err
+		}
+		return writer.Error()
+	case "xlsx":
+		f := excelize.NewFile()
+		defer f.Close()
+		if err := f.SetSheetName("Sheet1", spotImportSheetName); err != nil {
+			return err
+		}
+		for i, record := range records {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			values := make([]interface{}, len(record))
+			for j, value := range record {
+				values[j] = value
+			}
+			if err := f.SetSheetRow(spotImportSheetName, cell, &values); err != nil {
+				return err
+			}
+		}
+		return f.Write(w)
+	}
+	return newLocalizedError("spot_import.format_invalid")
+}
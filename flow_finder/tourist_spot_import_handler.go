@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 観光地の一括取り込み・書き出しのルートを登録
+func RegisterTouristSpotImportRoutes(r *gin.Engine, db *gorm.DB) {
+	// CSV / XLSX の取り込み（?dry_run=true で検証結果のみ返す）
+	r.POST("/api/admin/tourist-spots/import", spotImportHandler(db))
+
+	// 取り込みと同じ形式で全観光地を書き出す（?format=csv|xlsx）
+	r.GET("/api/admin/tourist-spots/export", spotExportHandler(db))
+}
+
+// ファイル形式（?format= がなければ拡張子から判定）
+func spotTableFormat(format, filename string) string {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+	return strings.ToLower(format)
+}
+
+// 取り込みハンドラ
+func spotImportHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.file_missing")})
+			return
+		}
+		if file.Size > spotImportMaxBytes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.too_large")})
+			return
+		}
+		format := spotTableFormat(c.Query("format"), file.Filename)
+		if format != "csv" && format != "xlsx" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot_import.format_invalid")})
+			return
+		}
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.read_failed")})
+			return
+		}
+		defer src.Close()
+
+		records, err := readSpotTable(src, format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		result, err := PlanSpotImport(db, records)
+		if err != nil {
+			var localized *LocalizedError
+			if errors.As(err, &localized) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+				return
+			}
+			fmt.Printf("⚠️ 観光地の取り込みの検証に失敗: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "spot_import.failed")})
+			return
+		}
+		result.DryRun = c.Query("dry_run") == "true"
+		localizeSpotImportIssues(c, result)
+
+		if result.DryRun {
+			c.JSON(http.StatusOK, result)
+			return
+		}
+		if result.Failed > 0 {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": T(c, "spot_import.has_errors"), "result": result})
+			return
+		}
+		if err := ApplySpotImport(db, result, currentUserIDPtr(c)); err != nil {
+			fmt.Printf("⚠️ 観光地の取り込みに失敗: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "spot_import.failed")})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// 行の問題をリクエストのロケールの文言にする
+func localizeSpotImportIssues(c *gin.Context, result *SpotImportResult) {
+	for i := range result.Rows {
+		for j := range result.Rows[i].Errors {
+			issue := &result.Rows[i].Errors[j]
+			issue.Message = errorText(c, issue.err)
+		}
+	}
+}
+
+// 書き出しハンドラ
+func spotExportHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := spotTableFormat(c.DefaultQuery("format", "csv"), "")
+		if format != "csv" && format != "xlsx" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot_import.format_invalid")})
+			return
+		}
+		records, err := ExportSpotRecords(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "spot_import.export_failed")})
+			return
+		}
+
+		contentType := "text/csv; charset=utf-8"
+		if format == "xlsx" {
+			contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		}
+		filename := fmt.Sprintf("tourist_spots_%s.%s", time.Now().Format("20060102"), format)
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+		if err := writeSpotTable(c.Writer, format, records); err != nil {
+			fmt.Printf("⚠️ 観光地の書き出しに失敗: %v\n", err)
+		}
+	}
+}
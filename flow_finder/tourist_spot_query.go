@@ -0,0 +1,499 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 観光地一覧の絞り込み・並べ替え・ページング
+// カテゴリ・グループ・料金・評価はSQLで絞り込み、営業状況・混雑度・距離のように算出が必要な条件は取得後に判定する。
+// limit または cursor を指定した場合のみページングし、次のページのカーソルを X-Next-Cursor ヘッダーで返す。
+// 保存されている項目（id・名前・評価・入場料）の並べ替えはSQLのキーセット方式でページングするが、
+// 混雑度・距離は算出が必要なため、全件を取得してから並べ替える（観光地の件数に比例して遅くなる）
+const (
+	spotListMaxLimit = 200
+	spotCursorHeader = "X-Next-Cursor"
+)
+
+// 並べ替えに使える項目（先頭に "-" を付けると降順）
+var spotSortKeys = map[string]bool{
+	"id": true, "name": true, "rating": true, "congestion": true, "distance": true, "entry_fee": true,
+}
+
+// SQLで並べ替え・ページングできる項目の式（値がない場合は取得後の並べ替えと同じく0として扱う）
+var spotSQLSortColumns = map[string]string{
+	"id": "id", "name": "name", "rating": "COALESCE(rating, 0)", "entry_fee": "COALESCE(entry_fee, 0)",
+}
+
+// 観光地一覧の検索条件
+type TouristSpotListQuery struct {
+	Category      string // 旧カテゴリ名
+	CategoryIDs   []uint
+	GroupID       *uint
+	OpenOnly      bool
+	CongestionMin *CongestionLevel
+	CongestionMax *CongestionLevel
+	FeeMin        *int
+	FeeMax        *int
+	RatingMin     *float64
+	NearNodeID    *uint // 距離の基準にするノード
+	Sort          string
+	Desc          bool
+	Fields        []string // 返す項目（空の場合は全項目）
+	Limit         int      // 0 の場合はページングしない
+	Cursor        *spotCursor
+}
+
+// ページングのカーソル（直前のページの最後の観光地の並べ替えキー）
+type spotCursor struct {
+	Sort string   `json:"s"`
+	Num  *float64 `json:"n,omitempty"` // 数値のキー（値がない場合は nil）
+	Str  string   `json:"t,omitempty"` // 名前順のキー
+	ID   uint     `json:"id"`
+}
+
+func (c spotCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSpotCursor(s string) (*spotCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c spotCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// カンマ区切りのIDを読み取る
+func parseUintList(s string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(s, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// 観光地のJSONの項目名
+func touristSpotJSONFields() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(TouristSpot{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// クエリ文字列から検索条件を読み取る
+func ParseTouristSpotListQuery(values url.Values) (*TouristSpotListQuery, error) {
+	q := &TouristSpotListQuery{Category: values.Get("category"), OpenOnly: values.Get("open") == "true", Sort: "id"}
+
+	if v := values.Get("category_id"); v != "" {
+		ids, err := parseUintList(v)
+		if err != nil {
+			return nil, newLocalizedError("spot_query.category_id_invalid")
+		}
+		q.CategoryIDs = ids
+	}
+	uintParams := map[string]**uint{"group_id": &q.GroupID, "near_node": &q.NearNodeID}
+	for name, dst := range uintParams {
+		if v := values.Get(name); v != "" {
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, newLocalizedError("spot_query.number_required", name)
+			}
+			u := uint(id)
+			*dst = &u
+		}
+	}
+	levelParams := map[string]**CongestionLevel{"congestion_min": &q.CongestionMin, "congestion_max": &q.CongestionMax}
+	for name, dst := range levelParams {
+		if v := values.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			level := CongestionLevel(n)
+			if err != nil || !level.Valid() {
+				return nil, newLocalizedError("spot_query.congestion_range", name, CongestionMaxLevel)
+			}
+			*dst = &level
+		}
+	}
+	intParams := map[string]**int{"fee_min": &q.FeeMin, "fee_max": &q.FeeMax}
+	for name, dst := range intParams {
+		if v := values.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, newLocalizedError("spot_query.non_negative", name)
+			}
+			*dst = &n
+		}
+	}
+	if v := values.Get("rating_min"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r < 0 || r > 5 {
+			return nil, newLocalizedError("spot_query.rating_range")
+		}
+		q.RatingMin = &r
+	}
+
+	if v := values.Get("sort"); v != "" {
+		q.Desc = strings.HasPrefix(v, "-")
+		q.Sort = strings.TrimPrefix(v, "-")
+		if !spotSortKeys[q.Sort] {
+			return nil, newLocalizedError("spot_query.sort_invalid")
+		}
+	}
+	if q.Sort == "distance" && q.NearNodeID == nil {
+		return nil, newLocalizedError("spot_query.near_node_required")
+	}
+
+	if v := values.Get("fields"); v != "" {
+		known := touristSpotJSONFields()
+		for _, f := range strings.Split(v, ",") {
+			f = strings.TrimSpace(f)
+			if !known[f] {
+				return nil, newLocalizedError("spot_query.unknown_field", f)
+			}
+			q.Fields = append(q.Fields, f)
+		}
+	}
+
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > spotListMaxLimit {
+			return nil, newLocalizedError("spot_query.limit_range", spotListMaxLimit)
+		}
+		q.Limit = n
+	}
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := decodeSpotCursor(v)
+		if err != nil || cursor.Sort != q.sortParam() {
+			return nil, newLocalizedError("spot_query.cursor_invalid")
+		}
+		q.Cursor = cursor
+		if q.Limit == 0 {
+			q.Limit = 50
+		}
+	}
+	return q, nil
+}
+
+// SQLで判定できる条件を適用
+func (q *TouristSpotListQuery) Apply(query *gorm.DB) *gorm.DB {
+	if q.Category != "" {
+		query = query.Where("category = ?", q.Category)
+	}
+	if len(q.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", q.CategoryIDs)
+	}
+	if q.GroupID != nil {
+		query = query.Where("category_id IN (SELECT id FROM tourist_spot_categories WHERE group_id = ?)", *q.GroupID)
+	}
+	if q.OpenOnly {
+		query = query.Where("is_open = ?", true)
+	}
+	if q.FeeMin != nil {
+		query = query.Where("entry_fee >= ?", *q.FeeMin)
+	}
+	if q.FeeMax != nil {
+		query = query.Where("entry_fee <= ?", *q.FeeMax)
+	}
+	if q.RatingMin != nil {
+		query = query.Where("rating >= ?", *q.RatingMin)
+	}
+	return query
+}
+
+// 観光地の並べ替えキー
+func (q *TouristSpotListQuery) key(spot *TouristSpot) spotCursor {
+	c := spotCursor{Sort: q.sortParam(), ID: spot.ID}
+	num := func(v float64) { c.Num = &v }
+	switch q.Sort {
+	case "id":
+		num(float64(spot.ID))
+	case "name":
+		c.Str = spot.Name
+	case "rating":
+		num(float64(spot.Rating))
+	case "entry_fee":
+		num(float64(spot.EntryFee))
+	case "congestion":
+		if spot.Congestion != nil && spot.Congestion.Ratio != nil {
+			num(*spot.Congestion.Ratio)
+		}
+	case "distance":
+		if spot.Distance != nil {
+			num(*spot.Distance)
+		}
+	}
+	return c
+}
+
+func (q *TouristSpotListQuery) sortParam() string {
+	if q.Desc {
+		return "-" + q.Sort
+	}
+	if q.Sort == "id" {
+		return ""
+	}
+	return q.Sort
+}
+
+// a が b より前に並ぶかどうか（値がないものは昇順・降順とも末尾、同じ値はID順）
+func (q *TouristSpotListQuery) before(a, b spotCursor) bool {
+	if q.Sort == "name" {
+		if a.Str != b.Str {
+			return (a.Str < b.Str) != q.Desc
+		}
+		return a.ID < b.ID
+	}
+	switch {
+	case a.Num == nil && b.Num == nil:
+		return a.ID < b.ID
+	case a.Num == nil:
+		return false
+	case b.Num == nil:
+		return true
+	case *a.Num != *b.Num:
+		return (*a.Num < *b.Num) != q.Desc
+	}
+	return a.ID < b.ID
+}
+
+// ページングをSQL（キーセット方式）で行えるかどうか
+// 名前順は翻訳後の名前で並べるため、既定の言語の場合のみ
+func (q *TouristSpotListQuery) pagesInSQL(locale string) bool {
+	if q.Limit == 0 {
+		return false
+	}
+	if _, ok := spotSQLSortColumns[q.Sort]; !ok {
+		return false
+	}
+	return q.Sort != "name" || locale == defaultLocale
+}
+
+// カーソルより後の観光地に絞り込み、並べ替える（同じ値はID順）
+func (q *TouristSpotListQuery) sqlPage(query *gorm.DB, cursor *spotCursor) *gorm.DB {
+	column := spotSQLSortColumns[q.Sort]
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+	if q.Sort == "id" {
+		if cursor != nil {
+			query = query.Where("id "+op+" ?", cursor.ID)
+		}
+		return query.Order("id " + dir)
+	}
+	if cursor != nil {
+		var value interface{} = cursor.Str
+		if q.Sort != "name" {
+			var n float64
+			if cursor.Num != nil {
+				n = *cursor.Num
+			}
+			value = n
+		}
+		query = query.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id > ?))", column, op, column), value, value, cursor.ID)
+	}
+	return query.Order(column + " " + dir).Order("id ASC")
+}
+
+// 一覧を取得する（混雑度・翻訳を設定し、絞り込み・並べ替え・ページングまで行う）
+// 次のページがない場合のカーソルは空
+func (q *TouristSpotListQuery) Find(db *gorm.DB, locale string, now time.Time) ([]TouristSpot, string, error) {
+	query := preloadSpotSchedule(q.Apply(db.Model(&TouristSpot{}))).
+		Preload("TouristCategory"). // カテゴリ情報をプリロード
+		Session(&gorm.Session{})
+	fill := func(spots []TouristSpot) {
+		NewCongestionService(db).FillSpots(spots)
+		TranslateSpots(db, locale, spots)
+	}
+
+	if !q.pagesInSQL(locale) {
+		var spots []TouristSpot
+		if err := query.Find(&spots).Error; err != nil {
+			return nil, "", err
+		}
+		fill(spots)
+		return q.Select(db, spots, now)
+	}
+
+	origin, err := q.origin(db)
+	if err != nil {
+		return nil, "", err
+	}
+	// 1件多く取得して次のページの有無を判定する。取得後の絞り込みで足りない場合は続きを取得する
+	var result []TouristSpot
+	cursor := q.Cursor
+	for {
+		var spots []TouristSpot
+		if err := q.sqlPage(query, cursor).Limit(q.Limit + 1).Find(&spots).Error; err != nil {
+			return nil, "", err
+		}
+		fill(spots)
+		result = append(result, q.filter(spots, origin, now)...)
+		if len(result) > q.Limit || len(spots) <= q.Limit {
+			break
+		}
+		last := q.key(&spots[len(spots)-1])
+		cursor = &last
+	}
+	if len(result) <= q.Limit {
+		return result, "", nil
+	}
+	result = result[:q.Limit]
+	return result, q.key(&result[q.Limit-1]).Encode(), nil
+}
+
+// 距離の基準にするノード（near_node を指定しない場合は nil）
+func (q *TouristSpotListQuery) origin(db *gorm.DB) (*Node, error) {
+	if q.NearNodeID == nil {
+		return nil, nil
+	}
+	var node Node
+	if err := db.First(&node, *q.NearNodeID).Error; err != nil {
+		return nil, newLocalizedError("spot_query.near_node_not_found")
+	}
+	return &node, nil
+}
+
+// 算出が必要な条件で絞り込む（営業状況と距離も設定する）
+func (q *TouristSpotListQuery) filter(spots []TouristSpot, origin *Node, now time.Time) []TouristSpot {
+	filtered := make([]TouristSpot, 0, len(spots))
+	for _, spot := range spots {
+		status := spot.OpenStatusAt(now)
+		spot.OpenStatus = &status
+		if q.OpenOnly && !status.IsOpen {
+			continue
+		}
+		if q.CongestionMin != nil || q.CongestionMax != nil {
+			if spot.Congestion == nil || spot.Congestion.Level == CongestionUnknown {
+				continue
+			}
+			if q.CongestionMin != nil && spot.Congestion.Level < *q.CongestionMin {
+				continue
+			}
+			if q.CongestionMax != nil && spot.Congestion.Level > *q.CongestionMax {
+				continue
+			}
+		}
+		if origin != nil {
+			d := calculateDistance(origin.X, origin.Y, spot.X, spot.Y)
+			spot.Distance = &d
+		}
+		filtered = append(filtered, spot)
+	}
+	return filtered
+}
+
+// 取得済みの観光地を絞り込み・並べ替え・ページングする（次のページがない場合のカーソルは空）
+func (q *TouristSpotListQuery) Select(db *gorm.DB, spots []TouristSpot, now time.Time) ([]TouristSpot, string, error) {
+	origin, err := q.origin(db)
+	if err != nil {
+		return nil, "", err
+	}
+	filtered := q.filter(spots, origin, now)
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return q.before(q.key(&filtered[i]), q.key(&filtered[j]))
+	})
+	if q.Limit == 0 {
+		return filtered, "", nil
+	}
+
+	start := 0
+	if q.Cursor != nil {
+		start = sort.Search(len(filtered), func(i int) bool {
+			return q.before(*q.Cursor, q.key(&filtered[i]))
+		})
+	}
+	end := start + q.Limit
+	if end >= len(filtered) {
+		return filtered[start:], "", nil
+	}
+	return filtered[start:end], q.key(&filtered[end-1]).Encode(), nil
+}
+
+// 指定した項目だけを残す
+func (q *TouristSpotListQuery) Project(spots []TouristSpot) ([]map[string]interface{}, error) {
+	result := make([]map[string]interface{}, 0, len(spots))
+	for _, spot := range spots {
+		b, err := json.Marshal(spot)
+		if err != nil {
+			return nil, err
+		}
+		var full map[string]json.RawMessage
+		if err := json.Unmarshal(b, &full); err != nil {
+			return nil, err
+		}
+		item := make(map[string]interface{}, len(q.Fields))
+		for _, f := range q.Fields {
+			if v, ok := full[f]; ok {
+				item[f] = v
+			} else {
+				item[f] = nil
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// 最寄りノードを設定する（all が false の場合は未設定の観光地のみ）
+func BackfillNearestNodes(db *gorm.DB, all bool) (int, error) {
+	var nodes []Node
+	if err := db.Find(&nodes).Error; err != nil {
+		return 0, err
+	}
+	if len(nodes) == 0 {
+		return 0, nil
+	}
+	query := db.Model(&TouristSpot{})
+	if !all {
+		query = query.Where("node_id IS NULL")
+	}
+	var spots []TouristSpot
+	if err := query.Select("id", "x", "y", "node_id", "distance_to_node").Find(&spots).Error; err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, spot := range spots {
+		nearest, distance := nodes[0], calculateDistance(spot.X, spot.Y, nodes[0].X, nodes[0].Y)
+		for _, node := range nodes[1:] {
+			if d := calculateDistance(spot.X, spot.Y, node.X, node.Y); d < distance {
+				nearest, distance = node, d
+			}
+		}
+		if spot.NodeID != nil && *spot.NodeID == nearest.ID && spot.DistanceToNode == distance {
+			continue
+		}
+		// 来場者数の更新日時（LastUpdated）は変えない
+		if err := db.Model(&TouristSpot{}).Where("id = ?", spot.ID).
+			UpdateColumns(map[string]interface{}{"node_id": nearest.ID, "distance_to_node": distance}).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 翻訳（エンティティ・項目・ロケールごとの文言）
+// 元の値（日本語）はエンティティ側に持ち、翻訳がない項目は日本語のまま返す
+type Translation struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EntityType string    `gorm:"not null;uniqueIndex:idx_translation_key" json:"entity_type"`  // 対象の種類（tourist_spot など）
+	EntityID   uint      `gorm:"not null;uniqueIndex:idx_translation_key" json:"entity_id"`    // 対象のID
+	Field      string    `gorm:"not null;uniqueIndex:idx_translation_key" json:"field"`        // 項目名（name など）
+	Locale     string    `gorm:"not null;uniqueIndex:idx_translation_key;index" json:"locale"` // ロケール（en など）
+	Value      string    `gorm:"type:text;not null" json:"value"`                              // 翻訳した文言
+	UpdatedBy  *uint     `json:"updated_by"`                                                   // 最後に編集したユーザー
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// 翻訳の対象
+const (
+	TranslationTypeSpot     = "tourist_spot"
+	TranslationTypeCategory = "category"
+	TranslationTypeGroup    = "category_group"
+	TranslationTypeTutorial = "tutorial"
+	TranslationTypeNode     = "node"
+)
+
+// 翻訳できる項目（対象の種類 → 項目名）
+var translatableFields = map[string][]string{
+	TranslationTypeSpot:     {"name", "description"},
+	TranslationTypeCategory: {"name", "description"},
+	TranslationTypeGroup:    {"name"},
+	TranslationTypeTutorial: {"title", "description"},
+	TranslationTypeNode:     {"name"},
+}
+
+// 翻訳できる項目かどうか
+func IsTranslatableField(entityType, field string) bool {
+	for _, f := range translatableFields[entityType] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// 指定したエンティティの翻訳を読み込む（ID → 項目名 → 文言）
+func loadTranslations(db *gorm.DB, entityType, locale string, ids []uint) map[uint]map[string]string {
+	result := make(map[uint]map[string]string)
+	if locale == defaultLocale || len(ids) == 0 {
+		return result
+	}
+	var rows []Translation
+	if err := db.Where("entity_type = ? AND locale = ? AND entity_id IN ?", entityType, locale, ids).Find(&rows).Error; err != nil {
+		fmt.Printf("⚠️ 翻訳の取得に失敗: %v\n", err)
+		return result
+	}
+	for _, row := range rows {
+		if result[row.EntityID] == nil {
+			result[row.EntityID] = make(map[string]string)
+		}
+		result[row.EntityID][row.Field] = row.Value
+	}
+	return result
+}
+
+// 翻訳があれば値を置き換える
+func applyTranslation(fields map[string]string, field string, value *string) {
+	if text, ok := fields[field]; ok && text != "" {
+		*value = text
+	}
+}
+
+// 観光地の名前・説明を翻訳する（プリロード済みのカテゴリとグループも翻訳する）
+func TranslateSpots(db *gorm.DB, locale string, spots []TouristSpot) {
+	if locale == defaultLocale || len(spots) == 0 {
+		return
+	}
+	ids := make([]uint, len(spots))
+	var categories []*TouristSpotCategory
+	for i := range spots {
+		ids[i] = spots[i].ID
+		if spots[i].TouristCategory != nil {
+			categories = append(categories, spots[i].TouristCategory)
+		}
+	}
+	translations := loadTranslations(db, TranslationTypeSpot, locale, ids)
+	for i := range spots {
+		fields := translations[spots[i].ID]
+		applyTranslation(fields, "name", &spots[i].Name)
+		applyTranslation(fields, "description", &spots[i].Description)
+	}
+	translateCategoryPointers(db, locale, categories)
+}
+
+// カテゴリの名前・説明を翻訳する（プリロード済みのグループも翻訳する）
+func TranslateCategories(db *gorm.DB, locale string, categories []TouristSpotCategory) {
+	pointers := make([]*TouristSpotCategory, len(categories))
+	for i := range categories {
+		pointers[i] = &categories[i]
+	}
+	translateCategoryPointers(db, locale, pointers)
+}
+
+func translateCategoryPointers(db *gorm.DB, locale string, categories []*TouristSpotCategory) {
+	if locale == defaultLocale || len(categories) == 0 {
+		return
+	}
+	ids := make([]uint, len(categories))
+	var groups []*CategoryGroup
+	for i, category := range categories {
+		ids[i] = category.ID
+		if category.Group != nil {
+			groups = append(groups, category.Group)
+		}
+	}
+	translations := loadTranslations(db, TranslationTypeCategory, locale, ids)
+	for _, category := range categories {
+		fields := translations[category.ID]
+		applyTranslation(fields, "name", &category.Name)
+		applyTranslation(fields, "description", &category.Description)
+	}
+	translateGroupPointers(db, locale, groups)
+}
+
+// カテゴリグループの名前を翻訳する
+func TranslateGroups(db *gorm.DB, locale string, groups []CategoryGroup) {
+	pointers := make([]*CategoryGroup, len(groups))
+	for i := range groups {
+		pointers[i] = &groups[i]
+	}
+	translateGroupPointers(db, locale, pointers)
+}
+
+func translateGroupPointers(db *gorm.DB, locale string, groups []*CategoryGroup) {
+	if locale == defaultLocale || len(groups) == 0 {
+		return
+	}
+	ids := make([]uint, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+	}
+	translations := loadTranslations(db, TranslationTypeGroup, locale, ids)
+	for _, group := range groups {
+		applyTranslation(translations[group.ID], "name", &group.Name)
+	}
+}
+
+// チュートリアルのタイトル・説明を翻訳する
+func TranslateTutorials(db *gorm.DB, locale string, tutorials []Tutorial) {
+	if locale == defaultLocale || len(tutorials) == 0 {
+		return
+	}
+	ids := make([]uint, len(tutorials))
+	for i := range tutorials {
+		ids[i] = tutorials[i].ID
+	}
+	translations := loadTranslations(db, TranslationTypeTutorial, locale, ids)
+	for i := range tutorials {
+		fields := translations[tutorials[i].ID]
+		applyTranslation(fields, "title", &tutorials[i].Title)
+		applyTranslation(fields, "description", &tutorials[i].Description)
+	}
+}
+
+// ノードの名前を翻訳する
+func TranslateNodes(db *gorm.DB, locale string, nodes []Node) {
+	if locale == defaultLocale || len(nodes) == 0 {
+		return
+	}
+	ids := make([]uint, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].ID
+	}
+	translations := loadTranslations(db, TranslationTypeNode, locale, ids)
+	for i := range nodes {
+		applyTranslation(translations[nodes[i].ID], "name", &nodes[i].Name)
+	}
+}
+
+// エンティティの削除時に翻訳も削除する
+func DeleteTranslations(db *gorm.DB, entityType string, entityID uint) {
+	if err := db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Delete(&Translation{}).Error; err != nil {
+		fmt.Printf("⚠️ 翻訳の削除に失敗: %v\n", err)
+	}
+}
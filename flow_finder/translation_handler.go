@@ -0,0 +1,273 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 翻訳対象のテーブル（対象の種類 → テーブル名）
+var translationTables = map[string]string{
+	TranslationTypeSpot:     "tourist_spots",
+	TranslationTypeCategory: "tourist_spot_categories",
+	TranslationTypeGroup:    "category_groups",
+	TranslationTypeTutorial: "tutorials",
+	TranslationTypeNode:     "nodes",
+}
+
+// 多言語対応関連のルートを登録
+func RegisterTranslationRoutes(r *gin.Engine, db *gorm.DB) {
+	// 対応言語の一覧
+	r.GET("/api/locales", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"default":   defaultLocale,
+			"supported": supportedLocales,
+			"current":   RequestLocale(c),
+			"fields":    translatableFields,
+		})
+	})
+
+	admin := r.Group("/api/admin/translations")
+	{
+		// 翻訳の一覧（?entity_type= ?entity_id= ?locale= で絞り込み）
+		admin.GET("", listTranslationsHandler(db))
+
+		// 未翻訳の項目の一覧（?locale= 必須、?entity_type= で絞り込み）
+		admin.GET("/missing", missingTranslationsHandler(db))
+
+		// 翻訳をまとめて登録・更新（value が空の場合は削除）
+		admin.PUT("", upsertTranslationsHandler(db))
+
+		// 翻訳を削除
+		admin.DELETE("/:id", deleteTranslationHandler(db))
+	}
+}
+
+// 翻訳一覧取得ハンドラ
+func listTranslationsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Model(&Translation{})
+		if entityType := c.Query("entity_type"); entityType != "" {
+			query = query.Where("entity_type = ?", entityType)
+		}
+		if entityID := c.Query("entity_id"); entityID != "" {
+			id, err := strconv.ParseUint(entityID, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_id")})
+				return
+			}
+			query = query.Where("entity_id = ?", id)
+		}
+		if locale := c.Query("locale"); locale != "" {
+			query = query.Where("locale = ?", locale)
+		}
+
+		var translations []Translation
+		if err := query.Order("entity_type, entity_id, field, locale").Find(&translations).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "translation.fetch_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, translations)
+	}
+}
+
+// 未翻訳の項目
+type missingTranslation struct {
+	EntityType string `json:"entity_type"`
+	EntityID   uint   `json:"entity_id"`
+	Field      string `json:"field"`
+	Source     string `json:"source"` // 日本語の値
+}
+
+// 未翻訳項目の一覧取得ハンドラ
+func missingTranslationsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := c.Query("locale")
+		if !IsSupportedLocale(locale) || locale == defaultLocale {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "translation.locale_invalid")})
+			return
+		}
+		entityTypes := make([]string, 0, len(translationTables))
+		if entityType := c.Query("entity_type"); entityType != "" {
+			if _, ok := translationTables[entityType]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "translation.entity_type_invalid")})
+				return
+			}
+			entityTypes = append(entityTypes, entityType)
+		} else {
+			entityTypes = append(entityTypes, TranslationTypeSpot, TranslationTypeCategory, TranslationTypeGroup, TranslationTypeTutorial, TranslationTypeNode)
+		}
+
+		missing := []missingTranslation{}
+		for _, entityType := range entityTypes {
+			fields := translatableFields[entityType]
+			var rows []map[string]interface{}
+			if err := db.Table(translationTables[entityType]).Select(append([]string{"id"}, fields...)).Order("id").Find(&rows).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "translation.fetch_failed")})
+				return
+			}
+			ids := make([]uint, 0, len(rows))
+			for _, row := range rows {
+				ids = append(ids, toUint(row["id"]))
+			}
+			translated := loadTranslations(db, entityType, locale, ids)
+			for _, row := range rows {
+				id := toUint(row["id"])
+				for _, field := range fields {
+					source, _ := row[field].(string)
+					if strings.TrimSpace(source) == "" {
+						continue
+					}
+					if _, ok := translated[id][field]; ok {
+						continue
+					}
+					missing = append(missing, missingTranslation{EntityType: entityType, EntityID: id, Field: field, Source: source})
+				}
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"locale": locale, "count": len(missing), "items": missing})
+	}
+}
+
+// DBから読み込んだIDを uint にする
+func toUint(v interface{}) uint {
+	switch n := v.(type) {
+	case int64:
+		return uint(n)
+	case int32:
+		return uint(n)
+	case int:
+		return uint(n)
+	case uint:
+		return n
+	case uint64:
+		return uint(n)
+	}
+	return 0
+}
+
+type translationInput struct {
+	EntityType string `json:"entity_type"`
+	EntityID   uint   `json:"entity_id"`
+	Field      string `json:"field"`
+	Locale     string `json:"locale"`
+	Value      string `json:"value"`
+}
+
+// 翻訳の内容を検証（不正な場合はメッセージIDを返す）
+func (in *translationInput) validate(db *gorm.DB) string {
+	table, ok := translationTables[in.EntityType]
+	if !ok {
+		return "translation.entity_type_invalid"
+	}
+	if !IsTranslatableField(in.EntityType, in.Field) {
+		return "translation.field_invalid"
+	}
+	if !IsSupportedLocale(in.Locale) || in.Locale == defaultLocale {
+		return "translation.locale_invalid"
+	}
+	var count int64
+	db.Table(table).Where("id = ?", in.EntityID).Count(&count)
+	if count == 0 {
+		return "translation.entity_not_found"
+	}
+	return ""
+}
+
+// 翻訳一括登録・更新ハンドラ
+func upsertTranslationsHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Translations []translationInput `json:"translations"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request"), "details": err.Error()})
+			return
+		}
+		if len(req.Translations) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.no_updates")})
+			return
+		}
+		for i := range req.Translations {
+			req.Translations[i].Value = strings.TrimSpace(req.Translations[i].Value)
+			if id := req.Translations[i].validate(db); id != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": T(c, id), "index": i})
+				return
+			}
+		}
+
+		userID := currentUserIDPtr(c)
+		saved := []Translation{}
+		deleted := 0
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, in := range req.Translations {
+				var existing Translation
+				err := tx.Where("entity_type = ? AND entity_id = ? AND field = ? AND locale = ?", in.EntityType, in.EntityID, in.Field, in.Locale).First(&existing).Error
+				found := err == nil
+				if err != nil && err != gorm.ErrRecordNotFound {
+					return err
+				}
+
+				if in.Value == "" {
+					if found {
+						if err := tx.Delete(&existing).Error; err != nil {
+							return err
+						}
+						if err := RecordChangeHistoryTx(tx, "translations", strconv.Itoa(int(existing.ID)), userID, "delete", existing, nil); err != nil {
+							return err
+						}
+						deleted++
+					}
+					continue
+				}
+
+				before := existing
+				translation := existing
+				translation.EntityType = in.EntityType
+				translation.EntityID = in.EntityID
+				translation.Field = in.Field
+				translation.Locale = in.Locale
+				translation.Value = in.Value
+				translation.UpdatedBy = userID
+				if err := tx.Save(&translation).Error; err != nil {
+					return err
+				}
+				if found {
+					err = RecordChangeHistoryTx(tx, "translations", strconv.Itoa(int(translation.ID)), userID, "update", before, translation)
+				} else {
+					err = RecordChangeHistoryTx(tx, "translations", strconv.Itoa(int(translation.ID)), userID, "create", nil, translation)
+				}
+				if err != nil {
+					return err
+				}
+				saved = append(saved, translation)
+			}
+			return nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "translation.save_failed")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": "ok", "saved": saved, "deleted": deleted})
+	}
+}
+
+// 翻訳削除ハンドラ
+func deleteTranslationHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var translation Translation
+		if err := db.First(&translation, c.Param("id")).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "translation.not_found")})
+			return
+		}
+		if err := db.Delete(&translation).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "translation.delete_failed")})
+			return
+		}
+		RecordChangeHistory(db, "translations", c.Param("id"), currentUserIDPtr(c), "delete", translation, nil)
+		c.JSON(http.StatusOK, gin.H{"result": "ok"})
+	}
+}
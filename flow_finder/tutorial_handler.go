@@ -26,16 +26,16 @@ func RegisterTutorialRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Clien
 	r.GET("/api/tutorials/category/:category", getTutorialsByCategoryHandler(db))
 
 	// チュートリアルアップロード（管理者専用）
-	r.POST("/api/tutorials/upload", AdminRequired(db, redisClient), uploadTutorialHandler(db))
+	r.POST("/api/tutorials/upload", uploadTutorialHandler(db))
 
 	// チュートリアル更新（管理者専用）
-	r.PUT("/api/tutorials/:id", AdminRequired(db, redisClient), updateTutorialHandler(db))
+	r.PUT("/api/tutorials/:id", updateTutorialHandler(db))
 
 	// チュートリアル削除（管理者専用）
-	r.DELETE("/api/tutorials/:id", AdminRequired(db, redisClient), deleteTutorialHandler(db))
+	r.DELETE("/api/tutorials/:id", deleteTutorialHandler(db))
 
 	// チュートリアルの表示順を更新（管理者専用）
-	r.PUT("/api/tutorials/:id/order", AdminRequired(db, redisClient), updateTutorialOrderHandler(db))
+	r.PUT("/api/tutorials/:id/order", updateTutorialOrderHandler(db))
 }
 
 // チュートリアル一覧取得ハンドラ
@@ -44,7 +44,7 @@ func getTutorialsHandler(db *gorm.DB) gin.HandlerFunc {
 		tutorials, err := GetActiveTutorials(db)
 		if err != nil {
 			log.Printf("チュートリアル一覧取得エラー: %v", err)
-			c.JSON(500, gin.H{"error": "チュートリアルの取得に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "tutorial.fetch_failed")})
 			return
 		}
 
@@ -52,6 +52,7 @@ func getTutorialsHandler(db *gorm.DB) gin.HandlerFunc {
 		for i := range tutorials {
 			tutorials[i].URL = fmt.Sprintf("/uploads/tutorials/%s", tutorials[i].FileName)
 		}
+		TranslateTutorials(db, RequestLocale(c), tutorials)
 
 		c.JSON(200, tutorials)
 	}
@@ -65,7 +66,7 @@ func getTutorialsByCategoryHandler(db *gorm.DB) gin.HandlerFunc {
 		tutorials, err := GetTutorialsByCategory(db, category)
 		if err != nil {
 			log.Printf("カテゴリ別チュートリアル取得エラー: %v", err)
-			c.JSON(500, gin.H{"error": "チュートリアルの取得に失敗しました"})
+			c.JSON(500, gin.H{"error": T(c, "tutorial.fetch_failed")})
 			return
 		}
 
@@ -73,6 +74,7 @@ func getTutorialsByCategoryHandler(db *gorm.DB) gin.HandlerFunc {
 		for i := range tutorials {
 			tutorials[i].URL = fmt.Sprintf("/uploads/tutorials/%s", tutorials[i].FileName)
 		}
+		TranslateTutorials(db, RequestLocale(c), tutorials)
 
 		c.JSON(200, tutorials)
 	}
@@ -87,13 +89,13 @@ func uploadTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		file, err := c.FormFile("file")
 		if err != nil {
 			log.Printf("ファイル取得エラー: %v", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ファイルが選択されていません"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.file_missing")})
 			return
 		}
 
 		// ファイルサイズチェック（10MB制限）
 		if file.Size > 10*1024*1024 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ファイルサイズが大きすぎます（10MB以下にしてください）"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.too_large")})
 			return
 		}
 
@@ -109,7 +111,7 @@ func uploadTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 		if !isAllowed {
 			log.Printf("許可されていない拡張子: %s", ext)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "サポートされていないファイル形式です"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.unsupported_type")})
 			return
 		}
 
@@ -117,21 +119,21 @@ func uploadTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		uploadDir := "./uploads/tutorials"
 		if err := os.MkdirAll(uploadDir, 0755); err != nil {
 			log.Printf("ディレクトリ作成エラー: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "アップロードディレクトリの作成に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.dir_failed")})
 			return
 		}
 
 		// ファイル内容を読み取ってハッシュを計算
 		src, err := file.Open()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ファイルの読み取りに失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.read_failed")})
 			return
 		}
 		defer src.Close()
 
 		hash := md5.New()
 		if _, err := io.Copy(hash, src); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ファイルハッシュの計算に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.hash_failed")})
 			return
 		}
 		hashString := fmt.Sprintf("%x", hash.Sum(nil))
@@ -155,7 +157,7 @@ func uploadTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		// ファイルを保存
 		if err := c.SaveUploadedFile(file, filePath); err != nil {
 			log.Printf("ファイル保存エラー: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ファイルの保存に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.save_failed")})
 			return
 		}
 
@@ -193,7 +195,7 @@ func uploadTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 
 		if err := db.Create(&tutorial).Error; err != nil {
 			log.Printf("チュートリアル保存エラー: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "チュートリアルの保存に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.save_failed")})
 			return
 		}
 
@@ -216,9 +218,9 @@ func updateTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		var tutorial Tutorial
 		if err := db.First(&tutorial, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "チュートリアルが見つかりません"})
+				c.JSON(http.StatusNotFound, gin.H{"error": T(c, "tutorial.not_found")})
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "チュートリアルの取得に失敗しました"})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.fetch_failed")})
 			}
 			return
 		}
@@ -247,7 +249,7 @@ func updateTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 
 		if err := db.Save(&tutorial).Error; err != nil {
 			log.Printf("チュートリアル更新エラー: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "チュートリアルの更新に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.update_failed")})
 			return
 		}
 
@@ -270,9 +272,9 @@ func deleteTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		var tutorial Tutorial
 		if err := db.First(&tutorial, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "チュートリアルが見つかりません"})
+				c.JSON(http.StatusNotFound, gin.H{"error": T(c, "tutorial.not_found")})
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "チュートリアルの取得に失敗しました"})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.fetch_failed")})
 			}
 			return
 		}
@@ -286,11 +288,12 @@ func deleteTutorialHandler(db *gorm.DB) gin.HandlerFunc {
 		// DBから削除
 		if err := db.Delete(&tutorial).Error; err != nil {
 			log.Printf("チュートリアル削除エラー: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "チュートリアルの削除に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.delete_failed")})
 			return
 		}
 
 		RecordChangeHistory(db, "tutorials", id, nil, "delete", tutorial, nil)
+		DeleteTranslations(db, TranslationTypeTutorial, tutorial.ID)
 		log.Printf("チュートリアル削除成功: ID=%d", tutorial.ID)
 		c.JSON(http.StatusOK, gin.H{
 			"message": "チュートリアルが削除されました",
@@ -308,23 +311,23 @@ func updateTutorialOrderHandler(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		if err := c.BindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエスト"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 
 		var tutorial Tutorial
 		if err := db.First(&tutorial, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "チュートリアルが見つかりません"})
+				c.JSON(http.StatusNotFound, gin.H{"error": T(c, "tutorial.not_found")})
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "チュートリアルの取得に失敗しました"})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.fetch_failed")})
 			}
 			return
 		}
 
 		if err := tutorial.UpdateOrder(db, req.Order); err != nil {
 			log.Printf("表示順更新エラー: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "表示順の更新に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "tutorial.order_failed")})
 			return
 		}
 
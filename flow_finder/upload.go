@@ -15,18 +15,18 @@ func RegisterUploadRoute(r *gin.Engine) {
 	r.POST("/upload", func(c *gin.Context) {
 		file, err := c.FormFile("file")
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ファイルがありません"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "upload.file_missing")})
 			return
 		}
 		dir := "uploads"
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ディレクトリ作成失敗"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.dir_failed")})
 			return
 		}
 		filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(file.Filename))
 		path := filepath.Join(dir, filename)
 		if err := c.SaveUploadedFile(file, path); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存失敗"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "upload.save_failed")})
 			return
 		}
 		url := fmt.Sprintf("/uploads/%s", filename)
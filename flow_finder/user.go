@@ -1,14 +1,61 @@
 package main
 
-import "gorm.io/gorm"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Userモデル
 // 他ファイルから使うために大文字でエクスポート
 // gorm.ModelにはID, CreatedAt, UpdatedAt, DeletedAtが含まれる
 type User struct {
 	gorm.Model
-	Name    string `json:"name"`
-	IsAdmin bool   `json:"is_admin" gorm:"default:false"` // 管理者フラグ
+	Name               string     `json:"name"`
+	IsAdmin            bool       `json:"is_admin" gorm:"default:false"`                // 管理者フラグ
+	PasswordHash       string     `json:"-"`                                            // パスワードハッシュ（bcrypt）
+	PasswordChangedAt  *time.Time `json:"password_changed_at,omitempty"`                // 最終パスワード変更日時
+	MustChangePassword bool       `json:"must_change_password" gorm:"default:false"`    // 管理者リセット後の変更要求フラグ
+	Roles              []Role     `json:"roles,omitempty" gorm:"many2many:user_roles;"` // 付与されたロール
+	IsGuest            bool       `json:"is_guest" gorm:"default:false;index"`          // 未ログインの来訪者用ゲストユーザー
+	OIDCIssuer         string     `json:"-" gorm:"uniqueIndex:idx_user_oidc_subject"`   // OIDCログインのIdP
+	OIDCSubject        *string    `json:"-" gorm:"uniqueIndex:idx_user_oidc_subject"`   // IdPのsubject（ローカルユーザーはnil）
+}
+
+// 組み込みロール名
+const RoleAdmin = "admin"
+
+// トークンに埋め込むロール名の一覧（Rolesはプリロードしておくこと）
+func (u *User) RoleNames() []string {
+	var names []string
+	if u.IsAdmin {
+		names = append(names, RoleAdmin)
+	}
+	for _, r := range u.Roles {
+		if r.Name != RoleAdmin || !u.IsAdmin {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// パスワードが設定済みかどうか
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// パスワードを設定する（ハッシュ化して保存用フィールドに格納）
+func (u *User) SetPassword(password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	u.PasswordHash = hash
+	u.PasswordChangedAt = &now
+	return nil
 }
 
 // デフォルトユーザーを作成（存在しない場合）
@@ -34,3 +81,49 @@ func CreateDefaultUserIfNotExists(db *gorm.DB) error {
 
 	return nil
 }
+
+// 起動時に管理者のパスワードを設定する（ADMIN_PASSWORD）
+// パスワードが未設定のユーザー（パスワード導入前からのユーザー）にのみ設定し、いなければ管理者として作成する。
+// パスワード設定済みのユーザーは上書き・昇格しない（先に同名で登録されたアカウントを乗っ取らせないため）
+func BootstrapAdminUser(db *gorm.DB, name, password string) error {
+	if password == "" {
+		var locked int64
+		db.Model(&User{}).Where("is_admin = ? AND (password_hash IS NULL OR password_hash = '')", true).Count(&locked)
+		if locked > 0 {
+			fmt.Printf("⚠️ パスワード未設定の管理者が%d人います。ADMIN_PASSWORD を指定して起動してください\n", locked)
+		}
+		return nil
+	}
+
+	var user User
+	err := db.Where("name = ?", name).First(&user).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user = User{Name: name, IsAdmin: true}
+		if err := user.SetPassword(password); err != nil {
+			return err
+		}
+		if err := db.Create(&user).Error; err != nil {
+			return err
+		}
+		fmt.Printf("✅ 管理者ユーザー %s を作成しました\n", name)
+		return nil
+	case err != nil:
+		return err
+	case user.HasPassword():
+		if !user.IsAdmin {
+			fmt.Printf("⚠️ %s はパスワード設定済みの一般ユーザーのため管理者にしません\n", name)
+		}
+		return nil
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	user.IsAdmin = true
+	if err := db.Model(&user).Select("password_hash", "password_changed_at", "is_admin").Updates(&user).Error; err != nil {
+		return err
+	}
+	fmt.Printf("✅ 管理者ユーザー %s のパスワードを設定しました\n", name)
+	return nil
+}
@@ -30,7 +30,7 @@ func getUserFavoriteTouristSpotsHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
@@ -44,7 +44,7 @@ func getUserFavoriteTouristSpotsHandler(c *gin.Context, db *gorm.DB) {
 	if priority != "" {
 		priorityInt, parseErr := strconv.Atoi(priority)
 		if parseErr != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無効な優先度です"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "favorite.priority_invalid")})
 			return
 		}
 		favorites, err = GetFavoritesByPriority(db, userID, priorityInt)
@@ -68,7 +68,7 @@ func getUserFavoriteTouristSpotsHandler(c *gin.Context, db *gorm.DB) {
 		}
 		LogUserActivity(db, log)
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "お気に入り観光地の取得に失敗しました"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "favorite.fetch_failed")})
 		return
 	}
 
@@ -92,20 +92,20 @@ func addFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
 	var req AddFavoriteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
 		return
 	}
 
 	// 観光地が存在するかチェック
 	var touristSpot TouristSpot
 	if err := db.First(&touristSpot, req.TouristSpotID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "指定された観光地が見つかりません"})
+		c.JSON(http.StatusNotFound, gin.H{"error": T(c, "spot.not_found")})
 		return
 	}
 
@@ -114,7 +114,7 @@ func addFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 	if err != nil {
 		if err.Error() == "UNIQUE constraint failed: user_favorite_tourist_spots.user_id, user_favorite_tourist_spots.tourist_spot_id" ||
 			err.Error() == "duplicated key not allowed" {
-			c.JSON(http.StatusConflict, gin.H{"error": "この観光地は既にお気に入りに追加されています"})
+			c.JSON(http.StatusConflict, gin.H{"error": T(c, "favorite.already_added")})
 			return
 		}
 		// エラーログ記録
@@ -130,7 +130,7 @@ func addFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 		}
 		LogUserActivity(db, log)
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "お気に入りの追加に失敗しました"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "favorite.add_failed")})
 		return
 	}
 
@@ -161,13 +161,13 @@ func removeFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
 	touristSpotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無効な観光地IDです"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot.invalid_id")})
 		return
 	}
 
@@ -177,7 +177,7 @@ func removeFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 
 	// お気に入りから削除
 	if err := RemoveFromFavorites(db, userID, uint(touristSpotID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "お気に入りの削除に失敗しました"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "favorite.remove_failed")})
 		return
 	}
 
@@ -192,19 +192,19 @@ func updateFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
 	touristSpotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無効な観光地IDです"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot.invalid_id")})
 		return
 	}
 
 	var req UpdateFavoriteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストデータです"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
 		return
 	}
 
@@ -224,13 +224,13 @@ func updateFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 	}
 
 	if len(updates) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "更新するデータがありません"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.no_updates")})
 		return
 	}
 
 	// お気に入りの詳細を更新
 	if err := UpdateFavoriteDetails(db, userID, uint(touristSpotID), updates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "お気に入りの更新に失敗しました"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "favorite.update_failed")})
 		return
 	}
 
@@ -242,20 +242,20 @@ func checkFavoriteTouristSpotHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
 	touristSpotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無効な観光地IDです"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "spot.invalid_id")})
 		return
 	}
 
 	// お気に入り状態をチェック
 	isFavorite, err := IsFavorite(db, userID, uint(touristSpotID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "お気に入り状態の確認に失敗しました"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "favorite.check_failed")})
 		return
 	}
 
@@ -267,7 +267,7 @@ func getFavoriteStatsHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
@@ -308,7 +308,6 @@ func getFavoriteStatsHandler(c *gin.Context, db *gorm.DB) {
 // お気に入り観光地関連のルートを登録
 func RegisterFavoriteRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	favorites := r.Group("/api/favorites")
-	favorites.Use(AuthRequired(redisClient)) // 認証必須
 	{
 		// お気に入り一覧取得
 		favorites.GET("/tourist-spots", func(c *gin.Context) {
@@ -352,7 +351,7 @@ func addCategoryFavoritesHandler(c *gin.Context, db *gorm.DB) {
 	// 認証されたユーザーIDを取得
 	userID, exists := GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
 		return
 	}
 
@@ -360,7 +359,7 @@ func addCategoryFavoritesHandler(c *gin.Context, db *gorm.DB) {
 	categoryIDStr := c.Param("categoryId")
 	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無効なカテゴリーIDです"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "category.invalid_id")})
 		return
 	}
 
@@ -368,9 +367,9 @@ func addCategoryFavoritesHandler(c *gin.Context, db *gorm.DB) {
 	var category TouristSpotCategory
 	if err := db.First(&category, categoryID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "カテゴリーが見つかりません"})
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "category.not_found")})
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "カテゴリーの確認に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "category.fetch_failed")})
 		}
 		return
 	}
@@ -378,7 +377,7 @@ func addCategoryFavoritesHandler(c *gin.Context, db *gorm.DB) {
 	// そのカテゴリーの観光地を取得
 	var touristSpots []TouristSpot
 	if err := db.Where("category_id = ?", categoryID).Find(&touristSpots).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "観光地の取得に失敗しました"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "spot.fetch_failed")})
 		return
 	}
 
@@ -426,7 +425,7 @@ func addCategoryFavoritesHandler(c *gin.Context, db *gorm.DB) {
 	// バッチでお気に入りに追加
 	if len(newFavorites) > 0 {
 		if err := db.Create(&newFavorites).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "お気に入りの追加に失敗しました"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "favorite.add_failed")})
 			return
 		}
 
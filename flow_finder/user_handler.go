@@ -1,34 +1,43 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 // ユーザー関連のルートを登録
-func RegisterUserRoutes(r *gin.Engine, db *gorm.DB) {
-	// ユーザー追加
+func RegisterUserRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+	// ユーザー登録（名前とパスワードが必須）
 	r.POST("/api/users", func(c *gin.Context) {
 		var req struct {
-			Name string `json:"name"`
+			Name     string `json:"name" binding:"required"`
+			Password string `json:"password" binding:"required"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": "invalid request"})
+			c.JSON(400, gin.H{"error": T(c, "common.invalid_request")})
 			return
 		}
 
 		// 既存ユーザーの重複チェック
 		var existingUser User
 		if err := db.Where("name = ?", req.Name).First(&existingUser).Error; err == nil {
-			c.JSON(409, gin.H{"error": "user already exists"})
+			c.JSON(409, gin.H{"error": T(c, "user.already_exists")})
 			return
 		}
 
 		user := User{Name: req.Name}
+		if err := user.SetPassword(req.Password); err != nil {
+			c.JSON(400, gin.H{"error": errorText(c, err)})
+			return
+		}
 		if err := db.Create(&user).Error; err != nil {
-			c.JSON(500, gin.H{"error": "DB insert error"})
+			c.JSON(500, gin.H{"error": T(c, "common.save_failed")})
 			return
 		}
 
@@ -40,16 +49,169 @@ func RegisterUserRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		LogDatabaseOperation(db, userID, sessionID, "create", "users", fmt.Sprintf("%d", user.ID), c)
 
-		c.JSON(200, gin.H{"result": "ok", "user_id": user.ID})
+		// ゲストとして利用していた場合はお気に入り・ログを引き継ぐ
+		merged := mergeGuestOnLogin(c, db, redisClient, user.ID)
+
+		c.JSON(200, gin.H{"result": "ok", "user_id": user.ID, "merged_guest": merged})
+	})
+
+	// ログイン中のユーザー情報取得
+	r.GET("/api/users/me", func(c *gin.Context) {
+		userID, _ := GetUserIDFromContext(c)
+		var user User
+		if err := db.Preload("Roles").First(&user, userID).Error; err != nil {
+			c.JSON(404, gin.H{"error": T(c, "user.not_found")})
+			return
+		}
+		c.JSON(200, user)
 	})
 
-	// ユーザー一覧取得
+	// ユーザー一覧取得（ユーザー管理権限が必要）
 	r.GET("/api/users", func(c *gin.Context) {
 		var users []User
 		if err := db.Find(&users).Error; err != nil {
-			c.JSON(500, gin.H{"error": "DB select error"})
+			c.JSON(500, gin.H{"error": T(c, "common.fetch_failed")})
 			return
 		}
 		c.JSON(200, users)
 	})
+
+	// 自分のパスワードを変更
+	r.PUT("/api/users/me/password", changePasswordHandler(db, redisClient))
+
+	// パスワードをリセット（管理者専用）
+	r.POST("/api/users/:id/reset-password", resetPasswordHandler(db, redisClient))
+}
+
+// パスワード変更ハンドラ
+func changePasswordHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "auth.required")})
+			return
+		}
+
+		var req struct {
+			CurrentPassword string `json:"current_password"`
+			NewPassword     string `json:"new_password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": T(c, "common.invalid_request")})
+			return
+		}
+
+		var user User
+		if err := db.First(&user, userID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "user.not_found")})
+			return
+		}
+
+		// ゲストはパスワードを持たない（ユーザー登録でデータを引き継ぐ）
+		if user.IsGuest {
+			c.JSON(http.StatusForbidden, gin.H{"error": T(c, "user.guest_password")})
+			return
+		}
+
+		// 現在のパスワードなしで設定できるのは、管理者が変更を要求したパスワード未設定のユーザーのみ
+		// （OIDCのユーザーなど、セッションだけでパスワードを作れないようにする）
+		if !user.HasPassword() {
+			if !user.MustChangePassword {
+				c.JSON(http.StatusForbidden, gin.H{"error": T(c, "password.not_set")})
+				return
+			}
+		} else if !CheckPassword(user.PasswordHash, req.CurrentPassword) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": T(c, "password.current_incorrect")})
+			return
+		}
+
+		if err := user.SetPassword(req.NewPassword); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"password_hash":        user.PasswordHash,
+			"password_changed_at":  user.PasswordChangedAt,
+			"must_change_password": false,
+		}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "password.update_failed")})
+			return
+		}
+
+		sessionID := c.GetHeader("X-Session-Id")
+		if sessionID == "" {
+			sessionID = generateHandlerSessionID()
+		}
+		LogDatabaseOperation(db, &userID, sessionID, "update", "users", strconv.Itoa(int(userID)), c)
+
+		// 変更要求を解除し、現在のセッション以外を失効させる
+		redisClient.Del(context.Background(), mustChangePasswordKey(userID))
+		RevokeUserSessions(context.Background(), redisClient, userID, currentSessionID(c, redisClient))
+
+		c.JSON(http.StatusOK, gin.H{"result": "ok"})
+	}
+}
+
+// 管理者によるパスワードリセットハンドラ
+// passwordを指定しない場合は一時パスワードを生成して返す
+func resetPasswordHandler(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var user User
+		if err := db.First(&user, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": T(c, "user.not_found")})
+			return
+		}
+
+		var req struct {
+			Password string `json:"password"`
+		}
+		// ボディは省略可能
+		_ = c.ShouldBindJSON(&req)
+
+		password := req.Password
+		generated := false
+		if password == "" {
+			tmp, err := GenerateTemporaryPassword(12)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "password.generate_failed")})
+				return
+			}
+			password = tmp
+			generated = true
+		}
+
+		if err := user.SetPassword(password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorText(c, err)})
+			return
+		}
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"password_hash":        user.PasswordHash,
+			"password_changed_at":  user.PasswordChangedAt,
+			"must_change_password": true,
+		}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": T(c, "password.reset_failed")})
+			return
+		}
+
+		var adminID *uint
+		if uid, ok := GetUserIDFromContext(c); ok {
+			adminID = &uid
+		}
+		sessionID := c.GetHeader("X-Session-Id")
+		if sessionID == "" {
+			sessionID = generateHandlerSessionID()
+		}
+		LogDatabaseOperation(db, adminID, sessionID, "update", "users", id, c)
+
+		// リセット対象ユーザーの既存セッションは全て失効させ、次のログインから変更を求める
+		RevokeUserSessions(context.Background(), redisClient, user.ID, "")
+		redisClient.Set(context.Background(), mustChangePasswordKey(user.ID), "1", RefreshTokenTTL)
+
+		resp := gin.H{"result": "ok", "user_id": user.ID, "must_change_password": true}
+		if generated {
+			resp["temporary_password"] = password
+		}
+		c.JSON(http.StatusOK, resp)
+	}
 }
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 来場者数の更新が許容範囲外だった場合のエラー
+type VisitorCountError struct {
+	ID   string // メッセージID
+	Args []interface{}
+}
+
+func newVisitorCountError(id string, args ...interface{}) *VisitorCountError {
+	return &VisitorCountError{ID: id, Args: args}
+}
+
+func (e *VisitorCountError) Error() string {
+	return Message(defaultLocale, e.ID, e.Args...)
+}
+
+// 満員（キャパシティポリシーのハード上限）で入場を止めたかどうか
+func (e *VisitorCountError) IsFull() bool {
+	return e.ID == "spot.capacity_full"
+}
+
+// 来場者数を原子的に増減する（delta > 0 で増加、delta < 0 で減少）
+// 読み込み→保存ではなく条件付きUPDATEで更新するため、複数ゲートから同時に更新しても取りこぼさない
+// キャパシティポリシーが有効な場合、増加はハード上限まで・満員の間は受け付けない
+// 戻り値は更新前・更新後の観光地
+func AdjustVisitorCount(db *gorm.DB, id uint, delta int) (before, after *TouristSpot, err error) {
+	var policy *SpotCapacityPolicy
+	if delta > 0 {
+		if policy, err = GetCapacityPolicy(db, id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var spot TouristSpot
+	query := db.Model(&spot).Clauses(clause.Returning{}).Where("id = ?", id)
+	switch {
+	case delta > 0 && policy.Enabled:
+		query = query.Where("(current_count + ?) * 100 <= max_capacity * ?", delta, policy.HardLimitRatio).
+			Where("NOT EXISTS (SELECT 1 FROM spot_capacity_policies p WHERE p.tourist_spot_id = tourist_spots.id AND p.enabled AND p.state = ?)", CapacityStateFull)
+	case delta > 0:
+		query = query.Where("current_count + ? <= max_capacity", delta)
+	default:
+		query = query.Where("current_count + ? >= 0", delta)
+	}
+	result := query.Update("current_count", gorm.Expr("current_count + ?", delta))
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// 観光地が存在しないか、許容範囲を外れる
+		var current TouristSpot
+		if err := db.First(&current, id).Error; err != nil {
+			return nil, nil, err
+		}
+		if delta > 0 && policy.Enabled {
+			// 満員中か、ハード上限に達している場合は満員として扱う
+			_, hard, _ := policy.Limits(&current)
+			if latest, err := GetCapacityPolicy(db, id); err == nil && (latest.State == CapacityStateFull || current.CurrentCount >= hard) {
+				return nil, nil, newVisitorCountError("spot.capacity_full", current.Name)
+			}
+			return nil, nil, newVisitorCountError("spot.capacity_exceeded", current.CurrentCount, delta, hard)
+		}
+		if delta > 0 {
+			return nil, nil, newVisitorCountError("spot.capacity_exceeded", current.CurrentCount, delta, current.MaxCapacity)
+		}
+		return nil, nil, newVisitorCountError("spot.count_below_zero", current.CurrentCount, -delta)
+	}
+
+	prev := spot
+	prev.CurrentCount -= delta
+	return &prev, &spot, nil
+}
+
+// 来場者数を直接設定する（行ロックで同時更新と直列化）
+func SetVisitorCount(db *gorm.DB, id uint, count int) (before, after *TouristSpot, err error) {
+	if count < 0 {
+		return nil, nil, newVisitorCountError("spot.count_negative")
+	}
+
+	var prev, spot TouristSpot
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&prev, id).Error; err != nil {
+			return err
+		}
+		spot = prev
+		return tx.Model(&spot).Update("current_count", count).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &prev, &spot, nil
+}
+
+// センサー（入退場カウンター）の集計を反映する（トランザクション内で呼び出す）
+// 実際の人数を表すため許容人数は超えてもよいが、0未満にはせず、下回る分は切り捨てる
+// 戻り値は更新後の観光地と、切り捨てた人数
+func ApplySensorVisitorDelta(db *gorm.DB, id uint, delta int) (*TouristSpot, int, error) {
+	var current TouristSpot
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "current_count").First(&current, id).Error; err != nil {
+		return nil, 0, err
+	}
+	clamped := 0
+	if current.CurrentCount+delta < 0 {
+		clamped = -(current.CurrentCount + delta)
+	}
+
+	var spot TouristSpot
+	result := db.Model(&spot).Clauses(clause.Returning{}).Where("id = ?", id).
+		Update("current_count", gorm.Expr("GREATEST(current_count + ?, 0)", delta))
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, 0, gorm.ErrRecordNotFound
+	}
+	return &spot, clamped, nil
+}
+
+// 来場者数の更新内容（APIとMQTTで共通）
+type VisitorUpdate struct {
+	Action       string `json:"action"`        // "increment" or "decrement" (optional)
+	Count        int    `json:"count"`         // for increment/decrement
+	CurrentCount *int   `json:"current_count"` // for direct set
+}
+
+// 来場者数を更新し、変更履歴の記録・入場状態の判定・購読中のクライアントへの配信・混雑アラートの判定まで行う
+// source は配信イベントの更新元（visitors | mqtt）
+func ApplyVisitorUpdate(db *gorm.DB, redisClient *redis.Client, spotID uint, req VisitorUpdate, userID *uint, source string) (*TouristSpot, error) {
+	// 同時更新で取りこぼさないよう、DB上で原子的に更新する
+	var beforeSpot, spot *TouristSpot
+	var err error
+	if req.CurrentCount != nil {
+		// 直接来場者数を設定する場合
+		beforeSpot, spot, err = SetVisitorCount(db, spotID, *req.CurrentCount)
+	} else {
+		// incrementまたはdecrementの場合
+		if req.Count == 0 {
+			req.Count = 1
+		}
+		if req.Count < 0 {
+			return nil, newVisitorCountError("spot.visitor_count_invalid")
+		}
+
+		switch req.Action {
+		case "increment":
+			beforeSpot, spot, err = AdjustVisitorCount(db, spotID, req.Count)
+		case "decrement":
+			beforeSpot, spot, err = AdjustVisitorCount(db, spotID, -req.Count)
+		default:
+			return nil, newVisitorCountError("spot.visitor_action_invalid")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 変更履歴を記録
+	RecordChangeHistory(db, "tourist_spots", strconv.Itoa(int(spot.ID)), userID, "update", beforeSpot, spot)
+
+	// 入場状態の判定（満員・再開）
+	evaluateCapacityState(db, redisClient, spot, source)
+
+	// 購読中のクライアントへ配信
+	notifyCongestionChange(db, redisClient, spot, source, nil)
+
+	// 混雑アラートの判定
+	go EvaluateCongestionAlerts(db, spot.ID)
+
+	return spot, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// テスト用のPostgreSQLに接続する（TEST_DATABASE_DSN が未設定の場合はスキップ）
+// 例: TEST_DATABASE_DSN="host=localhost port=5432 user=postgres password=postgres dbname=flow_finder_test sslmode=disable"
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN が未設定のためスキップ")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("テスト用DBに接続できません: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.SetMaxOpenConns(20)
+	}
+	if err := db.AutoMigrate(&Field{}, &Node{}, &CategoryGroup{}, &TouristSpotCategory{}, &TouristSpot{}, &CongestionRecord{}, &ChangeHistory{}, &Webhook{}, &WebhookDelivery{}, &AlertRule{}, &AlertState{}, &SensorDevice{}, &SensorEvent{}, &SpotCapacityPolicy{}, &SpotCapacityTransition{}); err != nil {
+		t.Fatalf("AutoMigrate失敗: %v", err)
+	}
+	return db
+}
+
+// テスト用の観光地を作成し、終了時に削除する
+func createTestSpot(t *testing.T, db *gorm.DB, spot TouristSpot) *TouristSpot {
+	t.Helper()
+	if err := db.Create(&spot).Error; err != nil {
+		t.Fatalf("観光地の作成失敗: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("tourist_spot_id = ?", spot.ID).Delete(&SpotCapacityTransition{})
+		db.Where("tourist_spot_id = ?", spot.ID).Delete(&SpotCapacityPolicy{})
+		db.Delete(&TouristSpot{}, spot.ID)
+	})
+	return &spot
+}
+
+func TestAdjustVisitorCountConcurrent(t *testing.T) {
+	db := openTestDB(t)
+
+	const maxCapacity = 40
+	tests := []struct {
+		name   string
+		policy *SpotCapacityPolicy
+		limit  int // 受け付ける人数
+	}{
+		{name: "許容人数まで", limit: maxCapacity},
+		{name: "ハード上限まで", policy: &SpotCapacityPolicy{Enabled: true, SoftLimitRatio: 50, HardLimitRatio: 75}, limit: maxCapacity * 75 / 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spot := createTestSpot(t, db, TouristSpot{Name: "同時更新テスト", MaxCapacity: maxCapacity})
+			if tt.policy != nil {
+				policy := *tt.policy
+				policy.TouristSpotID = spot.ID
+				policy.applyDefaults()
+				if err := db.Create(&policy).Error; err != nil {
+					t.Fatalf("ポリシーの作成失敗: %v", err)
+				}
+			}
+
+			// 受け付ける人数より多い増加を同時に送る
+			const attempts = maxCapacity + 20
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				accepted int
+				rejected int
+				failures []error
+			)
+			for i := 0; i < attempts; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _, err := AdjustVisitorCount(db, spot.ID, 1)
+					mu.Lock()
+					defer mu.Unlock()
+					var countErr *VisitorCountError
+					switch {
+					case err == nil:
+						accepted++
+					case errors.As(err, &countErr):
+						rejected++
+					default:
+						failures = append(failures, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if len(failures) > 0 {
+				t.Fatalf("予期しないエラー: %v", failures[0])
+			}
+			if accepted != tt.limit || rejected != attempts-tt.limit {
+				t.Errorf("accepted=%d rejected=%d, want accepted=%d rejected=%d", accepted, rejected, tt.limit, attempts-tt.limit)
+			}
+
+			var got TouristSpot
+			if err := db.First(&got, spot.ID).Error; err != nil {
+				t.Fatalf("観光地の取得失敗: %v", err)
+			}
+			if got.CurrentCount != tt.limit {
+				t.Errorf("current_count=%d, want %d", got.CurrentCount, tt.limit)
+			}
+
+			// 上限に達した後の増加は拒否され、人数は変わらない
+			if _, _, err := AdjustVisitorCount(db, spot.ID, 1); err == nil {
+				t.Error("上限を超える増加が受け付けられた")
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhookの署名・配信に使うヘッダー
+const (
+	WebhookSignatureHeader = "X-FlowFinder-Signature" // t=<UNIX秒>,v1=<HMAC-SHA256(secret, "<t>.<body>")の16進>
+	WebhookEventHeader     = "X-FlowFinder-Event"
+	WebhookDeliveryHeader  = "X-FlowFinder-Delivery"
+)
+
+// 配信状態
+const (
+	DeliveryPending = "pending" // 送信待ち（再送待ちを含む）
+	DeliverySending = "sending" // 送信中（ワーカーが確保済み）
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed" // 再送回数の上限に達した
+)
+
+const (
+	webhookMaxAttempts  = 6                // 最大送信回数
+	webhookRetryBase    = 30 * time.Second // 再送間隔の基準（送信回数ごとに2倍）
+	webhookTimeout      = 10 * time.Second // 1回の送信のタイムアウト
+	webhookLease        = time.Minute      // 送信中として確保する時間（ワーカー停止時はこの後に再送）
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	webhookSecretBytes  = 24
+)
+
+// 送信先のWebhook
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	URL       string    `gorm:"not null" json:"url"`
+	Secret    string    `gorm:"not null" json:"-"` // 署名用のシークレット（作成時のみ返す）
+	Enabled   bool      `json:"enabled"`           // 作成時に明示的に設定する（default:true だと false が無視される）
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Webhookの配信記録
+type WebhookDelivery struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	WebhookID     uint       `gorm:"not null;index" json:"webhook_id"`
+	AlertRuleID   *uint      `gorm:"index" json:"alert_rule_id"`
+	TouristSpotID *uint      `json:"tourist_spot_id"`
+	Event         string     `gorm:"not null" json:"event"` // alert.triggered | alert.resolved | capacity.changed | ping
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"not null;default:pending;index:idx_webhook_delivery_due,priority:1" json:"status"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `gorm:"index:idx_webhook_delivery_due,priority:2" json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// 署名用シークレットを生成
+func GenerateWebhookSecret() (string, error) {
+	token, err := GenerateToken(webhookSecretBytes)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
+
+// Webhook URLの検証（http/httpsの絶対URLのみ）
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return newLocalizedError("webhook.url_invalid")
+	}
+	return nil
+}
+
+// 署名ヘッダーの値を作成
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var errWebhookSignature = errors.New("invalid webhook signature")
+
+// 署名ヘッダーを検証（受信側の実装・動作確認用。tolerance を過ぎた署名は拒否）
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errWebhookSignature
+	}
+	signedAt := time.Unix(unix, 0)
+	if tolerance > 0 && time.Since(signedAt).Abs() > tolerance {
+		return errWebhookSignature
+	}
+	expected := SignWebhookPayload(secret, signedAt, body)
+	if !hmac.Equal([]byte(expected), []byte("t="+ts+",v1="+sig)) {
+		return errWebhookSignature
+	}
+	return nil
+}
+
+// ワーカーへ新しい配信があることを知らせる
+var webhookKick = make(chan struct{}, 1)
+
+func kickWebhookWorker() {
+	select {
+	case webhookKick <- struct{}{}:
+	default:
+	}
+}
+
+// 配信を登録（実際の送信はワーカーが行う）
+func EnqueueWebhookDelivery(db *gorm.DB, webhookID uint, event string, payload interface{}, ruleID, spotID *uint) (*WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	delivery := WebhookDelivery{
+		WebhookID:     webhookID,
+		AlertRuleID:   ruleID,
+		TouristSpotID: spotID,
+		Event:         event,
+		Payload:       string(body),
+		Status:        DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	kickWebhookWorker()
+	return &delivery, nil
+}
+
+var webhookHTTPClient = &http.Client{Timeout: webhookTimeout}
+
+// 1回分の送信（ステータスコードとエラーを返す）
+func sendWebhook(ctx context.Context, hook *Webhook, delivery *WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FlowFinder-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(int(delivery.ID)))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(hook.Secret, time.Now(), body))
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// 確保した配信を送信して結果を記録
+func attemptWebhookDelivery(ctx context.Context, db *gorm.DB, delivery *WebhookDelivery) {
+	var hook Webhook
+	if err := db.First(&hook, delivery.WebhookID).Error; err != nil || !hook.Enabled {
+		db.Model(delivery).Updates(map[string]interface{}{"status": DeliveryFailed, "last_error": "webhook disabled or deleted"})
+		return
+	}
+
+	code, err := sendWebhook(ctx, &hook, delivery)
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts, "response_code": code}
+	switch {
+	case err == nil:
+		now := time.Now()
+		updates["status"] = DeliverySuccess
+		updates["delivered_at"] = &now
+		updates["last_error"] = ""
+	case attempts >= webhookMaxAttempts:
+		updates["status"] = DeliveryFailed
+		updates["last_error"] = err.Error()
+		fmt.Printf("⚠️ Webhook配信に失敗（再送上限） - Delivery: %d, Error: %v\n", delivery.ID, err)
+	default:
+		updates["status"] = DeliveryPending
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = time.Now().Add(webhookRetryBase << (attempts - 1))
+	}
+	if err := db.Model(delivery).Updates(updates).Error; err != nil {
+		fmt.Printf("⚠️ Webhook配信結果の保存に失敗 - Delivery: %d, Error: %v\n", delivery.ID, err)
+	}
+}
+
+// 送信時刻を過ぎた配信を確保する（複数インスタンスで重複しないよう SKIP LOCKED）
+func claimWebhookDeliveries(db *gorm.DB, now time.Time) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := db.Raw(`
+		UPDATE webhook_deliveries SET status = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN (?, ?) AND next_attempt_at <= ?
+			ORDER BY id LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		DeliverySending, now.Add(webhookLease), now, DeliveryPending, DeliverySending, now, webhookBatchSize,
+	).Scan(&deliveries).Error
+	return deliveries, err
+}
+
+// Webhook配信ワーカーを開始
+func StartWebhookWorker(ctx context.Context, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-webhookKick:
+			}
+
+			deliveries, err := claimWebhookDeliveries(db, time.Now())
+			if err != nil {
+				fmt.Printf("⚠️ Webhook配信の取得に失敗: %v\n", err)
+				continue
+			}
+			for i := range deliveries {
+				attemptWebhookDelivery(ctx, db, &deliveries[i])
+			}
+			if len(deliveries) == webhookBatchSize {
+				kickWebhookWorker() // 残りがあれば続けて処理
+			}
+		}
+	}()
+}
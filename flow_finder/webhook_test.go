@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"event":"ping"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		wantErr bool
+	}{
+		{name: "正しい署名", secret: secret, header: SignWebhookPayload(secret, now, body), body: body},
+		{name: "別のシークレット", secret: "whsec_other", header: SignWebhookPayload(secret, now, body), body: body, wantErr: true},
+		{name: "本文の改ざん", secret: secret, header: SignWebhookPayload(secret, now, body), body: []byte(`{"event":"alert.triggered"}`), wantErr: true},
+		{name: "許容時間を過ぎた署名", secret: secret, header: SignWebhookPayload(secret, now.Add(-10*time.Minute), body), body: body, wantErr: true},
+		{name: "v1がない", secret: secret, header: "t=" + strconv.FormatInt(now.Unix(), 10), body: body, wantErr: true},
+		{name: "空のヘッダー", secret: secret, header: "", body: body, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(tt.secret, tt.header, tt.body, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// テスト用の受信サーバー（statusesの順に応答し、受け取ったリクエストを記録する）
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	rcv := &webhookReceiver{statuses: statuses}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		status := http.StatusOK
+		if n := len(rcv.requests); n < len(rcv.statuses) {
+			status = rcv.statuses[n]
+		}
+		rcv.requests = append(rcv.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		rcv.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (rcv *webhookReceiver) received() []receivedWebhook {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]receivedWebhook(nil), rcv.requests...)
+}
+
+func createTestWebhook(t *testing.T, db *gorm.DB, url string) *Webhook {
+	t.Helper()
+	secret, err := GenerateWebhookSecret()
+	if err != nil {
+		t.Fatalf("シークレットの生成失敗: %v", err)
+	}
+	hook := Webhook{Name: "テスト", URL: url, Secret: secret, Enabled: true}
+	if err := db.Create(&hook).Error; err != nil {
+		t.Fatalf("Webhookの作成失敗: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("webhook_id = ?", hook.ID).Delete(&WebhookDelivery{})
+		db.Delete(&hook)
+	})
+	return &hook
+}
+
+// 5xxの場合は再送を予約し、成功するまで配信記録を更新する
+func TestAttemptWebhookDelivery(t *testing.T) {
+	db := openTestDB(t)
+	rcv := newWebhookReceiver(t, http.StatusServiceUnavailable, http.StatusOK)
+	hook := createTestWebhook(t, db, rcv.URL)
+
+	delivery, err := EnqueueWebhookDelivery(db, hook.ID, WebhookEventPing, map[string]string{"message": "test"}, nil, nil)
+	if err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	reload := func() *WebhookDelivery {
+		t.Helper()
+		var got WebhookDelivery
+		if err := db.First(&got, delivery.ID).Error; err != nil {
+			t.Fatalf("配信記録の取得失敗: %v", err)
+		}
+		return &got
+	}
+
+	// 1回目: 503 → 再送待ち
+	before := time.Now().Truncate(time.Microsecond) // DBはマイクロ秒で保存する
+	attemptWebhookDelivery(context.Background(), db, reload())
+	got := reload()
+	if got.Status != DeliveryPending || got.Attempts != 1 || got.ResponseCode != http.StatusServiceUnavailable || got.LastError == "" {
+		t.Fatalf("1回目: status=%s attempts=%d code=%d last_error=%q", got.Status, got.Attempts, got.ResponseCode, got.LastError)
+	}
+	if next := got.NextAttemptAt; next.Before(before.Add(webhookRetryBase)) || next.After(time.Now().Add(webhookRetryBase)) {
+		t.Errorf("next_attempt_at = %v, want about %v later", next, webhookRetryBase)
+	}
+
+	// 2回目: 200 → 成功
+	attemptWebhookDelivery(context.Background(), db, got)
+	got = reload()
+	if got.Status != DeliverySuccess || got.Attempts != 2 || got.ResponseCode != http.StatusOK || got.LastError != "" || got.DeliveredAt == nil {
+		t.Fatalf("2回目: status=%s attempts=%d code=%d last_error=%q delivered_at=%v", got.Status, got.Attempts, got.ResponseCode, got.LastError, got.DeliveredAt)
+	}
+
+	// 受信側で署名を検証できる
+	requests := rcv.received()
+	if len(requests) != 2 {
+		t.Fatalf("受信数 = %d, want 2", len(requests))
+	}
+	for i, req := range requests {
+		if err := VerifyWebhookSignature(hook.Secret, req.header.Get(WebhookSignatureHeader), req.body, time.Minute); err != nil {
+			t.Errorf("リクエスト%d: 署名の検証に失敗: %v", i+1, err)
+		}
+		if req.header.Get(WebhookEventHeader) != WebhookEventPing || req.header.Get(WebhookDeliveryHeader) != strconv.Itoa(int(delivery.ID)) {
+			t.Errorf("リクエスト%d: header = %v", i+1, req.header)
+		}
+		if string(req.body) != delivery.Payload {
+			t.Errorf("リクエスト%d: body = %s, want %s", i+1, req.body, delivery.Payload)
+		}
+	}
+}
+
+// 再送回数の上限に達したら失敗として残す（再送間隔は送信回数ごとに2倍）
+func TestAttemptWebhookDeliveryBackoff(t *testing.T) {
+	db := openTestDB(t)
+	rcv := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	hook := createTestWebhook(t, db, rcv.URL)
+
+	delivery, err := EnqueueWebhookDelivery(db, hook.ID, WebhookEventPing, map[string]string{"message": "test"}, nil, nil)
+	if err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		var current WebhookDelivery
+		if err := db.First(&current, delivery.ID).Error; err != nil {
+			t.Fatalf("配信記録の取得失敗: %v", err)
+		}
+		before := time.Now().Truncate(time.Microsecond) // DBはマイクロ秒で保存する
+		attemptWebhookDelivery(context.Background(), db, &current)
+
+		var got WebhookDelivery
+		if err := db.First(&got, delivery.ID).Error; err != nil {
+			t.Fatalf("配信記録の取得失敗: %v", err)
+		}
+		if got.Attempts != attempt {
+			t.Fatalf("attempts = %d, want %d", got.Attempts, attempt)
+		}
+		if attempt == webhookMaxAttempts {
+			if got.Status != DeliveryFailed {
+				t.Errorf("status = %s, want %s", got.Status, DeliveryFailed)
+			}
+			break
+		}
+		backoff := webhookRetryBase << (attempt - 1)
+		if got.Status != DeliveryPending || got.NextAttemptAt.Before(before.Add(backoff)) || got.NextAttemptAt.After(time.Now().Add(backoff)) {
+			t.Errorf("attempt %d: status=%s next_attempt_at=%v, want pending after %v", attempt, got.Status, got.NextAttemptAt, backoff)
+		}
+	}
+	if n := len(rcv.received()); n != webhookMaxAttempts {
+		t.Errorf("受信数 = %d, want %d", n, webhookMaxAttempts)
+	}
+}
+
+// 閾値を超えたら発報し、ヒステリシス分下がるまで解除しない
+func TestEvaluateCongestionAlerts(t *testing.T) {
+	db := openTestDB(t)
+	hook := createTestWebhook(t, db, "http://127.0.0.1:1/unused")
+	spot := createTestSpot(t, db, TouristSpot{Name: "アラートテスト", MaxCapacity: 100})
+
+	spotID := spot.ID
+	rule := AlertRule{Name: "混雑", TouristSpotID: &spotID, ThresholdRatio: 80, Hysteresis: 10, WebhookID: hook.ID, Enabled: true}
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("ルールの作成失敗: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("alert_rule_id = ?", rule.ID).Delete(&AlertState{})
+		db.Delete(&rule)
+	})
+
+	steps := []struct {
+		count int
+		want  string // 登録される配信のイベント（空の場合は配信なし）
+	}{
+		{count: 50},
+		{count: 85, want: AlertEventTriggered},
+		{count: 95},
+		{count: 75}, // 閾値未満だがヒステリシスの範囲内
+		{count: 65, want: AlertEventResolved},
+		{count: 60},
+		{count: 80, want: AlertEventTriggered},
+	}
+	var lastID uint
+	for _, step := range steps {
+		if err := db.Model(&TouristSpot{}).Where("id = ?", spot.ID).Update("current_count", step.count).Error; err != nil {
+			t.Fatalf("来場者数の更新失敗: %v", err)
+		}
+		EvaluateCongestionAlerts(db, spot.ID)
+
+		var deliveries []WebhookDelivery
+		if err := db.Where("alert_rule_id = ? AND id > ?", rule.ID, lastID).Order("id").Find(&deliveries).Error; err != nil {
+			t.Fatalf("配信記録の取得失敗: %v", err)
+		}
+		var events []string
+		for _, d := range deliveries {
+			events = append(events, d.Event)
+			lastID = d.ID
+		}
+		switch {
+		case step.want == "" && len(events) != 0:
+			t.Errorf("count=%d: events = %v, want none", step.count, events)
+		case step.want != "" && (len(events) != 1 || events[0] != step.want):
+			t.Errorf("count=%d: events = %v, want [%s]", step.count, events, step.want)
+		}
+	}
+}
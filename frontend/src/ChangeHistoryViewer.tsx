@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from "react";
 import { API_BASE_URL } from "./config";
+import { getAuthHeaders, downloadWithAuth } from "./api";
 
 interface ChangeHistory {
   id: number;
@@ -52,7 +53,7 @@ const ChangeHistoryViewer: React.FC = () => {
       if (filters.operation) params.append("operation", filters.operation);
       if (filters.userId) params.append("user_id", filters.userId);
 
-      const response = await fetch(`${API_BASE_URL}/change-history?${params}`);
+      const response = await fetch(`${API_BASE_URL}/change-history?${params}`, { headers: getAuthHeaders() });
       const data = await response.json();
 
       setHistories(data.histories || []);
@@ -66,7 +67,7 @@ const ChangeHistoryViewer: React.FC = () => {
 
   const fetchStats = async () => {
     try {
-      const response = await fetch(`${API_BASE_URL}/change-history/stats`);
+      const response = await fetch(`${API_BASE_URL}/change-history/stats`, { headers: getAuthHeaders() });
       const data = await response.json();
       setStats(data);
     } catch (error) {
@@ -101,7 +102,7 @@ const ChangeHistoryViewer: React.FC = () => {
     if (filters.tableName) params.append("table_name", filters.tableName);
     if (filters.operation) params.append("operation", filters.operation);
     if (filters.userId) params.append("user_id", filters.userId);
-    window.open(`${API_BASE_URL}/change-history/export?${params}`, "_blank");
+    downloadWithAuth(`${API_BASE_URL}/change-history/export?${params}`, "change_history.csv").catch((err) => alert(err.message));
   };
 
   const totalPages = Math.ceil(total / limit);
@@ -1,5 +1,6 @@
 import React, { useState, useEffect, Fragment } from 'react';
 import { getApiUrl } from './config';
+import { getAuthHeaders } from './api';
 
 interface Node {
   id: number;
@@ -109,7 +110,7 @@ const DijkstraTestPage: React.FC = () => {
   // デバッグ情報を取得
   const fetchDebugInfo = async () => {
     try {
-      const response = await fetch(getApiUrl('/api/debug/graph'));
+      const response = await fetch(getApiUrl('/api/debug/graph'), { headers: getAuthHeaders() });
       if (response.ok) {
         const data = await response.json();
         setDebugInfo(data);
@@ -1,6 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { getApiUrl, STATIC_BASE_URL } from './config';
-import { getAuthHeaders } from './api';
+import { getAuthHeaders, getAuthHeadersForFormData } from './api';
 
 interface Image {
   id: number;
@@ -104,6 +104,7 @@ const ImageManager: React.FC = () => {
 
       const response = await fetch(getApiUrl('/upload'), {
         method: 'POST',
+        headers: getAuthHeadersForFormData(),
         body: formData,
       });
 
@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from "react";
 import { API_BASE_URL } from "./config";
+import { getAuthHeaders, downloadWithAuth } from "./api";
 
 interface UserLog {
   id: number;
@@ -61,7 +62,7 @@ const LogViewer: React.FC = () => {
       if (filters.userId) params.append("user_id", filters.userId);
       if (filters.action) params.append("action", filters.action);
 
-      const response = await fetch(`${API_BASE_URL}/logs?${params}`);
+      const response = await fetch(`${API_BASE_URL}/logs?${params}`, { headers: getAuthHeaders() });
       const data = await response.json();
 
       setLogs(data.logs || []);
@@ -75,7 +76,7 @@ const LogViewer: React.FC = () => {
 
   const fetchStats = async () => {
     try {
-      const response = await fetch(`${API_BASE_URL}/logs/stats`);
+      const response = await fetch(`${API_BASE_URL}/logs/stats`, { headers: getAuthHeaders() });
       const data = await response.json();
       setStats(data);
     } catch (error) {
@@ -99,7 +100,7 @@ const LogViewer: React.FC = () => {
     if (filters.category) params.append("category", filters.category);
     if (filters.userId) params.append("user_id", filters.userId);
     if (filters.action) params.append("action", filters.action);
-    window.open(`${API_BASE_URL}/logs/export?${params}`, "_blank");
+    downloadWithAuth(`${API_BASE_URL}/logs/export?${params}`, "logs.csv").catch((err) => alert(err.message));
   };
 
   return (
@@ -1,15 +1,29 @@
 import React, { useState } from 'react';
 import { getApiUrl } from './config';
 
+// サーバー側の MinPasswordLength と合わせる
+const MIN_PASSWORD_LENGTH = 8;
+
 interface LoginProps {
   onLogin: (token: string, userId: number, isNewUser?: boolean) => void;
 }
 
 const Login: React.FC<LoginProps> = ({ onLogin }) => {
   const [name, setName] = useState('');
+  const [password, setPassword] = useState('');
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [isSignupMode, setIsSignupMode] = useState(false);
+  // 管理者がパスワードをリセットした場合、変更するまでログインを完了しない
+  const [pendingLogin, setPendingLogin] = useState<{ token: string; userId: number; isAdmin: boolean } | null>(null);
+  const [newPassword, setNewPassword] = useState('');
+
+  const completeLogin = (token: string, userId: number, isAdmin: boolean, isNewUser: boolean) => {
+    localStorage.setItem('authToken', token);
+    localStorage.setItem('userId', String(userId));
+    localStorage.setItem('isAdmin', isAdmin ? 'true' : 'false');
+    onLogin(token, userId, isNewUser);
+  };
 
   const handleLogin = async (e: React.FormEvent) => {
     e.preventDefault();
@@ -17,6 +31,10 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
       setError('ユーザー名を入力してください');
       return;
     }
+    if (!password) {
+      setError('パスワードを入力してください');
+      return;
+    }
 
     setLoading(true);
     setError(null);
@@ -27,7 +45,7 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify({ name: name.trim() }),
+        body: JSON.stringify({ name: name.trim(), password }),
       });
 
       if (!response.ok) {
@@ -36,10 +54,47 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
       }
 
       const data = await response.json();
-      localStorage.setItem('authToken', data.token);
-      localStorage.setItem('userId', data.user_id);
-      localStorage.setItem('isAdmin', data.is_admin ? 'true' : 'false');
-      onLogin(data.token, data.user_id, false);
+      if (data.must_change_password) {
+        setPendingLogin({ token: data.token, userId: data.user_id, isAdmin: data.is_admin });
+        return;
+      }
+      completeLogin(data.token, data.user_id, data.is_admin, false);
+    } catch (err: any) {
+      setError(err.message);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleChangePassword = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!pendingLogin) {
+      return;
+    }
+    if (newPassword.length < MIN_PASSWORD_LENGTH) {
+      setError(`パスワードは${MIN_PASSWORD_LENGTH}文字以上で入力してください`);
+      return;
+    }
+
+    setLoading(true);
+    setError(null);
+
+    try {
+      const response = await fetch(getApiUrl('/users/me/password'), {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'application/json',
+          Authorization: pendingLogin.token,
+        },
+        body: JSON.stringify({ current_password: password, new_password: newPassword }),
+      });
+
+      if (!response.ok) {
+        const errorData = await response.json();
+        throw new Error(errorData.error || 'パスワードの変更に失敗しました');
+      }
+
+      completeLogin(pendingLogin.token, pendingLogin.userId, pendingLogin.isAdmin, false);
     } catch (err: any) {
       setError(err.message);
     } finally {
@@ -53,6 +108,10 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
       setError('ユーザー名を入力してください');
       return;
     }
+    if (password.length < MIN_PASSWORD_LENGTH) {
+      setError(`パスワードは${MIN_PASSWORD_LENGTH}文字以上で入力してください`);
+      return;
+    }
 
     setLoading(true);
     setError(null);
@@ -64,7 +123,7 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify({ name: name.trim() }),
+        body: JSON.stringify({ name: name.trim(), password }),
       });
 
       if (signupRes.status === 409) {
@@ -81,7 +140,7 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify({ name: name.trim() }),
+        body: JSON.stringify({ name: name.trim(), password }),
       });
 
       if (!loginRes.ok) {
@@ -89,11 +148,8 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
       }
 
       const data = await loginRes.json();
-      localStorage.setItem('authToken', data.token);
-      localStorage.setItem('userId', data.user_id);
-      localStorage.setItem('isAdmin', data.is_admin ? 'true' : 'false');
       // サインアップ直後は新規ユーザーなのでフラグを渡す
-      onLogin(data.token, data.user_id, true);
+      completeLogin(data.token, data.user_id, data.is_admin, true);
     } catch (err: any) {
       setError(err.message);
     } finally {
@@ -101,7 +157,7 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
     }
   };
 
-  const handleSubmit = isSignupMode ? handleSignup : handleLogin;
+  const handleSubmit = pendingLogin ? handleChangePassword : isSignupMode ? handleSignup : handleLogin;
 
   return (
     <div style={{
@@ -120,10 +176,42 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
         maxWidth: '400px'
       }}>
         <h2 style={{ textAlign: 'center', marginBottom: '30px', color: '#333' }}>
-          OC道案内アプリ {isSignupMode ? 'ニックネーム登録' : 'ログイン'}
+          OC道案内アプリ {pendingLogin ? 'パスワード変更' : isSignupMode ? 'ニックネーム登録' : 'ログイン'}
         </h2>
         
         <form onSubmit={handleSubmit}>
+          {pendingLogin ? (
+          <div style={{ marginBottom: '20px' }}>
+            <p style={{ color: '#555', fontSize: '14px', marginBottom: '12px' }}>
+              管理者によってパスワードがリセットされました。新しいパスワードを設定してください。
+            </p>
+            <label style={{
+              display: 'block',
+              marginBottom: '8px',
+              fontWeight: '500',
+              color: '#555'
+            }}>
+              新しいパスワード
+            </label>
+            <input
+              type="password"
+              value={newPassword}
+              onChange={(e) => setNewPassword(e.target.value)}
+              autoComplete="new-password"
+              style={{
+                width: '100%',
+                padding: '12px',
+                border: '1px solid #ddd',
+                borderRadius: '4px',
+                fontSize: '16px',
+                boxSizing: 'border-box'
+              }}
+              placeholder={`${MIN_PASSWORD_LENGTH}文字以上`}
+              disabled={loading}
+            />
+          </div>
+          ) : (
+          <>
           <div style={{ marginBottom: '20px' }}>
             <label style={{
               display: 'block',
@@ -150,6 +238,35 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
             />
           </div>
 
+          <div style={{ marginBottom: '20px' }}>
+            <label style={{
+              display: 'block',
+              marginBottom: '8px',
+              fontWeight: '500',
+              color: '#555'
+            }}>
+              パスワード
+            </label>
+            <input
+              type="password"
+              value={password}
+              onChange={(e) => setPassword(e.target.value)}
+              autoComplete={isSignupMode ? 'new-password' : 'current-password'}
+              style={{
+                width: '100%',
+                padding: '12px',
+                border: '1px solid #ddd',
+                borderRadius: '4px',
+                fontSize: '16px',
+                boxSizing: 'border-box'
+              }}
+              placeholder={isSignupMode ? `${MIN_PASSWORD_LENGTH}文字以上` : 'パスワードを入力'}
+              disabled={loading}
+            />
+          </div>
+          </>
+          )}
+
           {error && (
             <div style={{
               color: '#dc2626',
@@ -180,11 +297,14 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
               transition: 'background-color 0.2s'
             }}
           >
-            {loading ? (isSignupMode ? 'ユーザー登録中...' : 'ログイン中...') : (isSignupMode ? 'ユーザー登録' : 'ログイン')}
+            {pendingLogin
+              ? (loading ? '変更中...' : 'パスワードを変更してログイン')
+              : loading ? (isSignupMode ? 'ユーザー登録中...' : 'ログイン中...') : (isSignupMode ? 'ユーザー登録' : 'ログイン')}
           </button>
         </form>
 
         {/* モード切替ボタン */}
+        {!pendingLogin && (
         <div style={{ marginTop: '20px', textAlign: 'center' }}>
           <button
             type="button"
@@ -192,6 +312,7 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
               setIsSignupMode(!isSignupMode);
               setError(null);
               setName('');
+              setPassword('');
             }}
             disabled={loading}
             style={{
@@ -206,6 +327,7 @@ const Login: React.FC<LoginProps> = ({ onLogin }) => {
             {isSignupMode ? 'すでにアカウントをお持ちの方はこちら' : '新規ニックネーム登録はこちら'}
           </button>
         </div>
+        )}
       </div>
     </div>
   );
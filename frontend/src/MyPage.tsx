@@ -28,10 +28,9 @@ const MyPage: React.FC = () => {
     }
 
     try {
-      const response = await apiRequest(getApiUrl('/users'));
+      const response = await apiRequest(getApiUrl('/users/me'));
       if (response.ok) {
-        const users = await response.json();
-        const user = users.find((u: any) => u.ID === parseInt(userId));
+        const user = await response.json();
         if (user) {
           setUserName(user.name || 'ユーザー');
         } else {
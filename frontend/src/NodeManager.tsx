@@ -25,7 +25,6 @@ const NodeManager: React.FC = () => {
     name: '',
     x: 0,
     y: 0,
-    congestion: 0,
     tourist: false,
     field_id: ''
   });
@@ -84,7 +83,6 @@ const NodeManager: React.FC = () => {
       name: formData.name,
       x: Number(formData.x),
       y: Number(formData.y),
-      congestion: Number(formData.congestion),
       tourist: formData.tourist,
       field_id: formData.field_id ? Number(formData.field_id) : null
     };
@@ -112,7 +110,6 @@ const NodeManager: React.FC = () => {
         name: '',
         x: 0,
         y: 0,
-        congestion: 0,
         tourist: false,
         field_id: ''
       });
@@ -133,7 +130,6 @@ const NodeManager: React.FC = () => {
       name: node.name,
       x: node.x,
       y: node.y,
-      congestion: node.congestion,
       tourist: node.tourist,
       field_id: node.field_id?.toString() || ''
     });
@@ -167,7 +163,6 @@ const NodeManager: React.FC = () => {
       name: '',
       x: 0,
       y: 0,
-      congestion: 0,
       tourist: false,
       field_id: ''
     });
@@ -336,25 +331,6 @@ const NodeManager: React.FC = () => {
                 />
               </div>
 
-              <div>
-                <label style={{ display: 'block', marginBottom: '5px', fontWeight: 'bold' }}>
-                  混雑度 (0-100)
-                </label>
-                <input
-                  type="number"
-                  min="0"
-                  max="100"
-                  value={formData.congestion}
-                  onChange={(e) => setFormData({ ...formData, congestion: Number(e.target.value) })}
-                  style={{
-                    width: '100%',
-                    padding: '8px',
-                    border: '1px solid #d1d5db',
-                    borderRadius: '4px'
-                  }}
-                />
-              </div>
-
               <div>
                 <label style={{ display: 'flex', alignItems: 'center', marginTop: '28px' }}>
                   <input
@@ -433,7 +409,7 @@ const NodeManager: React.FC = () => {
                   <td style={{ padding: '12px' }}>{node.id}</td>
                   <td style={{ padding: '12px' }}>{node.name}</td>
                   <td style={{ padding: '12px' }}>({node.x.toFixed(1)}, {node.y.toFixed(1)})</td>
-                  <td style={{ padding: '12px' }}>{node.congestion}</td>
+                  <td style={{ padding: '12px' }}>{node.congestion_status?.label ?? node.congestion}</td>
                   <td style={{ padding: '12px' }}>
                     {node.tourist ? (
                       <span style={{ color: '#10b981' }}>✓ はい</span>
@@ -38,6 +38,8 @@ interface CongestionRecord {
   id: number;
   tourist_spot_id: number;
   level: number;
+  label: string;
+  ratio: number;
   recorded_at: string;
   note: string;
   created_at: string;
@@ -282,10 +284,12 @@ const TouristSpotDetail: React.FC<TouristSpotDetailProps> = ({ spotId, onBack })
                   fontSize: '16px'
                 }}
               >
-                <option value={0}>0 - 混雑なし</option>
-                <option value={1}>1 - やや混雑</option>
-                <option value={2}>2 - 混雑</option>
-                <option value={3}>3 - 非常に混雑</option>
+                <option value={0}>0 - 空いている</option>
+                <option value={1}>1 - 少し空いている</option>
+                <option value={2}>2 - 普通</option>
+                <option value={3}>3 - 混雑</option>
+                <option value={4}>4 - 非常に混雑</option>
+                <option value={5}>5 - 満員</option>
               </select>
             </div>
 
@@ -366,7 +370,7 @@ const TouristSpotDetail: React.FC<TouristSpotDetailProps> = ({ spotId, onBack })
                     marginBottom: '10px'
                   }}>
                     <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: '5px' }}>
-                      <strong>レベル: {record.level}</strong>
+                      <strong>レベル: {record.level} ({record.label})</strong>
                       <span style={{ fontSize: '12px', color: '#6b7280' }}>
                         {new Date(record.recorded_at).toLocaleString('ja-JP')}
                       </span>
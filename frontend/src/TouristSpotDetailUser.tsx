@@ -23,6 +23,11 @@ interface TouristSpot {
   is_open: boolean;
   opening_time: string;
   closing_time: string;
+  open_status?: {
+    is_open: boolean;
+    opens_at?: string;
+    closes_at?: string;
+  };
   entry_fee: number;
   website: string;
   phone_number: string;
@@ -128,6 +133,8 @@ const TouristSpotDetailUser: React.FC<TouristSpotDetailUserProps> = ({ spotId, o
   }
 
   const congestion = getCongestionLevel(spot.current_count, spot.max_capacity);
+  // 営業時間スケジュールを反映した営業状況（古いAPIでは is_open を使う）
+  const isOpenNow = spot.open_status ? spot.open_status.is_open : spot.is_open;
 
   return (
     <div style={{ minHeight: '100vh', background: '#f8fafc' }}>
@@ -239,14 +246,14 @@ const TouristSpotDetailUser: React.FC<TouristSpotDetailUserProps> = ({ spotId, o
             display: 'flex',
             alignItems: 'center',
             gap: '6px',
-            background: spot.is_open ? '#dcfce7' : '#fee2e2',
-            color: spot.is_open ? '#166534' : '#dc2626',
+            background: isOpenNow ? '#dcfce7' : '#fee2e2',
+            color: isOpenNow ? '#166534' : '#dc2626',
             padding: '8px 16px',
             borderRadius: '20px',
             fontSize: '14px',
             fontWeight: 'bold'
           }}>
-            {spot.is_open ? '🟢 営業中' : '🔴 営業時間外'}
+            {isOpenNow ? '🟢 営業中' : '🔴 営業時間外'}
           </div>
         </div>
 
@@ -63,4 +63,18 @@ export const apiRequest = async (url: string, options: RequestInit = {}): Promis
   }
   
   return response;
-};
\ No newline at end of file
+};
+
+// 認証付きでファイルをダウンロード（window.openでは認証ヘッダーを送れないため）
+export const downloadWithAuth = async (url: string, filename: string): Promise<void> => {
+  const response = await fetch(url, { headers: getAuthHeaders() });
+  if (!response.ok) {
+    throw new Error(`ダウンロードに失敗しました (${response.status})`);
+  }
+  const blob = await response.blob();
+  const link = document.createElement('a');
+  link.href = URL.createObjectURL(blob);
+  link.download = filename;
+  link.click();
+  URL.revokeObjectURL(link.href);
+};
@@ -4,11 +4,16 @@ export const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localho
 // 静的ファイル（画像など）のベースURL（/apiなし）
 export const STATIC_BASE_URL = API_BASE_URL.replace(/\/api$/, '');
 
+// APIのロケール（編集画面で翻訳を日本語の元データとして保存しないよう、既定は日本語に固定）
+export const API_LANG = import.meta.env.VITE_API_LANG || 'ja';
+
 // APIエンドポイントのヘルパー関数
 export const getApiUrl = (endpoint: string): string => {
   // endpointが'/'で始まらない場合は追加
   const cleanEndpoint = endpoint.startsWith('/') ? endpoint : `/${endpoint}`;
-  return `${API_BASE_URL}${cleanEndpoint}`;
+  // ロケールを ?lang= で指定（Accept-Language より優先される）
+  const separator = cleanEndpoint.includes('?') ? '&' : '?';
+  return `${API_BASE_URL}${cleanEndpoint}${separator}lang=${API_LANG}`;
 };
 
 // よく使用するAPIエンドポイント
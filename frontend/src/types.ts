@@ -1,11 +1,23 @@
 // 共通の型定義
 
+// 混雑状況（サーバーで判定。level は -1=不明, 0=空いている 〜 5=満員）
+export interface CongestionStatus {
+  level: number;
+  label: string;
+  ratio: number | null;
+  current_count?: number;
+  max_capacity?: number;
+  basis: 'count' | 'record' | 'aggregate' | 'none';
+  updated_at?: string;
+}
+
 export interface Node {
   id: number;
   name: string;
   x: number;
   y: number;
-  congestion: number;
+  congestion: number; // 最寄りの観光地から集計した混雑度（読み取り専用）
+  congestion_status?: CongestionStatus;
   tourist: boolean;
   field_id?: number;
   created_at?: string;